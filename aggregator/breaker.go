@@ -0,0 +1,150 @@
+package aggregator
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态，任务被跳过
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // 关闭：正常放行
+	BreakerOpen                         // 打开：直接跳过
+	BreakerHalfOpen                     // 半开：冷却结束，放行一次探测
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker 按任务名称维护的熔断器：
+// 连续失败达到阈值后打开，冷却时间过后进入半开状态放行一次探测，探测成功则关闭，失败则重新打开。
+// 熔断器应跨请求复用（例如作为 Service 的字段）。
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu     sync.Mutex
+	states map[string]*breakerState
+}
+
+// breakerState 单个名称的熔断状态
+type breakerState struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool // 半开状态下是否已有探测在进行
+}
+
+// NewCircuitBreaker 创建熔断器；failureThreshold 为连续失败阈值，cooldown 为打开后的冷却时间
+func NewCircuitBreaker(failureThreshold int, cooldown time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 1
+	}
+	return &CircuitBreaker{
+		threshold: failureThreshold,
+		cooldown:  cooldown,
+		states:    make(map[string]*breakerState),
+	}
+}
+
+// Allow 判断名称对应的依赖当前是否放行
+func (cb *CircuitBreaker) Allow(name string) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	st := cb.get(name)
+	switch st.state {
+	case BreakerOpen:
+		if time.Since(st.openedAt) < cb.cooldown {
+			return false
+		}
+		st.state = BreakerHalfOpen
+		st.probing = true
+		return true
+	case BreakerHalfOpen:
+		if st.probing {
+			return false
+		}
+		st.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record 记录一次调用结果
+func (cb *CircuitBreaker) Record(name string, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	st := cb.get(name)
+	if err == nil {
+		st.state = BreakerClosed
+		st.failures = 0
+		st.probing = false
+		return
+	}
+
+	st.failures++
+	if st.state == BreakerHalfOpen || st.failures >= cb.threshold {
+		st.state = BreakerOpen
+		st.openedAt = time.Now()
+		st.probing = false
+	}
+}
+
+// abandon 调用被上游取消、结果无法说明依赖健康状况时，释放半开探测名额
+func (cb *CircuitBreaker) abandon(name string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if st, ok := cb.states[name]; ok && st.state == BreakerHalfOpen {
+		st.probing = false
+	}
+}
+
+// State 返回名称对应的熔断状态
+func (cb *CircuitBreaker) State(name string) BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if st, ok := cb.states[name]; ok {
+		if st.state == BreakerOpen && time.Since(st.openedAt) >= cb.cooldown {
+			return BreakerHalfOpen
+		}
+		return st.state
+	}
+	return BreakerClosed
+}
+
+// Reset 重置名称对应的熔断状态
+func (cb *CircuitBreaker) Reset(name string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	delete(cb.states, name)
+}
+
+func (cb *CircuitBreaker) get(name string) *breakerState {
+	st, ok := cb.states[name]
+	if !ok {
+		st = &breakerState{}
+		cb.states[name] = st
+	}
+	return st
+}
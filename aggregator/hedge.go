@@ -0,0 +1,249 @@
+package aggregator
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxHedges        = 1   // 每次尝试默认最多额外发起 1 个副本
+	defaultHedgeWindow      = 128 // 每个任务名称保留的延迟样本数
+	defaultHedgeMinSamples  = 10  // 按分位数计算延迟所需的最少样本数
+	defaultHedgeBudgetBurst = 1   // 预算允许的突发副本数
+)
+
+type HedgeOption func(*Hedger)
+
+// WithHedgeDelay 设置固定的对冲延迟：首个尝试超过该时长仍未完成时发起副本
+func WithHedgeDelay(delay time.Duration) HedgeOption {
+	return func(h *Hedger) { h.delay = delay }
+}
+
+// WithHedgePercentile 按任务名称的历史成功延迟分位数（0~1，如 0.95）作为对冲延迟；
+// 样本数不足 minSamples 时回退到 WithHedgeDelay
+func WithHedgePercentile(percentile float64, minSamples int) HedgeOption {
+	return func(h *Hedger) {
+		h.percentile = percentile
+		if minSamples > 0 {
+			h.minSamples = minSamples
+		}
+	}
+}
+
+// WithMaxHedges 设置每次尝试最多额外发起的副本数
+func WithMaxHedges(n int) HedgeOption {
+	return func(h *Hedger) {
+		if n > 0 {
+			h.maxHedges = n
+		}
+	}
+}
+
+// WithHedgeBudget 限制额外负载：累计副本数不超过 ratio*累计请求数 + burst，ratio<=0 表示不限制
+func WithHedgeBudget(ratio float64, burst int) HedgeOption {
+	return func(h *Hedger) {
+		h.budgetRatio = ratio
+		h.budgetBurst = burst
+	}
+}
+
+// HedgeStats 对冲统计
+type HedgeStats struct {
+	Requests  uint64 // 主请求数
+	Hedges    uint64 // 发起的副本数
+	HedgeWins uint64 // 副本先于主请求成功的次数
+}
+
+// Hedger 对冲请求策略：主请求在延迟后仍未完成时发起副本，先成功者胜出，其余尝试被取消。
+// Hedger 记录各任务名称的历史延迟与预算，应跨请求复用。
+//
+// 注意：启用对冲后同一个 ParallelFetcher 可能被并发执行多次，任务必须是幂等且并发安全的。
+type Hedger struct {
+	delay       time.Duration
+	percentile  float64
+	minSamples  int
+	maxHedges   int
+	budgetRatio float64
+	budgetBurst int
+
+	mu        sync.Mutex
+	latencies map[string]*latencyWindow
+
+	requests  atomic.Uint64
+	hedges    atomic.Uint64
+	hedgeWins atomic.Uint64
+}
+
+// NewHedger 创建对冲策略
+func NewHedger(opts ...HedgeOption) *Hedger {
+	h := &Hedger{
+		minSamples:  defaultHedgeMinSamples,
+		maxHedges:   defaultMaxHedges,
+		budgetBurst: defaultHedgeBudgetBurst,
+		latencies:   make(map[string]*latencyWindow),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Stats 返回对冲统计
+func (h *Hedger) Stats() HedgeStats {
+	return HedgeStats{
+		Requests:  h.requests.Load(),
+		Hedges:    h.hedges.Load(),
+		HedgeWins: h.hedgeWins.Load(),
+	}
+}
+
+// HedgeDelay 返回任务名称当前使用的对冲延迟，0 表示不对冲
+func (h *Hedger) HedgeDelay(name string) time.Duration {
+	if h.percentile > 0 {
+		h.mu.Lock()
+		w, ok := h.latencies[name]
+		var d time.Duration
+		if ok && w.len() >= h.minSamples {
+			d = w.quantile(h.percentile)
+		}
+		h.mu.Unlock()
+		if d > 0 {
+			return d
+		}
+	}
+	return h.delay
+}
+
+// run 以对冲方式执行一次尝试
+func (h *Hedger) run(ctx context.Context, name string, f ParallelFetcher, timeout time.Duration) error {
+	h.requests.Add(1)
+
+	delay := h.HedgeDelay(name)
+	if delay <= 0 {
+		return h.observe(name, time.Now(), runAttempt(ctx, f, timeout))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // 胜出后取消其余尝试
+
+	type outcome struct {
+		hedge bool
+		err   error
+	}
+	results := make(chan outcome, 1+h.maxHedges)
+	launch := func(hedge bool) {
+		go func() {
+			start := time.Now()
+			err := runAttempt(ctx, f, timeout)
+			results <- outcome{hedge: hedge, err: h.observe(name, start, err)}
+		}()
+	}
+
+	launch(false)
+	inflight, hedges := 1, 0
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var firstErr error
+	for {
+		select {
+		case res := <-results:
+			inflight--
+			if res.err == nil {
+				if res.hedge {
+					h.hedgeWins.Add(1)
+				}
+				return nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if inflight == 0 {
+				return firstErr
+			}
+
+		case <-timer.C:
+			if hedges < h.maxHedges && h.allowHedge() {
+				hedges++
+				inflight++
+				launch(true)
+				if hedges < h.maxHedges {
+					timer.Reset(delay)
+				}
+			}
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// allowHedge 检查并占用额外负载预算
+func (h *Hedger) allowHedge() bool {
+	if h.budgetRatio <= 0 {
+		h.hedges.Add(1)
+		return true
+	}
+	for {
+		used := h.hedges.Load()
+		limit := h.budgetRatio*float64(h.requests.Load()) + float64(h.budgetBurst)
+		if float64(used+1) > limit {
+			return false
+		}
+		if h.hedges.CompareAndSwap(used, used+1) {
+			return true
+		}
+	}
+}
+
+// observe 记录成功尝试的延迟，原样返回 err
+func (h *Hedger) observe(name string, start time.Time, err error) error {
+	if err != nil || h.percentile <= 0 {
+		return err
+	}
+
+	d := time.Since(start)
+	h.mu.Lock()
+	w, ok := h.latencies[name]
+	if !ok {
+		w = &latencyWindow{samples: make([]time.Duration, 0, defaultHedgeWindow)}
+		h.latencies[name] = w
+	}
+	w.add(d)
+	h.mu.Unlock()
+	return nil
+}
+
+// latencyWindow 固定容量的延迟样本环形缓冲
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func (w *latencyWindow) len() int {
+	return len(w.samples)
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	if len(w.samples) < cap(w.samples) {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % len(w.samples)
+}
+
+func (w *latencyWindow) quantile(q float64) time.Duration {
+	if len(w.samples) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), w.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(q*float64(len(sorted)-1) + 0.5)
+	return sorted[min(max(idx, 0), len(sorted)-1)]
+}
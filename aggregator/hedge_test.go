@@ -0,0 +1,120 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedging_SlowPrimaryLosesToHedge(t *testing.T) {
+	h := NewHedger(WithHedgeDelay(10 * time.Millisecond))
+
+	var calls, canceled int32
+	slowOnce := func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// 首个副本“卡住”，直到被取消
+			<-ctx.Done()
+			atomic.AddInt32(&canceled, 1)
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	start := time.Now()
+	err := ExecuteTasks(t.Context(), []Task{{Name: "replica", Fetch: slowOnce}}, WithHedging(h))
+	if err != nil {
+		t.Fatalf("expected hedge to succeed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatalf("expected hedged call to finish quickly, took %v", elapsed)
+	}
+
+	stats := h.Stats()
+	if stats.Requests != 1 || stats.Hedges != 1 || stats.HedgeWins != 1 {
+		t.Fatalf("unexpected hedge stats: %+v", stats)
+	}
+
+	// 胜出后失败的尝试被取消
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&canceled) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected losing attempt to be canceled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHedging_BudgetLimitsExtraLoad(t *testing.T) {
+	// 比例极小、突发 1：除第一次外不允许再对冲
+	h := NewHedger(WithHedgeDelay(5*time.Millisecond), WithHedgeBudget(0.0001, 1))
+
+	slow := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(30 * time.Millisecond):
+			return nil
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := ExecuteTasks(t.Context(), []Task{{Name: "slow", Fetch: slow}}, WithHedging(h)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if stats := h.Stats(); stats.Requests != 3 || stats.Hedges != 1 {
+		t.Fatalf("expected a single hedge within budget, got %+v", stats)
+	}
+}
+
+func TestHedging_PercentileDelay(t *testing.T) {
+	h := NewHedger(WithHedgePercentile(0.9, 5))
+	if d := h.HedgeDelay("svc"); d != 0 {
+		t.Fatalf("expected no hedge delay without samples, got %v", d)
+	}
+
+	fast := func(ctx context.Context) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	}
+	for i := 0; i < 5; i++ {
+		if err := ExecuteTasks(t.Context(), []Task{{Name: "svc", Fetch: fast}}, WithHedging(h)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if d := h.HedgeDelay("svc"); d < 2*time.Millisecond || d > 100*time.Millisecond {
+		t.Fatalf("expected percentile hedge delay around 2ms, got %v", d)
+	}
+}
+
+func TestExecuteTasks_SkipsRetryThatCannotMeetDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	errTransient := errors.New("transient")
+	var attempts int32
+	start := time.Now()
+	err := ExecuteTasks(ctx, []Task{{
+		Name: "deadline",
+		Fetch: func(ctx context.Context) error {
+			atomic.AddInt32(&attempts, 1)
+			return errTransient
+		},
+		// 退避远超剩余时间，重试注定无法在截止前完成
+		Retry: &RetryPolicy{MaxRetries: 3, BaseDelay: time.Second},
+	}})
+
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected last fetch error instead of waiting for deadline, got %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("expected 1 attempt, got %d", got)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Fatalf("expected to give up immediately, took %v", elapsed)
+	}
+}
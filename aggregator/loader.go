@@ -0,0 +1,321 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchFunc 批量抓取函数：接受一批唯一键，返回 map[K]V。
+// 返回普通 error 时该批所有键都带上该错误；返回 KeyErrors 时仅对应键失败，其余键正常取值。
+// 结果中缺失的键返回零值。
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// KeyErrors 按键返回的错误集合，用于 BatchFunc 报告部分键失败
+type KeyErrors[K comparable] map[K]error
+
+func (e KeyErrors[K]) Error() string {
+	return fmt.Sprintf("%d keys failed to load", len(e))
+}
+
+// LoaderStats Loader 统计信息，可用于 N+1 检测
+type LoaderStats struct {
+	Loads        uint64 // Load 调用次数
+	CacheHits    uint64 // 命中请求级缓存（或同批次去重）的次数
+	Batches      uint64 // 批量抓取次数
+	BatchedKeys  uint64 // 发往 BatchFunc 的键总数
+	MaxBatchSize uint64 // 单批最大键数
+}
+
+// AvgBatchSize 返回平均批大小，无数据时返回 0
+func (s LoaderStats) AvgBatchSize() float64 {
+	if s.Batches == 0 {
+		return 0
+	}
+	return float64(s.BatchedKeys) / float64(s.Batches)
+}
+
+// SuspectNPlusOne 在批量抓取次数不少于 minBatches 且平均批大小不足 2 时返回 true，
+// 通常意味着调用方在循环中逐个 Load 并立即等待结果，批处理没有生效
+func (s LoaderStats) SuspectNPlusOne(minBatches uint64) bool {
+	return s.Batches >= minBatches && s.AvgBatchSize() < 2
+}
+
+// Loader 原生的批处理 DataLoader：
+// 在批处理窗口内收集 Load 的键，合并为一次 BatchFunc 调用，并在请求级缓存中记忆结果。
+// Loader 应按请求创建，避免缓存跨请求共享。
+type Loader[K comparable, V any] struct {
+	fetch BatchFunc[K, V]
+	opts  loaderOptions
+
+	mu    sync.Mutex
+	cache map[K]*loaderEntry[K, V]
+	batch *loaderBatch[K, V] // 当前正在收集的批次
+
+	loads        atomic.Uint64
+	cacheHits    atomic.Uint64
+	batches      atomic.Uint64
+	batchedKeys  atomic.Uint64
+	maxBatchSize atomic.Uint64
+}
+
+// loaderEntry 单个键的加载结果
+type loaderEntry[K comparable, V any] struct {
+	done  chan struct{}
+	val   V
+	err   error
+	batch *loaderBatch[K, V] // 所属批次，用于等待时立即触发抓取；预置的条目为 nil
+}
+
+// loaderBatch 一个待抓取的批次
+type loaderBatch[K comparable, V any] struct {
+	ctx        context.Context
+	keys       []K
+	entries    map[K]*loaderEntry[K, V]
+	timer      *time.Timer
+	dispatched bool
+}
+
+// NewLoader 创建原生 Loader
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V], opts ...LoaderOption) *Loader[K, V] {
+	o := loaderOptions{
+		wait:     defaultBatchWait,
+		maxBatch: defaultMaxBatchSize,
+		cache:    true,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Loader[K, V]{
+		fetch: fetch,
+		opts:  o,
+		cache: make(map[K]*loaderEntry[K, V]),
+	}
+}
+
+// Load 将键加入当前批次并返回等待结果的 thunk。
+// 批次在窗口到期、达到最大批大小或任一 thunk 被调用时发起抓取；
+// 批量抓取使用首个键的 ctx（保留值但不继承取消），thunk 等待时则响应各自 ctx 的取消。
+func (l *Loader[K, V]) Load(ctx context.Context, key K) TypedThunk[V] {
+	l.loads.Add(1)
+
+	l.mu.Lock()
+	if e, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		l.cacheHits.Add(1)
+		return l.thunk(ctx, e)
+	}
+	if l.batch != nil {
+		if e, ok := l.batch.entries[key]; ok {
+			l.mu.Unlock()
+			l.cacheHits.Add(1)
+			return l.thunk(ctx, e)
+		}
+	}
+
+	b := l.batch
+	if b == nil {
+		b = &loaderBatch[K, V]{
+			ctx:     context.WithoutCancel(ctx),
+			entries: make(map[K]*loaderEntry[K, V]),
+		}
+		l.batch = b
+		if l.opts.wait > 0 {
+			b.timer = time.AfterFunc(l.opts.wait, func() { l.flush(b) })
+		}
+	}
+
+	e := &loaderEntry[K, V]{done: make(chan struct{}), batch: b}
+	b.keys = append(b.keys, key)
+	b.entries[key] = e
+	if l.opts.cache {
+		l.cache[key] = e
+	}
+
+	full := l.opts.maxBatch > 0 && len(b.keys) >= l.opts.maxBatch
+	if full {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	if full || l.opts.wait <= 0 {
+		go l.flush(b)
+	}
+	return l.thunk(ctx, e)
+}
+
+// LoadMany 批量加载多个键，返回的 thunk 按输入顺序给出结果与逐键错误
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) func() ([]V, []error) {
+	thunks := make([]TypedThunk[V], len(keys))
+	for i, k := range keys {
+		thunks[i] = l.Load(ctx, k)
+	}
+
+	return func() ([]V, []error) {
+		vals := make([]V, len(keys))
+		var errs []error
+		for i, th := range thunks {
+			v, err := th()
+			if err != nil {
+				if errs == nil {
+					errs = make([]error, len(keys))
+				}
+				errs[i] = err
+				continue
+			}
+			vals[i] = v
+		}
+		return vals, errs
+	}
+}
+
+// Prime 向缓存预置键值；键已存在时不覆盖
+func (l *Loader[K, V]) Prime(key K, val V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.cache[key]; ok {
+		return
+	}
+	e := &loaderEntry[K, V]{done: make(chan struct{}), val: val}
+	close(e.done)
+	l.cache[key] = e
+}
+
+// Clear 清除指定键的缓存
+func (l *Loader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.cache, key)
+}
+
+// ClearAll 清除全部缓存
+func (l *Loader[K, V]) ClearAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cache = make(map[K]*loaderEntry[K, V])
+}
+
+// Stats 返回统计信息快照
+func (l *Loader[K, V]) Stats() LoaderStats {
+	return LoaderStats{
+		Loads:        l.loads.Load(),
+		CacheHits:    l.cacheHits.Load(),
+		Batches:      l.batches.Load(),
+		BatchedKeys:  l.batchedKeys.Load(),
+		MaxBatchSize: l.maxBatchSize.Load(),
+	}
+}
+
+// thunk 返回等待 e 完成的 thunk；调用时若所属批次仍在收集，则立即发起抓取
+func (l *Loader[K, V]) thunk(ctx context.Context, e *loaderEntry[K, V]) TypedThunk[V] {
+	return func() (V, error) {
+		if e.batch != nil {
+			l.flush(e.batch)
+		}
+
+		select {
+		case <-e.done:
+			return e.val, e.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// flush 发起批次抓取，同一批次只执行一次
+func (l *Loader[K, V]) flush(b *loaderBatch[K, V]) {
+	l.mu.Lock()
+	if b.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	b.dispatched = true
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+
+	l.batches.Add(1)
+	size := uint64(len(b.keys))
+	l.batchedKeys.Add(size)
+	for {
+		cur := l.maxBatchSize.Load()
+		if size <= cur || l.maxBatchSize.CompareAndSwap(cur, size) {
+			break
+		}
+	}
+
+	resMap, err := l.safeFetch(b.ctx, b.keys)
+
+	var keyErrs KeyErrors[K]
+	if errors.As(err, &keyErrs) {
+		err = nil
+	}
+
+	var failed []K
+	for _, k := range b.keys {
+		e := b.entries[k]
+		switch {
+		case err != nil:
+			e.err = err
+		case keyErrs[k] != nil:
+			e.err = keyErrs[k]
+		default:
+			e.val = resMap[k]
+		}
+		if e.err != nil {
+			failed = append(failed, k)
+		}
+		close(e.done)
+	}
+
+	// 失败的键不保留在缓存中，以便后续重新加载
+	if len(failed) > 0 {
+		l.mu.Lock()
+		for _, k := range failed {
+			if l.cache[k] == b.entries[k] {
+				delete(l.cache, k)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// safeFetch 调用 BatchFunc 并恢复 panic
+func (l *Loader[K, V]) safeFetch(ctx context.Context, keys []K) (res map[K]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("loader batch func panic: %v", r)
+		}
+	}()
+	return l.fetch(ctx, keys)
+}
+
+// LoaderThunkGetter 返回一个 ThunkGetter，可直接传入 PopulateWithLoader / PopulateTreeWithLoader。
+// keyOf 用于从项 R 提取键 K，零值键不会触发加载。
+func LoaderThunkGetter[R any, K comparable, V any](
+	ctx context.Context,
+	loader *Loader[K, V],
+	keyOf func(R) K,
+) ThunkGetter[R, V] {
+	return func(r R) TypedThunk[V] {
+		k := keyOf(r)
+		var zeroK K
+		if k == zeroK {
+			return nil
+		}
+		return loader.Load(ctx, k)
+	}
+}
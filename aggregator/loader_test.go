@@ -0,0 +1,182 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]uint32
+}
+
+func (r *batchRecorder) fetch(ctx context.Context, keys []uint32) (map[uint32]string, error) {
+	r.mu.Lock()
+	kcopy := append([]uint32(nil), keys...)
+	sort.Slice(kcopy, func(i, j int) bool { return kcopy[i] < kcopy[j] })
+	r.batches = append(r.batches, kcopy)
+	r.mu.Unlock()
+
+	res := make(map[uint32]string, len(keys))
+	for _, k := range keys {
+		if k%2 == 1 {
+			res[k] = string(rune('a' + k))
+		}
+	}
+	return res, nil
+}
+
+func (r *batchRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.batches)
+}
+
+func TestLoader_BatchesAndCaches(t *testing.T) {
+	ctx := context.Background()
+	rec := &batchRecorder{}
+	loader := NewLoader[uint32, string](rec.fetch, WithBatchWait(time.Second))
+
+	th1 := loader.Load(ctx, 1)
+	th2 := loader.Load(ctx, 2)
+	th3 := loader.Load(ctx, 3)
+	thDup := loader.Load(ctx, 1)
+
+	// 调用任一 thunk 立即触发批次抓取，无需等待窗口到期
+	if v, err := th1(); err != nil || v != "b" {
+		t.Fatalf("key 1: expected \"b\", got %q (%v)", v, err)
+	}
+	if v, err := th2(); err != nil || v != "" {
+		t.Fatalf("key 2: expected zero value, got %q (%v)", v, err)
+	}
+	if v, _ := th3(); v != "d" {
+		t.Fatalf("key 3: expected \"d\", got %q", v)
+	}
+	if v, _ := thDup(); v != "b" {
+		t.Fatalf("duplicate key 1: expected \"b\", got %q", v)
+	}
+
+	// 再次加载命中缓存，不发起新批次
+	if v, _ := loader.Load(ctx, 3)(); v != "d" {
+		t.Fatalf("cached key 3: expected \"d\", got %q", v)
+	}
+
+	if rec.count() != 1 || len(rec.batches[0]) != 3 {
+		t.Fatalf("expected a single batch of 3 keys, got %v", rec.batches)
+	}
+
+	stats := loader.Stats()
+	if stats.Loads != 5 || stats.CacheHits != 2 || stats.Batches != 1 || stats.MaxBatchSize != 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.SuspectNPlusOne(1) {
+		t.Fatalf("did not expect N+1 suspicion: %+v", stats)
+	}
+}
+
+func TestLoader_MaxBatchSizeAndWindow(t *testing.T) {
+	ctx := context.Background()
+	rec := &batchRecorder{}
+	loader := NewLoader[uint32, string](rec.fetch, WithBatchWait(5*time.Millisecond), WithMaxBatchSize(2))
+
+	vals, errs := loader.LoadMany(ctx, []uint32{1, 3, 5})()
+	if errs != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if vals[0] != "b" || vals[1] != "d" || vals[2] != "f" {
+		t.Fatalf("unexpected values: %v", vals)
+	}
+	if rec.count() != 2 {
+		t.Fatalf("expected 2 batches with max batch size 2, got %v", rec.batches)
+	}
+}
+
+func TestLoader_KeyErrorsAndRetry(t *testing.T) {
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+	calls := 0
+
+	loader := NewLoader[uint32, string](func(ctx context.Context, keys []uint32) (map[uint32]string, error) {
+		calls++
+		res := map[uint32]string{}
+		errs := KeyErrors[uint32]{}
+		for _, k := range keys {
+			if k == 2 && calls == 1 {
+				errs[k] = errBoom
+				continue
+			}
+			res[k] = "ok"
+		}
+		if len(errs) > 0 {
+			return res, errs
+		}
+		return res, nil
+	})
+
+	vals, errs := loader.LoadMany(ctx, []uint32{1, 2})()
+	if vals[0] != "ok" || errs == nil || errs[0] != nil || !errors.Is(errs[1], errBoom) {
+		t.Fatalf("unexpected results: %v %v", vals, errs)
+	}
+
+	// 失败的键不缓存，再次加载会重新抓取
+	if v, err := loader.Load(ctx, 2)(); err != nil || v != "ok" {
+		t.Fatalf("expected retry to succeed, got %q (%v)", v, err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 fetch calls, got %d", calls)
+	}
+}
+
+func TestLoader_PrimeAndClear(t *testing.T) {
+	ctx := context.Background()
+	rec := &batchRecorder{}
+	loader := NewLoader[uint32, string](rec.fetch)
+
+	loader.Prime(1, "primed")
+	if v, _ := loader.Load(ctx, 1)(); v != "primed" {
+		t.Fatalf("expected primed value, got %q", v)
+	}
+	if rec.count() != 0 {
+		t.Fatalf("expected no fetch for primed key, got %v", rec.batches)
+	}
+
+	loader.Clear(1)
+	if v, _ := loader.Load(ctx, 1)(); v != "b" {
+		t.Fatalf("expected fetched value after Clear, got %q", v)
+	}
+
+	loader.ClearAll()
+	_, _ = loader.Load(ctx, 1)()
+	if rec.count() != 2 {
+		t.Fatalf("expected 2 fetches after ClearAll, got %v", rec.batches)
+	}
+}
+
+func TestLoader_WithPopulateWithLoader(t *testing.T) {
+	ctx := context.Background()
+	rec := &batchRecorder{}
+	loader := NewLoader[uint32, string](rec.fetch, WithBatchWait(time.Second))
+
+	items := []*testItem{{ID: 1}, {ID: 3}, {ID: 0}, {ID: 1}}
+	getter := LoaderThunkGetter[*testItem, uint32, string](ctx, loader, func(it *testItem) uint32 { return it.ID })
+
+	if err := PopulateWithLoader(items, getter, func(it *testItem, v string) { it.Name = v }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if items[0].Name != "b" || items[1].Name != "d" || items[2].Name != "" || items[3].Name != "b" {
+		t.Fatalf("unexpected populated names: %+v %+v %+v %+v", items[0], items[1], items[2], items[3])
+	}
+	if rec.count() != 1 {
+		t.Fatalf("expected single batch, got %v", rec.batches)
+	}
+	for _, k := range rec.batches[0] {
+		if k == 0 {
+			t.Fatalf("zero key must not be fetched: %v", rec.batches[0])
+		}
+	}
+}
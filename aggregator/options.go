@@ -9,9 +9,30 @@ const (
 )
 
 type options struct {
-	limit   int
-	timeout time.Duration
-	retry   int
+	limit          int
+	timeout        time.Duration
+	retry          RetryPolicy
+	fetcherTimeout time.Duration
+	partial        bool
+	breaker        *CircuitBreaker
+	hedger         *Hedger
+}
+
+// newOptions 生成默认配置并应用 opts
+func newOptions(opts []Option) *options {
+	o := &options{
+		limit:   defaultLimit,
+		timeout: defaultTimeout, // 0 表示不限制
+		retry:   DefaultRetryPolicy(defaultRetry),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.limit <= 0 {
+		o.limit = defaultLimit
+	}
+	return o
 }
 
 type Option func(*options)
@@ -26,7 +47,61 @@ func WithTimeout(timeout time.Duration) Option {
 	return func(o *options) { o.timeout = timeout }
 }
 
-// WithRetry 设置任务失败后的重试次数
+// WithRetry 设置任务失败后的重试次数（使用默认退避参数）
 func WithRetry(retry int) Option {
-	return func(o *options) { o.retry = retry }
+	return func(o *options) { o.retry.MaxRetries = retry }
+}
+
+// WithRetryPolicy 设置任务失败后的重试策略，可被 Task.Retry 覆盖
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) { o.retry = policy }
+}
+
+// WithFetcherTimeout 设置每个任务单次尝试的超时时间，可被 Task.Timeout 覆盖
+func WithFetcherTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.fetcherTimeout = timeout }
+}
+
+// WithPartialFailure 启用部分失败模式：任一任务出错不会取消其余任务，
+// 执行结束后以 ParallelErrors 返回全部失败
+func WithPartialFailure() Option {
+	return func(o *options) { o.partial = true }
+}
+
+// WithCircuitBreaker 为具名任务启用熔断器，熔断打开时任务被跳过并返回 ErrCircuitOpen
+func WithCircuitBreaker(cb *CircuitBreaker) Option {
+	return func(o *options) { o.breaker = cb }
+}
+
+const (
+	defaultBatchWait    = 2 * time.Millisecond
+	defaultMaxBatchSize = 0 // 0 表示不限制
+)
+
+type loaderOptions struct {
+	wait     time.Duration
+	maxBatch int
+	cache    bool
+}
+
+type LoaderOption func(*loaderOptions)
+
+// WithBatchWait 设置批处理窗口：首个键入队后最多等待多久再发起批量抓取
+func WithBatchWait(wait time.Duration) LoaderOption {
+	return func(o *loaderOptions) { o.wait = wait }
+}
+
+// WithMaxBatchSize 设置单批最大键数，达到上限立即发起抓取（0 表示不限制）
+func WithMaxBatchSize(size int) LoaderOption {
+	return func(o *loaderOptions) { o.maxBatch = size }
+}
+
+// WithLoaderCache 设置是否启用请求级缓存（默认启用）
+func WithLoaderCache(enabled bool) LoaderOption {
+	return func(o *loaderOptions) { o.cache = enabled }
+}
+
+// WithHedging 启用对冲请求：单次尝试超过对冲延迟仍未完成时发起副本，先成功者胜出
+func WithHedging(h *Hedger) Option {
+	return func(o *options) { o.hedger = h }
 }
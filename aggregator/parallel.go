@@ -2,8 +2,12 @@ package aggregator
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -12,109 +16,254 @@ import (
 // ParallelFetcher 定义了并发任务的契约
 type ParallelFetcher func(ctx context.Context) error
 
-// ExecuteParallel 并行执行多个 Fetch 任务
-func ExecuteParallel(ctx context.Context, fetchers []ParallelFetcher, opts ...Option) error {
-	if len(fetchers) == 0 {
-		return nil
+// Task 带名称与独立策略的并发任务
+type Task struct {
+	Name    string          // 任务名称，用于错误信息与熔断器（为空时不启用熔断）
+	Fetch   ParallelFetcher // 任务函数
+	Timeout time.Duration   // 单次尝试超时，0 表示使用 WithFetcherTimeout
+	Retry   *RetryPolicy    // 重试策略，nil 表示使用 WithRetry / WithRetryPolicy
+}
+
+// FetchError 部分失败模式下单个任务的错误
+type FetchError struct {
+	Index int    // 任务在输入中的下标
+	Name  string // 任务名称
+	Err   error
+}
+
+func (e *FetchError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("fetcher %q: %v", e.Name, e.Err)
+	}
+	return fmt.Sprintf("fetcher #%d: %v", e.Index, e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// ParallelErrors 部分失败模式下收集到的全部任务错误（按下标升序）
+type ParallelErrors []*FetchError
+
+func (e ParallelErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
 	}
+	return fmt.Sprintf("%d fetchers failed: %s", len(e), strings.Join(msgs, "; "))
+}
 
-	// 默认配置
-	defaultOpts := &options{
-		limit:   defaultLimit,
-		timeout: defaultTimeout, // 0 表示不限制
-		retry:   defaultRetry,
+func (e ParallelErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
 	}
-	for _, opt := range opts {
-		opt(defaultOpts)
+	return errs
+}
+
+// ExecuteParallel 并行执行多个 Fetch 任务
+func ExecuteParallel(ctx context.Context, fetchers []ParallelFetcher, opts ...Option) error {
+	tasks := make([]Task, len(fetchers))
+	for i, f := range fetchers {
+		tasks[i] = Task{Fetch: f}
 	}
+	return ExecuteTasks(ctx, tasks, opts...)
+}
 
-	if defaultOpts.limit <= 0 {
-		defaultOpts.limit = defaultLimit
+// ExecuteTasks 并行执行多个具名任务。
+// 默认模式下任一任务出错即取消其余任务并返回该错误；
+// 启用 WithPartialFailure 时执行全部任务，并以 ParallelErrors 返回所有失败。
+func ExecuteTasks(ctx context.Context, tasks []Task, opts ...Option) error {
+	if len(tasks) == 0 {
+		return nil
 	}
 
-	if defaultOpts.timeout > 0 {
+	o := newOptions(opts)
+
+	if o.timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, defaultOpts.timeout)
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
 		defer cancel()
 	}
 
+	if o.partial {
+		return executeCollect(ctx, tasks, o)
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
-	g.SetLimit(defaultOpts.limit)
+	g.SetLimit(o.limit)
 
-	for _, fetcher := range fetchers {
-		if fetcher == nil {
+	for _, task := range tasks {
+		if task.Fetch == nil {
 			continue
 		}
-		f := fetcher
 		g.Go(func() error {
-			var lastErr error
-			for i := 0; i <= defaultOpts.retry; i++ {
-				if err := ctx.Err(); err != nil {
-					return err
-				}
-
-				lastErr = safeRun(ctx, f)
-				if lastErr == nil {
-					return nil
-				}
-
-				// 如果还没达到最大重试次数，执行指数退避
-				if i < defaultOpts.retry {
-					// 根据当前重试次数 i 计算等待时间并 Sleep
-					if err := backoffWait(ctx, i); err != nil {
-						return err
-					}
-				}
-			}
-			return lastErr
+			return runTask(ctx, task, o)
 		})
 	}
 
 	return g.Wait()
 }
 
-// safeRun 增加 Panic 恢复
-func safeRun(ctx context.Context, f ParallelFetcher) (err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("parallel fetcher panic: %v", r)
+// executeCollect 部分失败模式：执行全部任务并收集错误
+func executeCollect(ctx context.Context, tasks []Task, o *options) error {
+	var (
+		mu   sync.Mutex
+		errs ParallelErrors
+		g    errgroup.Group
+	)
+	g.SetLimit(o.limit)
+
+	for i, task := range tasks {
+		if task.Fetch == nil {
+			continue
 		}
-	}()
-	return f(ctx)
+		g.Go(func() error {
+			if err := runTask(ctx, task, o); err != nil {
+				mu.Lock()
+				errs = append(errs, &FetchError{Index: i, Name: task.Name, Err: err})
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(a, b int) bool { return errs[a].Index < errs[b].Index })
+	return errs
 }
 
-// backoffWait 实现指数退避等待
-func backoffWait(ctx context.Context, attempt int) error {
-	// 基础参数配置
-	const (
-		baseDelay = 20 * time.Millisecond // 初始等待时间
-		maxDelay  = 2 * time.Second       // 最大等待间隔
-	)
+// ParallelMap 对 inputs 并发执行 fn，结果按输入顺序返回。
+// 默认模式下任一调用出错即取消其余调用并返回该错误；
+// 启用 WithPartialFailure 时返回全部结果（失败项为零值）以及 ParallelErrors。
+func ParallelMap[T any, R any](ctx context.Context, inputs []T, fn func(ctx context.Context, in T) (R, error), opts ...Option) ([]R, error) {
+	results := make([]R, len(inputs))
+	if len(inputs) == 0 {
+		return results, nil
+	}
 
-	// 计算指数延迟: baseDelay * 2^attempt
-	delay := baseDelay * (1 << uint(attempt))
-	if attempt > 30 { // 2^30 * 20ms ≈ 20,000 秒，远超 maxDelay
-		delay = maxDelay
-	} else {
-		delay = baseDelay * (1 << uint(attempt))
-		if delay > maxDelay {
-			delay = maxDelay
+	// 启用对冲时同一输入可能被并发执行多次，仅首个成功者写入结果
+	written := make([]atomic.Bool, len(inputs))
+	tasks := make([]Task, len(inputs))
+	for i, in := range inputs {
+		tasks[i] = Task{Fetch: func(ctx context.Context) error {
+			r, err := fn(ctx, in)
+			if err != nil {
+				return err
+			}
+			if written[i].CompareAndSwap(false, true) {
+				results[i] = r
+			}
+			return nil
+		}}
+	}
+
+	err := ExecuteTasks(ctx, tasks, opts...)
+	if err != nil && !newOptions(opts).partial {
+		return nil, err
+	}
+	return results, err
+}
+
+// runTask 执行单个任务：熔断判断、单次超时与按策略重试
+func runTask(ctx context.Context, task Task, o *options) error {
+	policy := o.retry
+	if task.Retry != nil {
+		policy = *task.Retry
+	}
+	timeout := o.fetcherTimeout
+	if task.Timeout > 0 {
+		timeout = task.Timeout
+	}
+	var breaker *CircuitBreaker
+	if task.Name != "" {
+		breaker = o.breaker
+	}
+
+	var lastErr error
+	for i := 0; i <= policy.MaxRetries; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if breaker != nil && !breaker.Allow(task.Name) {
+			if lastErr != nil {
+				return lastErr
+			}
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, task.Name)
+		}
+
+		start := time.Now()
+		if o.hedger != nil {
+			lastErr = o.hedger.run(ctx, task.Name, task.Fetch, timeout)
+		} else {
+			lastErr = runAttempt(ctx, task.Fetch, timeout)
+		}
+		elapsed := time.Since(start)
+
+		if breaker != nil {
+			if ctx.Err() != nil && lastErr != nil {
+				// 上游已取消，结果不代表依赖的健康状况
+				breaker.abandon(task.Name)
+			} else {
+				breaker.Record(task.Name, lastErr)
+			}
+		}
+
+		if lastErr == nil {
+			return nil
+		}
+
+		// 如果还没达到最大重试次数，按策略退避等待
+		if i < policy.MaxRetries {
+			if !policy.shouldRetry(lastErr) {
+				return lastErr
+			}
+
+			// 截止时间前无法完成“退避 + 一次尝试”时不再重试，以上一次尝试耗时估算
+			delay := policy.Backoff(i)
+			if !fitsDeadline(ctx, delay+elapsed) {
+				return lastErr
+			}
+			if err := sleepContext(ctx, delay); err != nil {
+				return err
+			}
 		}
 	}
+	return lastErr
+}
 
-	// 引入随机抖动 (Jitter)，防止大量请求在同一瞬间重试
-	// 实际延迟在 [0.5 * delay, 1.5 * delay] 之间波动
-	jitter := time.Duration(rand.Int64N(int64(delay)))
-	delay = delay/2 + jitter
+// fitsDeadline 判断 ctx 截止时间前是否还剩余 d
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > d
+}
 
-	// 执行等待
-	timer := time.NewTimer(delay)
-	defer timer.Stop()
+// runAttempt 执行单次尝试，timeout > 0 时限制单次耗时
+func runAttempt(ctx context.Context, f ParallelFetcher, timeout time.Duration) error {
+	if timeout <= 0 {
+		return safeRun(ctx, f)
+	}
 
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-timer.C:
-		return nil
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := safeRun(attemptCtx, f)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("attempt timed out after %v: %w", timeout, err)
 	}
+	return err
+}
+
+// safeRun 增加 Panic 恢复
+func safeRun(ctx context.Context, f ParallelFetcher) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parallel fetcher panic: %v", r)
+		}
+	}()
+	return f(ctx)
 }
@@ -94,3 +94,144 @@ func TestExecuteParallel_ContextCanceled(t *testing.T) {
 		t.Fatalf("expected context.Canceled error, got %v", err)
 	}
 }
+
+func TestExecuteTasks_PartialFailureCollectsAllErrors(t *testing.T) {
+	var done int32
+	errA := errors.New("a-failed")
+	errC := errors.New("c-failed")
+
+	err := ExecuteTasks(t.Context(), []Task{
+		{Name: "a", Fetch: func(ctx context.Context) error { return errA }},
+		{Name: "b", Fetch: func(ctx context.Context) error {
+			// 部分失败模式下其他任务出错不会取消本任务
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(20 * time.Millisecond):
+				atomic.AddInt32(&done, 1)
+				return nil
+			}
+		}},
+		{Fetch: func(ctx context.Context) error { return errC }},
+	}, WithPartialFailure())
+
+	var perrs ParallelErrors
+	if !errors.As(err, &perrs) {
+		t.Fatalf("expected ParallelErrors, got %v", err)
+	}
+	if len(perrs) != 2 || perrs[0].Name != "a" || perrs[1].Index != 2 {
+		t.Fatalf("unexpected collected errors: %v", perrs)
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errC) {
+		t.Fatalf("expected errors.Is to match both failures, got %v", err)
+	}
+	if atomic.LoadInt32(&done) != 1 {
+		t.Fatalf("expected successful task to complete")
+	}
+}
+
+func TestParallelMap_PreservesInputOrder(t *testing.T) {
+	inputs := []int{30, 10, 20}
+	results, err := ParallelMap(t.Context(), inputs, func(ctx context.Context, in int) (string, error) {
+		time.Sleep(time.Duration(in) * time.Millisecond)
+		return strings.Repeat("x", in/10), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 || results[0] != "xxx" || results[1] != "x" || results[2] != "xx" {
+		t.Fatalf("unexpected results order: %v", results)
+	}
+
+	_, err = ParallelMap(t.Context(), inputs, func(ctx context.Context, in int) (int, error) {
+		if in == 10 {
+			return 0, errors.New("bad-input")
+		}
+		return in, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "bad-input") {
+		t.Fatalf("expected fail-fast error, got %v", err)
+	}
+
+	results2, err := ParallelMap(t.Context(), inputs, func(ctx context.Context, in int) (int, error) {
+		if in == 10 {
+			return 0, errors.New("bad-input")
+		}
+		return in * 2, nil
+	}, WithPartialFailure())
+	var perrs ParallelErrors
+	if !errors.As(err, &perrs) || len(perrs) != 1 || perrs[0].Index != 1 {
+		t.Fatalf("expected single collected error at index 1, got %v", err)
+	}
+	if results2[0] != 60 || results2[1] != 0 || results2[2] != 40 {
+		t.Fatalf("unexpected partial results: %v", results2)
+	}
+}
+
+func TestExecuteTasks_FetcherTimeoutAndRetryPolicy(t *testing.T) {
+	var attempts int32
+	err := ExecuteTasks(t.Context(), []Task{{
+		Name: "slow-then-fast",
+		Fetch: func(ctx context.Context) error {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				<-ctx.Done() // 首次尝试超时
+				return ctx.Err()
+			}
+			return nil
+		},
+		Timeout: 10 * time.Millisecond,
+		Retry:   &RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond},
+	}})
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+
+	// RetryIf 返回 false 时不再重试
+	attempts = 0
+	errPermanent := errors.New("permanent")
+	err = ExecuteParallel(t.Context(), []ParallelFetcher{func(ctx context.Context) error {
+		atomic.AddInt32(&attempts, 1)
+		return errPermanent
+	}}, WithRetryPolicy(RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		RetryIf:    func(err error) bool { return !errors.Is(err, errPermanent) },
+	}))
+	if !errors.Is(err, errPermanent) || atomic.LoadInt32(&attempts) != 1 {
+		t.Fatalf("expected single attempt with permanent error, got %v after %d attempts", err, attempts)
+	}
+}
+
+func TestExecuteTasks_CircuitBreakerSkipsFailingDependency(t *testing.T) {
+	cb := NewCircuitBreaker(2, 50*time.Millisecond)
+	var calls int32
+	failing := Task{Name: "inventory", Fetch: func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("unavailable")
+	}}
+
+	for i := 0; i < 2; i++ {
+		_ = ExecuteTasks(t.Context(), []Task{failing}, WithCircuitBreaker(cb))
+	}
+	if cb.State("inventory") != BreakerOpen {
+		t.Fatalf("expected breaker open, got %v", cb.State("inventory"))
+	}
+
+	err := ExecuteTasks(t.Context(), []Task{failing}, WithCircuitBreaker(cb))
+	if !errors.Is(err, ErrCircuitOpen) || atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("expected skipped task with ErrCircuitOpen, got %v after %d calls", err, calls)
+	}
+
+	// 冷却后半开探测，成功则关闭
+	time.Sleep(60 * time.Millisecond)
+	recovered := Task{Name: "inventory", Fetch: func(ctx context.Context) error { return nil }}
+	if err = ExecuteTasks(t.Context(), []Task{recovered}, WithCircuitBreaker(cb)); err != nil {
+		t.Fatalf("expected probe to succeed, got %v", err)
+	}
+	if cb.State("inventory") != BreakerClosed {
+		t.Fatalf("expected breaker closed after successful probe, got %v", cb.State("inventory"))
+	}
+}
@@ -0,0 +1,160 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+)
+
+// Relation 声明资源 R 上的一个关联字段：通过键获取器收集键，经 BatchFunc 批量抓取，
+// 回填到 R，并递归回填关联对象自身的嵌套关联。由 HasOne / HasMany 构造。
+type Relation[R any] interface {
+	// Name 返回关联名称，用于错误信息
+	Name() string
+
+	populate(ctx context.Context, items []R, opts []Option) error
+}
+
+// PopulateGraph 按声明的关联图回填 items：
+// 同一层级的多个关联作为独立分支通过 ExecuteParallel 并行执行；
+// 每个关联对整层对象只发起一次批量抓取，再对抓取结果整体回填下一层嵌套关联。
+// opts 作用于每一层的 ExecuteParallel（并发数、超时、重试）。
+func PopulateGraph[R any](ctx context.Context, items []R, relations []Relation[R], opts ...Option) error {
+	if len(items) == 0 || len(relations) == 0 {
+		return nil
+	}
+
+	fetchers := make([]ParallelFetcher, 0, len(relations))
+	for _, rel := range relations {
+		if rel == nil {
+			continue
+		}
+		fetchers = append(fetchers, func(ctx context.Context) error {
+			if err := rel.populate(ctx, items, opts); err != nil {
+				return fmt.Errorf("populate relation %q: %w", rel.Name(), err)
+			}
+			return nil
+		})
+	}
+	return ExecuteParallel(ctx, fetchers, opts...)
+}
+
+// HasOne 声明一对一关联：key 从 R 提取单个键（零值键会被忽略），fetch 批量抓取，setter 回填，
+// nested 为关联对象 T 的嵌套关联
+func HasOne[R any, K comparable, T any](
+	name string,
+	key IDGetter[R, K],
+	fetch BatchFunc[K, T],
+	setter Setter[R, T],
+	nested ...Relation[T],
+) Relation[R] {
+	return &relation[R, K, T]{
+		name: name,
+		keys: func(r R) []K {
+			return []K{key(r)}
+		},
+		fetch: fetch,
+		set: func(r R, vals []T) {
+			setter(r, vals[0])
+		},
+		nested: nested,
+	}
+}
+
+// HasMany 声明一对多关联：keys 从 R 提取键列表（零值键会被忽略），fetch 批量抓取，setter 按键顺序回填，
+// nested 为关联对象 T 的嵌套关联
+func HasMany[R any, K comparable, T any](
+	name string,
+	keys IDListGetter[R, K],
+	fetch BatchFunc[K, T],
+	setter MultiSetter[R, T],
+	nested ...Relation[T],
+) Relation[R] {
+	return &relation[R, K, T]{
+		name:   name,
+		keys:   keys,
+		fetch:  fetch,
+		set:    setter,
+		nested: nested,
+	}
+}
+
+// relation 关联的通用实现
+type relation[R any, K comparable, T any] struct {
+	name   string
+	keys   func(R) []K
+	fetch  BatchFunc[K, T]
+	set    func(R, []T)
+	nested []Relation[T]
+}
+
+func (rel *relation[R, K, T]) Name() string {
+	return rel.name
+}
+
+func (rel *relation[R, K, T]) populate(ctx context.Context, items []R, opts []Option) error {
+	var zeroK K
+
+	// 1. 收集整层对象的唯一键
+	itemKeys := make([][]K, len(items))
+	seen := make(map[K]struct{})
+	var keys []K
+	for i, item := range items {
+		if isNil(item) {
+			continue
+		}
+		for _, k := range rel.keys(item) {
+			if k == zeroK {
+				continue
+			}
+			itemKeys[i] = append(itemKeys[i], k)
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				keys = append(keys, k)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// 2. 一次批量抓取
+	data, err := rel.fetch(ctx, keys)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	// 3. 先回填下一层嵌套关联，保证回填到父对象时关联对象已完整
+	vals := make([]T, 0, len(data))
+	index := make(map[K]int, len(data))
+	for _, k := range keys {
+		if v, ok := data[k]; ok && !isNil(v) {
+			index[k] = len(vals)
+			vals = append(vals, v)
+		}
+	}
+	if len(rel.nested) > 0 && len(vals) > 0 {
+		if err = PopulateGraph(ctx, vals, rel.nested, opts...); err != nil {
+			return err
+		}
+	}
+
+	// 4. 回填当前层
+	for i, item := range items {
+		if len(itemKeys[i]) == 0 {
+			continue
+		}
+		var matched []T
+		for _, k := range itemKeys[i] {
+			if idx, ok := index[k]; ok {
+				matched = append(matched, vals[idx])
+			}
+		}
+		if len(matched) > 0 {
+			rel.set(item, matched)
+		}
+	}
+	return nil
+}
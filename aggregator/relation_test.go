@@ -0,0 +1,169 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type graphDept struct {
+	ID        uint32
+	Name      string
+	ManagerID uint32
+	Manager   *graphUser
+}
+
+type graphUser struct {
+	ID     uint32
+	Name   string
+	DeptID uint32
+	Dept   *graphDept
+}
+
+type graphPost struct {
+	ID        uint32
+	AuthorID  uint32
+	TagIDs    []string
+	Author    *graphUser
+	TagLabels []string
+}
+
+type fetchLog struct {
+	mu    sync.Mutex
+	calls map[string][]int
+}
+
+func (l *fetchLog) record(name string, n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.calls == nil {
+		l.calls = map[string][]int{}
+	}
+	l.calls[name] = append(l.calls[name], n)
+}
+
+func TestPopulateGraph_MultiLevel(t *testing.T) {
+	log := &fetchLog{}
+
+	users := map[uint32]*graphUser{
+		1: {ID: 1, Name: "alice", DeptID: 10},
+		2: {ID: 2, Name: "bob", DeptID: 10},
+		3: {ID: 3, Name: "carol", DeptID: 20},
+	}
+	depts := map[uint32]*graphDept{
+		10: {ID: 10, Name: "eng", ManagerID: 3},
+		20: {ID: 20, Name: "ops"},
+	}
+
+	fetchUsers := func(ctx context.Context, ids []uint32) (map[uint32]*graphUser, error) {
+		log.record("users", len(ids))
+		res := make(map[uint32]*graphUser, len(ids))
+		for _, id := range ids {
+			if u, ok := users[id]; ok {
+				res[id] = u
+			}
+		}
+		return res, nil
+	}
+	fetchDepts := func(ctx context.Context, ids []uint32) (map[uint32]*graphDept, error) {
+		log.record("depts", len(ids))
+		res := make(map[uint32]*graphDept, len(ids))
+		for _, id := range ids {
+			if d, ok := depts[id]; ok {
+				res[id] = d
+			}
+		}
+		return res, nil
+	}
+	fetchTags := func(ctx context.Context, ids []string) (map[string]string, error) {
+		log.record("tags", len(ids))
+		res := make(map[string]string, len(ids))
+		for _, id := range ids {
+			res[id] = strings.ToUpper(id)
+		}
+		return res, nil
+	}
+
+	posts := []*graphPost{
+		{ID: 100, AuthorID: 1, TagIDs: []string{"go", "db"}},
+		{ID: 101, AuthorID: 2, TagIDs: []string{"go"}},
+		{ID: 102, AuthorID: 0},
+	}
+
+	relations := []Relation[*graphPost]{
+		HasOne("author",
+			func(p *graphPost) uint32 { return p.AuthorID },
+			fetchUsers,
+			func(p *graphPost, u *graphUser) { p.Author = u },
+			HasOne("dept",
+				func(u *graphUser) uint32 { return u.DeptID },
+				fetchDepts,
+				func(u *graphUser, d *graphDept) { u.Dept = d },
+				HasOne("manager",
+					func(d *graphDept) uint32 { return d.ManagerID },
+					fetchUsers,
+					func(d *graphDept, u *graphUser) { d.Manager = u },
+				),
+			),
+		),
+		HasMany("tags",
+			func(p *graphPost) []string { return p.TagIDs },
+			fetchTags,
+			func(p *graphPost, labels []string) { p.TagLabels = labels },
+		),
+	}
+
+	if err := PopulateGraph(context.Background(), posts, relations); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p := posts[0]
+	if p.Author == nil || p.Author.Name != "alice" {
+		t.Fatalf("expected author alice, got %+v", p.Author)
+	}
+	if p.Author.Dept == nil || p.Author.Dept.Name != "eng" {
+		t.Fatalf("expected dept eng, got %+v", p.Author.Dept)
+	}
+	if p.Author.Dept.Manager == nil || p.Author.Dept.Manager.Name != "carol" {
+		t.Fatalf("expected manager carol, got %+v", p.Author.Dept.Manager)
+	}
+	if len(p.TagLabels) != 2 || p.TagLabels[0] != "GO" || p.TagLabels[1] != "DB" {
+		t.Fatalf("unexpected tag labels: %v", p.TagLabels)
+	}
+	if posts[2].Author != nil {
+		t.Fatalf("expected zero author id to be skipped, got %+v", posts[2].Author)
+	}
+
+	// 每层每个关联只抓取一次，且键已去重
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if got := log.calls["users"]; len(got) != 2 || got[0]+got[1] != 3 {
+		t.Fatalf("expected users fetched once per level (2+1 keys), got %v", got)
+	}
+	if got := log.calls["depts"]; len(got) != 1 || got[0] != 1 {
+		t.Fatalf("expected depts fetched once with 1 key, got %v", got)
+	}
+	if got := log.calls["tags"]; len(got) != 1 || got[0] != 2 {
+		t.Fatalf("expected tags fetched once with 2 keys, got %v", got)
+	}
+}
+
+func TestPopulateGraph_ErrorNamesRelation(t *testing.T) {
+	errFetch := errors.New("backend down")
+
+	items := []*testItem{{ID: 1}}
+	relations := []Relation[*testItem]{
+		HasOne("name",
+			func(it *testItem) uint32 { return it.ID },
+			func(ctx context.Context, ids []uint32) (map[uint32]string, error) { return nil, errFetch },
+			func(it *testItem, v string) { it.Name = v },
+		),
+	}
+
+	err := PopulateGraph(context.Background(), items, relations)
+	if !errors.Is(err, errFetch) || !strings.Contains(err.Error(), `"name"`) {
+		t.Fatalf("expected wrapped fetch error naming relation, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultBaseDelay  = 20 * time.Millisecond // 初始等待时间
+	defaultMaxDelay   = 2 * time.Second       // 最大等待间隔
+	defaultMultiplier = 2.0                   // 指数退避倍数
+	defaultJitter     = 0.5                   // 抖动幅度
+)
+
+// RetryPolicy 任务失败后的重试策略（指数退避 + 抖动）
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数，0 表示不重试
+	BaseDelay  time.Duration // 初始等待时间，0 使用默认值 20ms
+	MaxDelay   time.Duration // 最大等待间隔，0 使用默认值 2s
+	Multiplier float64       // 退避倍数，<=0 使用默认值 2
+	Jitter     float64       // 抖动幅度（0~1），实际等待在 [(1-Jitter)*d, (1+Jitter)*d) 之间，0 表示不抖动
+
+	// RetryIf 判断错误是否可重试，nil 表示除熔断外的错误均重试
+	RetryIf func(err error) bool
+}
+
+// DefaultRetryPolicy 返回默认重试策略：20ms 起步、2 倍退避、最长 2s、±50% 抖动
+func DefaultRetryPolicy(maxRetries int) RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: maxRetries,
+		BaseDelay:  defaultBaseDelay,
+		MaxDelay:   defaultMaxDelay,
+		Multiplier: defaultMultiplier,
+		Jitter:     defaultJitter,
+	}
+}
+
+// Backoff 计算第 attempt 次（从 0 开始）失败后的等待时间
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = defaultBaseDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultMultiplier
+	}
+
+	// 计算指数延迟: base * multiplier^attempt，并限制在 maxDelay 以内
+	delay := maxDelay
+	if d := float64(base) * math.Pow(multiplier, float64(attempt)); d < float64(maxDelay) {
+		delay = time.Duration(d)
+	}
+
+	// 引入随机抖动 (Jitter)，防止大量请求在同一瞬间重试
+	if jitter := min(p.Jitter, 1); jitter > 0 && delay > 0 {
+		spread := time.Duration(float64(delay) * jitter)
+		if spread > 0 {
+			delay = delay - spread + time.Duration(rand.Int64N(int64(spread)*2))
+		}
+	}
+	return delay
+}
+
+// shouldRetry 判断错误是否可重试
+func (p RetryPolicy) shouldRetry(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	if p.RetryIf != nil {
+		return p.RetryIf(err)
+	}
+	return true
+}
+
+// sleepContext 等待 d，ctx 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
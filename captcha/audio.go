@@ -0,0 +1,300 @@
+package captcha
+
+import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/rand/v2"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mojocn/base64Captcha"
+)
+
+// audioSampleRate 发音样本与输出 WAV 的采样率，单声道 8 位无符号 PCM
+const audioSampleRate = 8000
+
+// ErrAudioSampleMissing 字符集中的字符没有对应语言的发音样本
+var ErrAudioSampleMissing = errors.New("audio sample missing")
+
+// 内置数字发音样本：sounds/<lang>/<digit>.wav，来自 base64Captcha（源自 dchest/captcha）
+//
+//go:embed sounds
+var builtinSounds embed.FS
+
+var builtinAudio = sync.OnceValues(func() (map[string]AudioSamples, error) {
+	return loadAudio(builtinSounds, "sounds")
+})
+
+// AudioSamples 单个语言的发音样本：小写字符 -> 8kHz 单声道 8 位无符号 PCM
+type AudioSamples map[rune][]byte
+
+// loadAudio 读取 dir/<lang>/<char>.wav，目录不存在时返回空
+func loadAudio(fsys fs.FS, dir string) (map[string]AudioSamples, error) {
+	langs, err := readDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	audio := make(map[string]AudioSamples)
+	for _, lang := range langs {
+		if !lang.IsDir() {
+			continue
+		}
+		entries, err := readDir(fsys, path.Join(dir, lang.Name()))
+		if err != nil {
+			return nil, err
+		}
+		samples := make(AudioSamples)
+		for _, e := range entries {
+			if e.IsDir() || !hasExt(e.Name(), ".wav") {
+				continue
+			}
+			base := strings.ToLower(strings.TrimSuffix(e.Name(), path.Ext(e.Name())))
+			r, size := utf8.DecodeRuneInString(base)
+			if size == 0 || size != len(base) {
+				continue // 文件名必须是单个字符
+			}
+			name := path.Join(dir, lang.Name(), e.Name())
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			if samples[r], err = decodeWAV(data); err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+			}
+		}
+		if len(samples) > 0 {
+			audio[lang.Name()] = samples
+		}
+	}
+	return audio, nil
+}
+
+// decodeWAV 解码 8kHz 单声道 PCM WAV，16 位样本转换为 8 位无符号
+func decodeWAV(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+	var (
+		channels, bits uint16
+		rate           uint32
+		hasFmt         bool
+	)
+	for chunk := data[12:]; len(chunk) >= 8; {
+		id, size := string(chunk[0:4]), int(binary.LittleEndian.Uint32(chunk[4:8]))
+		if size > len(chunk)-8 {
+			return nil, errors.New("truncated WAV chunk")
+		}
+		body := chunk[8 : 8+size]
+		switch id {
+		case "fmt ":
+			if size < 16 || binary.LittleEndian.Uint16(body[0:2]) != 1 {
+				return nil, errors.New("only PCM WAV is supported")
+			}
+			channels = binary.LittleEndian.Uint16(body[2:4])
+			rate = binary.LittleEndian.Uint32(body[4:8])
+			bits = binary.LittleEndian.Uint16(body[14:16])
+			hasFmt = true
+		case "data":
+			if !hasFmt {
+				return nil, errors.New("missing fmt chunk")
+			}
+			if channels != 1 || rate != audioSampleRate {
+				return nil, fmt.Errorf("need mono %d Hz, got %d channels at %d Hz", audioSampleRate, channels, rate)
+			}
+			switch bits {
+			case 8:
+				return bytes.Clone(body), nil
+			case 16:
+				pcm := make([]byte, len(body)/2)
+				for i := range pcm {
+					pcm[i] = byte(int(int8(body[2*i+1])) + 128) // 取高字节
+				}
+				return pcm, nil
+			default:
+				return nil, fmt.Errorf("unsupported bits per sample: %d", bits)
+			}
+		}
+		chunk = chunk[8+size+size%2:]
+	}
+	return nil, errors.New("missing data chunk")
+}
+
+// encodeWAV 将 8 位无符号 PCM 封装为 WAV
+func encodeWAV(pcm []byte) []byte {
+	b := make([]byte, 44, 44+len(pcm))
+	copy(b[0:], "RIFF")
+	binary.LittleEndian.PutUint32(b[4:], uint32(36+len(pcm)))
+	copy(b[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(b[16:], 16)
+	binary.LittleEndian.PutUint16(b[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(b[22:], 1) // 单声道
+	binary.LittleEndian.PutUint32(b[24:], audioSampleRate)
+	binary.LittleEndian.PutUint32(b[28:], audioSampleRate)
+	binary.LittleEndian.PutUint16(b[32:], 1)
+	binary.LittleEndian.PutUint16(b[34:], 8)
+	copy(b[36:], "data")
+	binary.LittleEndian.PutUint32(b[40:], uint32(len(pcm)))
+	return append(b, pcm...)
+}
+
+// audioSamples 返回语言的发音样本：资源包中的样本优先，其余使用内置样本；未知语言使用 en
+func (c *Captcha) audioSamples(lang string) (AudioSamples, error) {
+	builtin, err := builtinAudio()
+	if err != nil {
+		return nil, err
+	}
+	pack := c.config.Resources
+	if _, ok := builtin[lang]; !ok && pack.audio(lang) == nil {
+		lang = "en"
+	}
+
+	samples := make(AudioSamples)
+	for r, pcm := range builtin[lang] {
+		samples[r] = pcm
+	}
+	for r, pcm := range pack.audio(lang) {
+		samples[r] = pcm
+	}
+	return samples, nil
+}
+
+// generateAudio 生成语音验证码：从字符集随机选取字符，拼接发音样本并混入背景噪声
+func (c *Captcha) generateAudio() (*RawCaptcha, error) {
+	cfg := c.config.AudioConfig.normalize()
+	samples, err := c.audioSamples(cfg.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	source := []rune(strings.ToLower(cfg.Source))
+	for _, r := range source {
+		if _, ok := samples[r]; !ok {
+			return nil, fmt.Errorf("%w: %q (%s)", ErrAudioSampleMissing, r, cfg.Language)
+		}
+	}
+
+	answer := make([]rune, cfg.CaptchaCount)
+	for i := range answer {
+		answer[i] = source[rand.IntN(len(source))]
+	}
+
+	pcm := mixAudio(answer, samples, cfg)
+	return &RawCaptcha{
+		ID:     base64Captcha.RandomId(),
+		Answer: string(answer),
+		Master: &Media{ContentType: "audio/wav", Data: encodeWAV(pcm)},
+	}, nil
+}
+
+// normalize 返回补全默认值并修正取值范围后的配置副本
+func (a *AudioConfig) normalize() *AudioConfig {
+	def := DefaultAudioConfig()
+	if a == nil {
+		return def
+	}
+	cfg := *a
+	if cfg.CaptchaCount <= 0 {
+		cfg.CaptchaCount = def.CaptchaCount
+	}
+	if cfg.Language == "" {
+		cfg.Language = def.Language
+	}
+	if cfg.Source == "" {
+		cfg.Source = def.Source
+	}
+	// 零值使用默认值，只设置了部分字段的旧配置仍带有噪声与间隔
+	if cfg.NoiseLevel == 0 {
+		cfg.NoiseLevel = def.NoiseLevel
+	}
+	cfg.NoiseLevel = min(max(cfg.NoiseLevel, 0), 1)
+	if cfg.MinSpeed <= 0 {
+		cfg.MinSpeed = def.MinSpeed
+	}
+	if cfg.MaxSpeed <= 0 {
+		cfg.MaxSpeed = def.MaxSpeed
+	}
+	cfg.MaxSpeed = max(cfg.MaxSpeed, cfg.MinSpeed)
+	if cfg.MinGap <= 0 && cfg.MaxGap <= 0 {
+		cfg.MinGap, cfg.MaxGap = def.MinGap, def.MaxGap
+	}
+	cfg.MinGap = max(cfg.MinGap, 0)
+	cfg.MaxGap = max(cfg.MaxGap, cfg.MinGap)
+	return &cfg
+}
+
+// mixAudio 按随机语速拼接字符发音，字符前后插入随机静音，再叠加白噪声与倒放的发音作为背景
+func mixAudio(text []rune, samples AudioSamples, cfg *AudioConfig) []byte {
+	gap := func() int {
+		d := cfg.MinGap + time.Duration(rand.Int64N(int64(cfg.MaxGap-cfg.MinGap)+1))
+		return int(d.Seconds() * audioSampleRate)
+	}
+
+	voice := make([]float64, gap())
+	for _, r := range text {
+		speed := cfg.MinSpeed + rand.Float64()*(cfg.MaxSpeed-cfg.MinSpeed)
+		voice = append(voice, resample(pcmToFloat(samples[r]), speed)...)
+		voice = append(voice, make([]float64, gap())...)
+	}
+
+	if cfg.NoiseLevel > 0 {
+		// 倒放的发音听起来像人声但无法辨认，干扰自动语音识别
+		keys := make([]rune, 0, len(samples))
+		for r := range samples {
+			keys = append(keys, r)
+		}
+		for pos := 0; pos < len(voice); {
+			babble := pcmToFloat(samples[keys[rand.IntN(len(keys))]])
+			for i := range babble {
+				if pos+i >= len(voice) {
+					break
+				}
+				voice[pos+i] += babble[len(babble)-1-i] * cfg.NoiseLevel * 0.5
+			}
+			pos += len(babble)/2 + rand.IntN(len(babble)+1) + 1
+		}
+		for i := range voice {
+			voice[i] += (rand.Float64()*2 - 1) * cfg.NoiseLevel * 0.3
+		}
+	}
+
+	pcm := make([]byte, len(voice))
+	for i, v := range voice {
+		pcm[i] = byte(math.Round(min(max(v, -1), 1)*127) + 128)
+	}
+	return pcm
+}
+
+func pcmToFloat(pcm []byte) []float64 {
+	out := make([]float64, len(pcm))
+	for i, b := range pcm {
+		out[i] = (float64(b) - 128) / 128
+	}
+	return out
+}
+
+// resample 线性插值改变语速（同时改变音高），speed > 1 时变快
+func resample(in []float64, speed float64) []float64 {
+	if len(in) == 0 || speed == 1 {
+		return in
+	}
+	out := make([]float64, int(float64(len(in))/speed))
+	for i := range out {
+		pos := float64(i) * speed
+		j := int(pos)
+		if j+1 >= len(in) {
+			out[i] = in[len(in)-1]
+			continue
+		}
+		frac := pos - float64(j)
+		out[i] = in[j]*(1-frac) + in[j+1]*frac
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+package captcha
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toneWAV 生成指定频率的 8 位 PCM WAV，作为测试用的发音样本
+func toneWAV(freq float64, d time.Duration) []byte {
+	pcm := make([]byte, int(d.Seconds()*audioSampleRate))
+	for i := range pcm {
+		pcm[i] = byte(128 + 100*math.Sin(2*math.Pi*freq*float64(i)/audioSampleRate))
+	}
+	return encodeWAV(pcm)
+}
+
+func letterPack(t *testing.T, letters string) *ResourcePack {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	for i, r := range letters {
+		fsys["audio/en/"+string(r)+".wav"] = &fstest.MapFile{Data: toneWAV(300+float64(i)*40, 200*time.Millisecond)}
+	}
+	pack, err := LoadResourcePack(fsys)
+	require.NoError(t, err)
+	return pack
+}
+
+func decodeAudio(t *testing.T, b64 string) []byte {
+	t.Helper()
+	require.True(t, strings.HasPrefix(b64, "data:audio/wav;base64,"))
+	wav, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(b64, "data:audio/wav;base64,"))
+	require.NoError(t, err)
+	assert.Equal(t, uint16(1), binary.LittleEndian.Uint16(wav[22:]), "mono")
+	assert.Equal(t, uint32(audioSampleRate), binary.LittleEndian.Uint32(wav[24:]))
+	assert.Equal(t, uint16(8), binary.LittleEndian.Uint16(wav[34:]))
+	pcm, err := decodeWAV(wav)
+	require.NoError(t, err)
+	return pcm
+}
+
+func TestBuiltinAudioSamples(t *testing.T) {
+	audio, err := builtinAudio()
+	require.NoError(t, err)
+	for _, lang := range []string{"zh", "en", "ja", "ru", "de"} {
+		require.Len(t, audio[lang], 10, lang)
+		for r := '0'; r <= '9'; r++ {
+			assert.NotEmpty(t, audio[lang][r], "%s/%c", lang, r)
+		}
+	}
+}
+
+func TestGenerate_AudioLetters(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	defer store.Close()
+
+	captchaInstance := NewCaptchaWithStore(store,
+		WithDriverType(DriverAudio),
+		WithAudioLanguage("en"),
+		WithAudioSource("ABCDEFGH23456789"),
+		WithAudioCount(5),
+		WithResourcePack(letterPack(t, "abcdefgh")),
+	)
+
+	for range 5 {
+		id, b64Audio, answer, err := captchaInstance.Generate()
+		require.NoError(t, err)
+		assert.Regexp(t, `^[a-h2-9]{5}$`, answer)
+		assert.NotEmpty(t, decodeAudio(t, b64Audio))
+
+		// 字母不区分大小写
+		require.NoError(t, captchaInstance.Save(ctx, id, answer))
+		ok, err := captchaInstance.Verify(ctx, id, strings.ToUpper(answer))
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	// 没有字母样本时返回错误，而不是生成无法听写的音频
+	_, _, _, err := NewCaptchaWithStore(store,
+		WithDriverType(DriverAudio),
+		WithAudioSource("abc"),
+	).Generate()
+	assert.ErrorIs(t, err, ErrAudioSampleMissing)
+}
+
+func TestMixAudio(t *testing.T) {
+	samples := AudioSamples{'a': make([]byte, 800), 'b': make([]byte, 1600)}
+	for _, pcm := range samples {
+		for i := range pcm {
+			pcm[i] = 200
+		}
+	}
+	gap := 100 * time.Millisecond
+	gapLen := int(gap.Seconds() * audioSampleRate)
+
+	t.Run("固定语速与间隔", func(t *testing.T) {
+		cfg := (&AudioConfig{NoiseLevel: -1, MinSpeed: 1, MaxSpeed: 1, MinGap: gap, MaxGap: gap}).normalize()
+		pcm := mixAudio([]rune("ab"), samples, cfg)
+		assert.Len(t, pcm, 3*gapLen+800+1600)
+
+		// 无噪声时间隔为静音
+		for _, b := range pcm[:gapLen] {
+			require.Equal(t, byte(128), b)
+		}
+		assert.NotEqual(t, byte(128), pcm[gapLen])
+	})
+
+	t.Run("语速", func(t *testing.T) {
+		cfg := (&AudioConfig{NoiseLevel: -1, MinSpeed: 2, MaxSpeed: 2, MinGap: gap, MaxGap: gap}).normalize()
+		pcm := mixAudio([]rune("ab"), samples, cfg)
+		assert.Len(t, pcm, 3*gapLen+400+800)
+	})
+
+	t.Run("随机间隔", func(t *testing.T) {
+		cfg := (&AudioConfig{NoiseLevel: -1, MinSpeed: 1, MaxSpeed: 1, MinGap: gap, MaxGap: 3 * gap}).normalize()
+		for range 10 {
+			n := len(mixAudio([]rune("ab"), samples, cfg)) - 2400
+			assert.GreaterOrEqual(t, n, 3*gapLen)
+			assert.LessOrEqual(t, n, 9*gapLen)
+		}
+	})
+
+	t.Run("背景噪声", func(t *testing.T) {
+		cfg := (&AudioConfig{NoiseLevel: 0.5, MinGap: gap, MaxGap: gap}).normalize()
+		pcm := mixAudio([]rune("ab"), samples, cfg)
+		silent := 0
+		for _, b := range pcm[:gapLen] {
+			if b == 128 {
+				silent++
+			}
+		}
+		assert.Less(t, silent, gapLen/2)
+	})
+}
+
+func TestDecodeWAV(t *testing.T) {
+	// 16 位样本转换为 8 位
+	data := encodeWAV(nil)
+	binary.LittleEndian.PutUint16(data[32:], 2)
+	binary.LittleEndian.PutUint16(data[34:], 16)
+	data = append(data, 0x00, 0x7f, 0x00, 0x80, 0x00, 0x00)
+	binary.LittleEndian.PutUint32(data[40:], 6)
+	pcm, err := decodeWAV(data)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{255, 0, 128}, pcm)
+
+	// 采样率不匹配
+	data = encodeWAV([]byte{128})
+	binary.LittleEndian.PutUint32(data[24:], 44100)
+	_, err = decodeWAV(data)
+	assert.Error(t, err)
+
+	_, err = decodeWAV([]byte("not a wav"))
+	assert.Error(t, err)
+}
@@ -7,8 +7,12 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/golang/freetype/truetype"
 	"github.com/mojocn/base64Captcha"
 	"github.com/redis/go-redis/v9"
 	"github.com/wenlng/go-captcha/v2/base/option"
@@ -18,7 +22,7 @@ import (
 )
 
 type Captcha struct {
-	rdb           *redis.Client
+	store         Store
 	config        *Config
 	slideCaptcha  slide.Captcha  // 滑动验证码实例（懒加载）
 	clickCaptcha  click.Captcha  // 点击验证码实例（懒加载）
@@ -34,23 +38,35 @@ type Captcha struct {
 //	    captcha.WithStringCount(6),
 //	)
 func NewCaptcha(rdb *redis.Client, opts ...Option) *Captcha {
+	return NewCaptchaWithStore(NewRedisStore(rdb), opts...)
+}
+
+// NewCaptchaWithConfig 使用自定义配置创建实例
+func NewCaptchaWithConfig(rdb *redis.Client, config *Config) *Captcha {
+	return NewCaptchaWithStoreConfig(NewRedisStore(rdb), config)
+}
+
+// NewCaptchaWithStore 使用自定义答案存储创建实例
+// 示例:
+//
+//	store := captcha.NewMemoryStore()
+//	defer store.Close()
+//	cap := captcha.NewCaptchaWithStore(store, captcha.WithDriverType(captcha.DriverMath))
+func NewCaptchaWithStore(store Store, opts ...Option) *Captcha {
 	config := DefaultConfig()
 	for _, opt := range opts {
 		opt(config)
 	}
-	return &Captcha{
-		rdb:    rdb,
-		config: config,
-	}
+	return NewCaptchaWithStoreConfig(store, config)
 }
 
-// NewCaptchaWithConfig 使用自定义配置创建实例
-func NewCaptchaWithConfig(rdb *redis.Client, config *Config) *Captcha {
+// NewCaptchaWithStoreConfig 使用自定义答案存储与配置对象创建实例
+func NewCaptchaWithStoreConfig(store Store, config *Config) *Captcha {
 	if config == nil {
 		config = DefaultConfig()
 	}
 	return &Captcha{
-		rdb:    rdb,
+		store:  store,
 		config: config,
 	}
 }
@@ -72,15 +88,28 @@ func (c *Captcha) initSlideCaptcha() error {
 		slide.WithRangeGraphSize(option.RangeVal{Min: slideCfg.TileWidth, Max: slideCfg.TileWidth}),
 	)
 
-	// 设置资源配置
-	// 注意：这里使用默认的背景图片，实际项目中应该从资源文件加载
-	bgImage, err := loadDefaultBackground()
-	if err != nil {
-		return fmt.Errorf("failed to load background: %w", err)
+	// 设置资源配置：优先使用资源包，缺失时使用内置的默认背景与滑块图形
+	pack := c.config.Resources
+	backgrounds := pack.backgrounds()
+	if len(backgrounds) == 0 {
+		bgImage, err := loadDefaultBackground(slideCfg.MasterWidth, slideCfg.MasterHeight)
+		if err != nil {
+			return fmt.Errorf("failed to load background: %w", err)
+		}
+		backgrounds = []image.Image{bgImage}
+	}
+	tileMasks := pack.tileMasks()
+	if len(tileMasks) == 0 {
+		tileMasks = []*TileMask{defaultTileMask(slideCfg.TileWidth, slideCfg.TileHeight)}
+	}
+	graphs := make([]*slide.GraphImage, len(tileMasks))
+	for i, m := range tileMasks {
+		graphs[i] = &slide.GraphImage{OverlayImage: m.Overlay, ShadowImage: m.Shadow, MaskImage: m.Mask}
 	}
 
 	builder.SetResources(
-		slide.WithBackgrounds([]image.Image{bgImage}),
+		slide.WithBackgrounds(backgrounds),
+		slide.WithGraphImages(graphs),
 	)
 
 	c.slideCaptcha = builder.Make()
@@ -88,12 +117,14 @@ func (c *Captcha) initSlideCaptcha() error {
 }
 
 // loadDefaultBackground 加载默认背景图片（创建一个简单的渐变色背景）
-func loadDefaultBackground() (image.Image, error) {
-	// 创建一个简单的纯色背景
-	// 在实际项目中，应该从文件系统或嵌入资源中加载真实图片
-	bg := image.NewRGBA(image.Rect(0, 0, 300, 220))
-	for y := 0; y < 220; y++ {
-		for x := 0; x < 300; x++ {
+func loadDefaultBackground(width, height int) (image.Image, error) {
+	if width <= 0 || height <= 0 {
+		width, height = 300, 220
+	}
+	// 在实际项目中，应该通过 WithResourcePack 加载真实图片
+	bg := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
 			// 渐变蓝色背景
 			r := uint8(100 + x%50)
 			g := uint8(150 + y%50)
@@ -139,28 +170,86 @@ type RotateCaptchaData struct {
 }
 
 // Generate 生成验证码:返回 id, base64图片, 答案, err
+// 对于语音验证码，b64s 为 data:audio/wav;base64 格式的 WAV 音频
 // 对于滑动验证码，b64s 包含 JSON 格式的 SlideCaptchaData
 // 对于点击验证码，b64s 包含 JSON 格式的 ClickCaptchaData
 // 对于旋转验证码，b64s 包含 JSON 格式的 RotateCaptchaData
 func (c *Captcha) Generate() (id string, b64s string, answer string, err error) {
+	return c.generate(c.config.DriverType)
+}
+
+// GenerateRaw 生成验证码并返回编码后的原始字节，适用于 HTTP 处理器直接输出图片或音频
+func (c *Captcha) GenerateRaw() (*RawCaptcha, error) {
+	return c.render(c.config.DriverType)
+}
+
+// generate 使用指定驱动生成验证码
+func (c *Captcha) generate(driverType DriverType) (id string, b64s string, answer string, err error) {
+	raw, err := c.render(driverType)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	var data any
+	switch driverType {
+	case DriverSlide:
+		data = &SlideCaptchaData{
+			ID:          raw.ID,
+			MasterImage: raw.Master.DataURI(),
+			TileImage:   raw.Tile.DataURI(),
+			XPosition:   raw.XPosition, // 缺口的 X 坐标
+		}
+	case DriverClick:
+		data = &ClickCaptchaData{
+			ID:          raw.ID,
+			MasterImage: raw.Master.DataURI(),
+			ThumbImage:  raw.Thumb.DataURI(),
+			Dots:        raw.Dots,
+		}
+	case DriverRotate:
+		data = &RotateCaptchaData{
+			ID:          raw.ID,
+			MasterImage: raw.Master.DataURI(),
+			ThumbImage:  raw.Thumb.DataURI(),
+			Angle:       raw.Angle,
+		}
+	default:
+		return raw.ID, raw.Master.DataURI(), raw.Answer, nil
+	}
+
+	// 将行为验证码数据序列化为 JSON
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to marshal %s data: %w", driverType, err)
+	}
+	return raw.ID, string(jsonData), raw.Answer, nil
+}
+
+// render 使用指定驱动生成验证码并编码图片
+func (c *Captcha) render(driverType DriverType) (*RawCaptcha, error) {
 	// 如果是滑动验证码，使用特殊处理
-	if c.config.DriverType == DriverSlide {
+	if driverType == DriverSlide {
 		return c.generateSlide()
 	}
 
 	// 如果是点击验证码，使用特殊处理
-	if c.config.DriverType == DriverClick {
+	if driverType == DriverClick {
 		return c.generateClick()
 	}
 
 	// 如果是旋转验证码，使用特殊处理
-	if c.config.DriverType == DriverRotate {
+	if driverType == DriverRotate {
 		return c.generateRotate()
 	}
 
+	// 语音验证码由发音样本合成
+	if driverType == DriverAudio {
+		return c.generateAudio()
+	}
+
 	var driver base64Captcha.Driver
 
-	switch c.config.DriverType {
+	switch driverType {
 	case DriverDigit:
 		digitCfg := c.config.DigitConfig
 		if digitCfg == nil {
@@ -239,66 +328,52 @@ func (c *Captcha) Generate() (id string, b64s string, answer string, err error)
 	// 绘制验证码图片
 	item, err := driver.DrawCaptcha(question)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to draw captcha: %w", err)
+		return nil, fmt.Errorf("failed to draw captcha: %w", err)
 	}
 
-	// 转换为 base64
-	b64s = item.EncodeB64string()
-
-	return id, b64s, answer, nil
+	media, err := c.encodeItem(item)
+	if err != nil {
+		return nil, err
+	}
+	return &RawCaptcha{ID: id, Answer: answer, Master: media}, nil
 }
 
 // generateSlide 生成滑动拼图验证码
-func (c *Captcha) generateSlide() (id string, b64s string, answer string, err error) {
+func (c *Captcha) generateSlide() (*RawCaptcha, error) {
 	// 初始化滑动验证码
 	if err := c.initSlideCaptcha(); err != nil {
-		return "", "", "", fmt.Errorf("failed to init slide captcha: %w", err)
+		return nil, fmt.Errorf("failed to init slide captcha: %w", err)
 	}
 
 	// 生成验证码数据
 	captData, err := c.slideCaptcha.Generate()
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to generate slide captcha: %w", err)
+		return nil, fmt.Errorf("failed to generate slide captcha: %w", err)
 	}
 
 	// 获取验证数据（包含缺口位置）
 	blockData := captData.GetData()
 	if blockData == nil {
-		return "", "", "", fmt.Errorf("slide captcha data is nil")
+		return nil, fmt.Errorf("slide captcha data is nil")
 	}
 
-	// 获取主图和滑块图的 base64
-	masterBase64, err := captData.GetMasterImage().ToBase64()
-	if err != nil {
-		return "", "", "", fmt.Errorf("failed to convert master image to base64: %w", err)
-	}
-	tileBase64, err := captData.GetTileImage().ToBase64()
+	// 编码主图和滑块图
+	master, err := c.encodeImage(captData.GetMasterImage().Get(), ImageFormatJPEG)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to convert tile image to base64: %w", err)
-	}
-
-	// 构造返回数据
-	slideData := &SlideCaptchaData{
-		ID:          fmt.Sprintf("%d_%d", blockData.X, blockData.Y),
-		MasterImage: masterBase64,
-		TileImage:   tileBase64,
-		XPosition:   blockData.X, // 缺口的 X 坐标
+		return nil, fmt.Errorf("failed to encode master image: %w", err)
 	}
-
-	// 将滑动验证码数据序列化为 JSON
-	jsonData, err := json.Marshal(slideData)
+	tile, err := c.encodeImage(captData.GetTileImage().Get(), ImageFormatPNG)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to marshal slide data: %w", err)
+		return nil, fmt.Errorf("failed to encode tile image: %w", err)
 	}
 
-	// ID 用于 Redis 存储
-	id = slideData.ID
-	// b64s 包含完整的 JSON 数据
-	b64s = string(jsonData)
-	// answer 是缺口的 X 坐标（用于验证）
-	answer = fmt.Sprintf("%d", blockData.X)
-
-	return id, b64s, answer, nil
+	return &RawCaptcha{
+		ID:        fmt.Sprintf("%d_%d", blockData.X, blockData.Y),
+		Answer:    fmt.Sprintf("%d", blockData.X), // answer 是缺口的 X 坐标（用于验证）
+		Master:    master,
+		Tile:      tile,
+		XPosition: blockData.X,
+	}, nil
 }
 
 // initClickCaptcha 初始化点击验证码实例（懒加载）
@@ -322,20 +397,39 @@ func (c *Captcha) initClickCaptcha() error {
 		click.WithShadowPoint(option.Point{X: clickCfg.ShadowOffsetX, Y: clickCfg.ShadowOffsetY}),
 	)
 
-	// 设置字符集
-	chars := clickCfg.Chars
-	if chars == "" {
-		chars = "这的是随了机文我你他字在有不么中"
+	// 设置字符集：资源包词典优先
+	pack := c.config.Resources
+	charArr := pack.words()
+	if len(charArr) == 0 {
+		chars := clickCfg.Chars
+		if chars == "" {
+			chars = "这的是随了机文我你他字在有不么中"
+		}
+
+		// 将字符串转换为字符数组
+		charArr = make([]string, 0, len([]rune(chars)))
+		for _, ch := range chars {
+			charArr = append(charArr, string(ch))
+		}
 	}
 
-	// 将字符串转换为字符数组
-	charArr := make([]string, 0, len([]rune(chars)))
-	for _, ch := range chars {
-		charArr = append(charArr, string(ch))
+	backgrounds := pack.backgrounds()
+	if len(backgrounds) == 0 {
+		bgImage, err := loadDefaultBackground(clickCfg.MasterWidth, clickCfg.MasterHeight)
+		if err != nil {
+			return fmt.Errorf("failed to load background: %w", err)
+		}
+		backgrounds = []image.Image{bgImage}
+	}
+	fonts := pack.fonts()
+	if len(fonts) == 0 {
+		fonts = []*truetype.Font{defaultClickFont()}
 	}
 
 	builder.SetResources(
 		click.WithChars(charArr),
+		click.WithBackgrounds(backgrounds),
+		click.WithFonts(fonts),
 	)
 
 	c.clickCaptcha = builder.Make()
@@ -343,32 +437,32 @@ func (c *Captcha) initClickCaptcha() error {
 }
 
 // generateClick 生成点击文字验证码
-func (c *Captcha) generateClick() (id string, b64s string, answer string, err error) {
+func (c *Captcha) generateClick() (*RawCaptcha, error) {
 	// 初始化点击验证码
 	if err := c.initClickCaptcha(); err != nil {
-		return "", "", "", fmt.Errorf("failed to init click captcha: %w", err)
+		return nil, fmt.Errorf("failed to init click captcha: %w", err)
 	}
 
 	// 生成验证码数据
 	captData, err := c.clickCaptcha.Generate()
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to generate click captcha: %w", err)
+		return nil, fmt.Errorf("failed to generate click captcha: %w", err)
 	}
 
 	// 获取验证数据（包含点击点位置）
 	dotData := captData.GetData()
 	if dotData == nil {
-		return "", "", "", fmt.Errorf("click captcha data is nil")
+		return nil, fmt.Errorf("click captcha data is nil")
 	}
 
-	// 获取主图和缩略图的 base64
-	masterBase64, err := captData.GetMasterImage().ToBase64()
+	// 编码主图和缩略图
+	master, err := c.encodeImage(captData.GetMasterImage().Get(), ImageFormatJPEG)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to get master image: %w", err)
+		return nil, fmt.Errorf("failed to encode master image: %w", err)
 	}
-	thumbBase64, err := captData.GetThumbImage().ToBase64()
+	thumb, err := c.encodeImage(captData.GetThumbImage().Get(), ImageFormatPNG)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to get thumb image: %w", err)
+		return nil, fmt.Errorf("failed to encode thumb image: %w", err)
 	}
 
 	// 构造返回数据
@@ -384,28 +478,16 @@ func (c *Captcha) generateClick() (id string, b64s string, answer string, err er
 		}
 	}
 
-	clickData := &ClickCaptchaData{
-		ID:          fmt.Sprintf("click_%d", time.Now().UnixNano()),
-		MasterImage: masterBase64,
-		ThumbImage:  thumbBase64,
-		Dots:        dots,
-	}
-
-	// 将点击验证码数据序列化为 JSON
-	jsonData, err := json.Marshal(clickData)
-	if err != nil {
-		return "", "", "", fmt.Errorf("failed to marshal click data: %w", err)
-	}
-
-	// ID 用于 Redis 存储
-	id = clickData.ID
-	// b64s 包含完整的 JSON 数据
-	b64s = string(jsonData)
 	// answer 是点击点的坐标信息（JSON 格式）
 	answerBytes, _ := json.Marshal(dotData)
-	answer = string(answerBytes)
 
-	return id, b64s, answer, nil
+	return &RawCaptcha{
+		ID:     fmt.Sprintf("click_%d", time.Now().UnixNano()),
+		Answer: string(answerBytes),
+		Master: master,
+		Thumb:  thumb,
+		Dots:   dots,
+	}, nil
 }
 
 // initRotateCaptcha 初始化旋转验证码实例（懒加载）
@@ -414,107 +496,200 @@ func (c *Captcha) initRotateCaptcha() error {
 		return nil
 	}
 
-	// 创建 builder - 使用默认配置，不设置自定义资源
-	// rotate 模块会使用内置的默认图片资源
-	builder := rotate.NewBuilder()
+	rotateCfg := c.config.RotateConfig
+	if rotateCfg == nil {
+		rotateCfg = DefaultRotateConfig()
+	}
+
+	// 创建 builder
+	builder := rotate.NewBuilder(
+		rotate.WithImageSquareSize(rotateCfg.MasterWidth),
+		rotate.WithRangeThumbImageSquareSize([]int{rotateCfg.ThumbWidth}),
+	)
+
+	// 设置图片资源：资源包优先，缺失时使用内置的默认图片
+	images := c.config.Resources.rotateImages()
+	if len(images) == 0 {
+		images = []image.Image{defaultRotateImage(rotateCfg.MasterWidth)}
+	}
+	builder.SetResources(
+		rotate.WithImages(images),
+	)
 
 	c.rotateCaptcha = builder.Make()
 	return nil
 }
 
 // generateRotate 生成旋转验证码
-func (c *Captcha) generateRotate() (id string, b64s string, answer string, err error) {
+func (c *Captcha) generateRotate() (*RawCaptcha, error) {
 	// 初始化旋转验证码
 	if err := c.initRotateCaptcha(); err != nil {
-		return "", "", "", fmt.Errorf("failed to init rotate captcha: %w", err)
+		return nil, fmt.Errorf("failed to init rotate captcha: %w", err)
 	}
 
 	// 生成验证码数据
 	captData, err := c.rotateCaptcha.Generate()
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to generate rotate captcha: %w", err)
+		return nil, fmt.Errorf("failed to generate rotate captcha: %w", err)
 	}
 
 	// 获取验证数据（包含正确角度）
 	angleData := captData.GetData()
 	if angleData == nil {
-		return "", "", "", fmt.Errorf("rotate captcha data is nil")
+		return nil, fmt.Errorf("rotate captcha data is nil")
 	}
 
-	// 获取主图和缩略图的 base64
-	masterBase64, err := captData.GetMasterImage().ToBase64()
+	// 编码主图和缩略图
+	master, err := c.encodeImage(captData.GetMasterImage().Get(), ImageFormatPNG)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to convert master image to base64: %w", err)
+		return nil, fmt.Errorf("failed to encode master image: %w", err)
 	}
-	thumbBase64, err := captData.GetThumbImage().ToBase64()
+	thumb, err := c.encodeImage(captData.GetThumbImage().Get(), ImageFormatPNG)
 	if err != nil {
-		return "", "", "", fmt.Errorf("failed to convert thumb image to base64: %w", err)
-	}
-
-	// 构造返回数据
-	rotateData := &RotateCaptchaData{
-		ID:          fmt.Sprintf("rotate_%d", time.Now().UnixNano()),
-		MasterImage: masterBase64,
-		ThumbImage:  thumbBase64,
-		Angle:       angleData.Angle,
+		return nil, fmt.Errorf("failed to encode thumb image: %w", err)
 	}
 
-	// 将旋转验证码数据序列化为 JSON
-	jsonData, err := json.Marshal(rotateData)
-	if err != nil {
-		return "", "", "", fmt.Errorf("failed to marshal rotate data: %w", err)
-	}
+	return &RawCaptcha{
+		ID:     fmt.Sprintf("rotate_%d", time.Now().UnixNano()),
+		Answer: fmt.Sprintf("%d", angleData.Angle), // answer 是正确的角度值
+		Master: master,
+		Thumb:  thumb,
+		Angle:  angleData.Angle,
+	}, nil
+}
 
-	// ID 用于 Redis 存储
-	id = rotateData.ID
-	// b64s 包含完整的 JSON 数据
-	b64s = string(jsonData)
-	// answer 是正确的角度值
-	answer = fmt.Sprintf("%d", angleData.Angle)
+// Save 将验证码答案存入存储
+func (c *Captcha) Save(ctx context.Context, captchaID, answer string) error {
+	return c.store.Set(ctx, c.key(captchaID), answer, c.config.Expire)
+}
 
-	return id, b64s, answer, nil
+// Issue 保存答案并返回客户端验证时需回传的凭证。
+// 有状态存储（Redis、内存）返回 captchaID 本身；无状态存储返回封装了答案的加密凭证。
+func (c *Captcha) Issue(ctx context.Context, captchaID, answer string) (string, error) {
+	if sealer, ok := c.store.(Sealer); ok {
+		token, err := sealer.Seal(ctx, answer, c.config.Expire)
+		if err != nil {
+			return "", err
+		}
+		// 升级驱动的标记随凭证迁移，验证时以凭证查找
+		if d := c.driverOf(ctx, captchaID); d != c.config.DriverType {
+			if err := c.stateStore().Set(ctx, c.stateKey("driver", token), string(d), c.config.Expire); err != nil {
+				return "", err
+			}
+		}
+		return token, nil
+	}
+	if err := c.Save(ctx, captchaID, answer); err != nil {
+		return "", err
+	}
+	return captchaID, nil
 }
 
-// Save 将验证码答案存入 Redis
-func (c *Captcha) Save(ctx context.Context, captchaID, answer string) error {
-	key := fmt.Sprintf("%s:%s", c.config.KeyPrefix, captchaID)
-	return c.rdb.Set(ctx, key, answer, c.config.Expire).Err()
+// VerifyResult 验证结果
+type VerifyResult struct {
+	OK      bool     `json:"ok"`      // 是否通过：答案匹配且轨迹风险分未超限
+	Match   bool     `json:"match"`   // 答案是否匹配
+	Risk    float64  `json:"risk"`    // 轨迹风险分 0~1，未校验轨迹时为 0
+	Reasons []string `json:"reasons"` // 命中的风险项
 }
 
-// Verify 从Redis读取并校验验证码
+// Verify 从存储读取并校验验证码
 // 验证成功自动删除
 func (c *Captcha) Verify(ctx context.Context, captchaID, userInput string) (bool, error) {
-	key := fmt.Sprintf("%s:%s", c.config.KeyPrefix, captchaID)
+	res, err := c.VerifyWithRisk(ctx, captchaID, userInput)
+	return res.OK, err
+}
+
+// VerifyWithRisk 与 Verify 相同，同时返回轨迹风险分与命中的风险项。
+// 滑动、旋转验证码配置了 TrackPolicy 时，userInput 应为 TrackInput 的 JSON。
+func (c *Captcha) VerifyWithRisk(ctx context.Context, captchaID, userInput string) (VerifyResult, error) {
+	key := c.key(captchaID)
+
+	// 先计入尝试次数再读取答案，超过上限的请求不会接触答案
+	attempt, allowed, err := c.beginAttempt(ctx, captchaID)
+	if err != nil || !allowed {
+		return VerifyResult{}, err
+	}
 
 	// 获取答案
-	ans, err := c.rdb.Get(ctx, key).Result()
+	ans, err := c.store.Get(ctx, key)
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			return false, nil // 过期/不存在
+		if errors.Is(err, ErrAnswerNotFound) {
+			return VerifyResult{}, nil // 过期/不存在
 		}
-		return false, err
+		return VerifyResult{}, err
 	}
 
-	// 校验成功就删除
-	var match bool
-	if c.config.DriverType == DriverSlide {
-		// 滑动验证码需要特殊处理，允许一定误差
-		match = c.verifySlide(ans, userInput)
-	} else if c.config.DriverType == DriverClick {
-		// 点击验证码需要特殊处理，比较坐标
-		match = c.verifyClick(ans, userInput)
-	} else if c.config.DriverType == DriverRotate {
-		// 旋转验证码需要特殊处理，比较角度
-		match = c.verifyRotate(ans, userInput)
-	} else {
-		match = ans == userInput
+	// 校验成功就删除，失败且用完尝试次数时作废
+	res := c.evaluate(c.driverOf(ctx, captchaID), ans, userInput)
+	if res.OK {
+		if err := c.consume(ctx, key); err != nil {
+			if errors.Is(err, ErrAnswerNotFound) {
+				return VerifyResult{}, nil // 并发验证中已被使用
+			}
+			return VerifyResult{}, err
+		}
+		c.clearState(ctx, captchaID)
+	} else if err := c.recordFailure(ctx, captchaID, attempt); err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// consume 验证成功后作废答案；存储实现 Consumer 时原子消费，保证同一答案只能通过一次
+func (c *Captcha) consume(ctx context.Context, key string) error {
+	if consumer, ok := c.store.(Consumer); ok {
+		return consumer.Consume(ctx, key)
+	}
+	_ = c.store.Delete(ctx, key)
+	return nil
+}
+
+// key 返回答案在存储中的 key；无状态存储的凭证本身即为 key
+func (c *Captcha) key(captchaID string) string {
+	if _, ok := c.store.(Sealer); ok {
+		return captchaID
 	}
+	return fmt.Sprintf("%s:%s", c.config.KeyPrefix, captchaID)
+}
 
-	if match {
-		_ = c.rdb.Del(ctx, key).Err()
+// evaluate 按驱动类型比较答案与用户输入，滑动、旋转验证码按策略校验轨迹
+func (c *Captcha) evaluate(driverType DriverType, expected, actual string) VerifyResult {
+	var res VerifyResult
+
+	switch driverType {
+	case DriverSlide, DriverRotate:
+		in, ok := parseTrackInput(actual)
+		if !ok {
+			return res
+		}
+		value := strconv.Itoa(int(math.Round(in.Value)))
+		if driverType == DriverSlide {
+			// 滑动验证码需要特殊处理，允许一定误差
+			res.Match = c.verifySlide(expected, value)
+		} else {
+			// 旋转验证码需要特殊处理，比较角度
+			res.Match = c.verifyRotate(expected, value)
+		}
+		if policy := c.config.TrackPolicies[driverType]; policy != nil && res.Match {
+			analysis := AnalyzeTrack(in.Track, in.Value, policy)
+			res.Risk, res.Reasons = analysis.Risk, analysis.Reasons
+			res.OK = analysis.Risk <= policy.MaxRisk
+			return res
+		}
+	case DriverClick:
+		// 点击验证码需要特殊处理，比较坐标
+		res.Match = c.verifyClick(expected, actual)
+	case DriverAudio:
+		// 听写的字母不区分大小写
+		res.Match = strings.EqualFold(expected, strings.TrimSpace(actual))
+	default:
+		res.Match = expected == actual
 	}
 
-	return match, nil
+	res.OK = res.Match
+	return res
 }
 
 // verifySlide 验证滑动验证码（允许误差范围）
@@ -627,46 +802,52 @@ func (c *Captcha) VerifyAndDelete(ctx context.Context, captchaID, userInput stri
 
 // VerifyWithoutDelete 验证但不删除验证码（用于多次验证场景）
 func (c *Captcha) VerifyWithoutDelete(ctx context.Context, captchaID, userInput string) (bool, error) {
-	key := fmt.Sprintf("%s:%s", c.config.KeyPrefix, captchaID)
+	// 每次验证（包括成功）都计入尝试次数，防止无限次重试
+	attempt, allowed, err := c.beginAttempt(ctx, captchaID)
+	if err != nil || !allowed {
+		return false, err
+	}
 
 	// 获取答案
-	ans, err := c.rdb.Get(ctx, key).Result()
+	ans, err := c.store.Get(ctx, c.key(captchaID))
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
+		if errors.Is(err, ErrAnswerNotFound) {
 			return false, nil // 过期/不存在
 		}
 		return false, err
 	}
 
 	// 只校验，不删除
-	if c.config.DriverType == DriverSlide {
-		return c.verifySlide(ans, userInput), nil
-	} else if c.config.DriverType == DriverClick {
-		return c.verifyClick(ans, userInput), nil
-	} else if c.config.DriverType == DriverRotate {
-		return c.verifyRotate(ans, userInput), nil
+	if c.evaluate(c.driverOf(ctx, captchaID), ans, userInput).OK {
+		return true, nil
 	}
-	return ans == userInput, nil
+	return false, c.recordFailure(ctx, captchaID, attempt)
 }
 
 // Delete 手动删除验证码
 func (c *Captcha) Delete(ctx context.Context, captchaID string) error {
-	key := fmt.Sprintf("%s:%s", c.config.KeyPrefix, captchaID)
-	return c.rdb.Del(ctx, key).Err()
+	c.clearState(ctx, captchaID)
+	return c.store.Delete(ctx, c.key(captchaID))
 }
 
 // Exists 检查验证码是否存在
 func (c *Captcha) Exists(ctx context.Context, captchaID string) (bool, error) {
-	key := fmt.Sprintf("%s:%s", c.config.KeyPrefix, captchaID)
-	result, err := c.rdb.Exists(ctx, key).Result()
+	_, err := c.store.Get(ctx, c.key(captchaID))
 	if err != nil {
+		if errors.Is(err, ErrAnswerNotFound) {
+			return false, nil
+		}
 		return false, err
 	}
-	return result > 0, nil
+	return true, nil
 }
 
 // GetRemainingTime 获取验证码剩余时间
 func (c *Captcha) GetRemainingTime(ctx context.Context, captchaID string) (time.Duration, error) {
-	key := fmt.Sprintf("%s:%s", c.config.KeyPrefix, captchaID)
-	return c.rdb.TTL(ctx, key).Result()
+	return c.store.TTL(ctx, c.key(captchaID))
+}
+
+// GetStore 获取答案存储
+func (c *Captcha) GetStore() Store {
+	return c.store
 }
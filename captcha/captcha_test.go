@@ -2,8 +2,10 @@ package captcha
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -509,6 +511,39 @@ func TestVerify_RotateCaptcha_WithTolerance(t *testing.T) {
 	assert.True(t, valid, "应该接受在误差范围内的值")
 }
 
+func TestGenerate_AudioCaptcha(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	for _, lang := range []string{"zh", "en"} {
+		t.Run(lang, func(t *testing.T) {
+			captchaInstance := NewCaptchaWithStore(store,
+				WithDriverType(DriverAudio),
+				WithAudioCount(4),
+				WithAudioLanguage(lang),
+			)
+
+			id, b64Audio, answer, err := captchaInstance.Generate()
+			require.NoError(t, err)
+			assert.NotEmpty(t, id)
+			assert.Regexp(t, `^[0-9]{4}$`, answer)
+
+			// 返回 base64 编码的 WAV 音频
+			require.True(t, strings.HasPrefix(b64Audio, "data:audio/wav;base64,"))
+			wav, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(b64Audio, "data:audio/wav;base64,"))
+			require.NoError(t, err)
+			assert.Equal(t, "RIFF", string(wav[:4]))
+			assert.Equal(t, "WAVE", string(wav[8:12]))
+
+			ctx := context.Background()
+			require.NoError(t, captchaInstance.Save(ctx, id, answer))
+			ok, err := captchaInstance.Verify(ctx, id, answer)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		})
+	}
+}
+
 func TestVerify_WrongAnswer(t *testing.T) {
 	rdb := setupTestRedis(t)
 	defer teardownTestRedis(rdb)
@@ -950,6 +985,13 @@ func TestDefaultConfigs(t *testing.T) {
 		assert.NotNil(t, cfg.SlideConfig)
 		assert.NotNil(t, cfg.ClickConfig)
 		assert.NotNil(t, cfg.RotateConfig)
+		assert.NotNil(t, cfg.AudioConfig)
+	})
+
+	t.Run("DefaultAudioConfig", func(t *testing.T) {
+		cfg := DefaultAudioConfig()
+		assert.Equal(t, 6, cfg.CaptchaCount)
+		assert.Equal(t, "zh", cfg.Language)
 	})
 }
 
@@ -9,8 +9,8 @@ import (
 	"github.com/tx7do/go-utils/captcha"
 )
 
-// ExampleNewCaptcha_OptionsPattern 使用 Options 模式创建验证码（推荐）
-func ExampleNewCaptcha_OptionsPattern() {
+// ExampleNewCaptcha_optionsPattern 使用 Options 模式创建验证码（推荐）
+func ExampleNewCaptcha_optionsPattern() {
 	rdb := redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
 	})
@@ -26,11 +26,10 @@ func ExampleNewCaptcha_OptionsPattern() {
 
 	id, _, answer, _ := captchaInstance.Generate()
 	fmt.Printf("字符串验证码ID: %s, 长度: %d\n", id, len(answer))
-	// Output:
 }
 
-// ExampleNewCaptcha_DigitCaptcha 数字验证码示例
-func ExampleNewCaptcha_DigitCaptcha() {
+// ExampleNewCaptcha_digitCaptcha 数字验证码示例
+func ExampleNewCaptcha_digitCaptcha() {
 	rdb := redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
 	})
@@ -44,11 +43,10 @@ func ExampleNewCaptcha_DigitCaptcha() {
 
 	id, _, answer, _ := captchaInstance.Generate()
 	fmt.Printf("数字验证码ID: %s, 答案: %s\n", id, answer)
-	// Output:
 }
 
-// ExampleNewCaptcha_MathCaptcha 算术验证码示例
-func ExampleNewCaptcha_MathCaptcha() {
+// ExampleNewCaptcha_mathCaptcha 算术验证码示例
+func ExampleNewCaptcha_mathCaptcha() {
 	rdb := redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
 	})
@@ -61,11 +59,10 @@ func ExampleNewCaptcha_MathCaptcha() {
 	fmt.Printf("算术验证码ID: %s\n", id)
 	fmt.Printf("问题: %s\n", question)
 	fmt.Printf("答案: %s\n", answer)
-	// Output:
 }
 
-// ExampleNewCaptcha_ChineseCaptcha 中文验证码示例
-func ExampleNewCaptcha_ChineseCaptcha() {
+// ExampleNewCaptcha_chineseCaptcha 中文验证码示例
+func ExampleNewCaptcha_chineseCaptcha() {
 	rdb := redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
 	})
@@ -78,16 +75,15 @@ func ExampleNewCaptcha_ChineseCaptcha() {
 
 	id, _, answer, _ := captchaInstance.Generate()
 	fmt.Printf("中文验证码ID: %s, 字符数: %d\n", id, len([]rune(answer)))
-	// Output:
 }
 
-// ExampleVerifyWithoutDelete 验证但不删除示例
-func ExampleVerifyWithoutDelete() {
-	rdb := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-	})
+// ExampleCaptcha_VerifyWithoutDelete 验证但不删除示例
+func ExampleCaptcha_VerifyWithoutDelete() {
+	// 内存存储无需 Redis，适用于测试与单实例服务
+	store := captcha.NewMemoryStore()
+	defer store.Close()
 
-	captchaInstance := captcha.NewCaptcha(rdb,
+	captchaInstance := captcha.NewCaptchaWithStore(store,
 		captcha.WithExpire(5*time.Minute),
 		captcha.WithKeyPrefix("myapp:captcha"),
 	)
@@ -109,13 +105,12 @@ func ExampleVerifyWithoutDelete() {
 	// 第二次验证: true
 }
 
-// ExampleGetRemainingTime 获取剩余时间示例
-func ExampleGetRemainingTime() {
-	rdb := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-	})
+// ExampleCaptcha_GetRemainingTime 获取剩余时间示例
+func ExampleCaptcha_GetRemainingTime() {
+	store := captcha.NewMemoryStore()
+	defer store.Close()
 
-	captchaInstance := captcha.NewCaptcha(rdb,
+	captchaInstance := captcha.NewCaptchaWithStore(store,
 		captcha.WithExpire(5*time.Minute),
 		captcha.WithKeyPrefix("myapp:captcha"),
 	)
@@ -130,6 +125,36 @@ func ExampleGetRemainingTime() {
 		panic(err)
 	}
 
-	fmt.Printf("验证码剩余时间: %v\n", ttl)
+	fmt.Printf("验证码剩余时间有效: %v\n", ttl > 0 && ttl <= 5*time.Minute)
+	// Output:
+	// 验证码剩余时间有效: true
+}
+
+// ExampleCaptcha_Issue 无状态凭证示例：答案加密后交给客户端保存，服务端无需存储
+func ExampleCaptcha_Issue() {
+	store, err := captcha.NewStatelessStore([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		panic(err)
+	}
+
+	captchaInstance := captcha.NewCaptchaWithStore(store,
+		captcha.WithDriverType(captcha.DriverDigit),
+		captcha.WithExpire(2*time.Minute),
+	)
+	id, _, answer, _ := captchaInstance.Generate()
+
+	ctx := context.Background()
+	// 凭证随图片下发（或通过 store.Cookie 写入 Cookie），验证时代替 ID 回传
+	token, _ := captchaInstance.Issue(ctx, id, answer)
+
+	ok1, _ := captchaInstance.Verify(ctx, token, answer)
+	fmt.Printf("第一次验证: %v\n", ok1)
+
+	// 验证成功后凭证被记入重放缓存
+	ok2, _ := captchaInstance.Verify(ctx, token, answer)
+	fmt.Printf("重放验证: %v\n", ok2)
+
 	// Output:
+	// 第一次验证: true
+	// 重放验证: false
 }
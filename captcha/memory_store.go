@@ -0,0 +1,166 @@
+package captcha
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultCleanupInterval = time.Minute // 默认过期清理间隔
+
+type MemoryStoreOption func(*MemoryStore)
+
+// WithCleanupInterval 设置后台清理过期答案的间隔，<=0 表示不启动清理协程（过期答案仅在读取时剔除）
+func WithCleanupInterval(interval time.Duration) MemoryStoreOption {
+	return func(s *MemoryStore) { s.interval = interval }
+}
+
+// memoryItem 内存存储条目
+type memoryItem struct {
+	answer   string
+	expireAt time.Time // 零值表示不过期
+}
+
+func (it memoryItem) expired(now time.Time) bool {
+	return !it.expireAt.IsZero() && !now.Before(it.expireAt)
+}
+
+// MemoryStore 进程内答案存储，适用于测试与单实例服务。
+// 后台清理协程定期剔除过期答案，不再使用时应调用 Close。
+type MemoryStore struct {
+	interval time.Duration
+
+	mu    sync.Mutex
+	items map[string]memoryItem
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMemoryStore 创建内存存储并启动过期清理协程
+func NewMemoryStore(opts ...MemoryStoreOption) *MemoryStore {
+	s := &MemoryStore{
+		interval: defaultCleanupInterval,
+		items:    make(map[string]memoryItem),
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.interval > 0 {
+		go s.janitor()
+	}
+	return s
+}
+
+func (s *MemoryStore) Set(_ context.Context, key, answer string, ttl time.Duration) error {
+	it := memoryItem{answer: answer}
+	if ttl > 0 {
+		it.expireAt = time.Now().Add(ttl)
+	}
+
+	s.mu.Lock()
+	s.items[key] = it
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (string, error) {
+	it, ok := s.lookup(key)
+	if !ok {
+		return "", ErrAnswerNotFound
+	}
+	return it.answer, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) TTL(_ context.Context, key string) (time.Duration, error) {
+	it, ok := s.lookup(key)
+	if !ok {
+		return -2, nil
+	}
+	if it.expireAt.IsZero() {
+		return -1, nil
+	}
+	return time.Until(it.expireAt), nil
+}
+
+func (s *MemoryStore) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	if !ok || it.expired(time.Now()) {
+		it = memoryItem{}
+		if ttl > 0 {
+			it.expireAt = time.Now().Add(ttl)
+		}
+	}
+	n, _ := strconv.ParseInt(it.answer, 10, 64)
+	n++
+	it.answer = strconv.FormatInt(n, 10)
+	s.items[key] = it
+	return n, nil
+}
+
+// Len 返回当前保存的答案数量（包含尚未清理的过期答案）
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// Close 停止后台清理协程
+func (s *MemoryStore) Close() error {
+	s.closeOnce.Do(func() { close(s.stop) })
+	return nil
+}
+
+// lookup 查找未过期的条目，顺带剔除已过期的条目
+func (s *MemoryStore) lookup(key string) (memoryItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	if !ok {
+		return memoryItem{}, false
+	}
+	if it.expired(time.Now()) {
+		delete(s.items, key)
+		return memoryItem{}, false
+	}
+	return it, true
+}
+
+// janitor 定期清理过期答案
+func (s *MemoryStore) janitor() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.deleteExpired(now)
+		}
+	}
+}
+
+func (s *MemoryStore) deleteExpired(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, it := range s.items {
+		if it.expired(now) {
+			delete(s.items, key)
+		}
+	}
+}
@@ -15,6 +15,7 @@ const (
 	DriverSlide   DriverType = "slide"   // 滑动拼图验证码
 	DriverClick   DriverType = "click"   // 点击文字验证码
 	DriverRotate  DriverType = "rotate"  // 旋转验证码
+	DriverAudio   DriverType = "audio"   // 语音验证码
 )
 
 // DigitConfig 数字验证码配置
@@ -120,6 +121,32 @@ type RotateConfig struct {
 	ThumbHeight  int `json:"thumb_height"`  // 缩略图高度
 }
 
+// ProtectionConfig 防暴力破解配置，需要存储实现 Counter
+type ProtectionConfig struct {
+	MaxAttempts    int           `json:"max_attempts"`    // 单个验证码允许的最大验证次数，用完后作废；0 表示不限制
+	GenerateLimit  int           `json:"generate_limit"`  // 单个客户端在 GenerateWindow 内的最大生成次数；0 表示不限制
+	GenerateWindow time.Duration `json:"generate_window"` // 生成限流窗口
+	FailureWindow  time.Duration `json:"failure_window"`  // 客户端失败计数窗口
+	EscalateAfter  int           `json:"escalate_after"`  // 客户端累计失败达到该次数后升级驱动；0 表示不升级
+	EscalateDriver DriverType    `json:"escalate_driver"` // 升级后使用的驱动
+	LockoutAfter   int           `json:"lockout_after"`   // 客户端累计失败达到该次数后锁定；0 表示不锁定
+	Lockout        time.Duration `json:"lockout"`         // 首次锁定时长，此后每次锁定翻倍
+	MaxLockout     time.Duration `json:"max_lockout"`     // 锁定时长上限，0 表示不设上限
+}
+
+// AudioConfig 语音验证码配置。
+// 内置 zh、en（另有 ja、ru、de）的数字发音样本；字符集包含字母时，需通过 ResourcePack.Audio 提供对应语言的字母样本。
+type AudioConfig struct {
+	CaptchaCount int           `json:"captcha_count"` // 播报的字符数量
+	Language     string        `json:"language"`      // 发音语言，没有该语言的样本时使用 en
+	Source       string        `json:"source"`        // 字符集，字母不区分大小写，默认 0-9
+	NoiseLevel   float64       `json:"noise_level"`   // 背景噪声强度 0~1，0 使用默认值，负数表示不加噪声
+	MinSpeed     float64       `json:"min_speed"`     // 随机语速倍率下限，1 为原速，0 使用默认值
+	MaxSpeed     float64       `json:"max_speed"`     // 随机语速倍率上限，0 使用默认值
+	MinGap       time.Duration `json:"min_gap"`       // 字符间随机静音下限
+	MaxGap       time.Duration `json:"max_gap"`       // 字符间随机静音上限，两者均为 0 时使用默认值
+}
+
 // Config 验证码总配置
 type Config struct {
 	DriverType    DriverType     `json:"driver_type"`    // 驱动类型
@@ -132,6 +159,13 @@ type Config struct {
 	SlideConfig   *SlideConfig   `json:"slide_config"`   // 滑动拼图配置
 	ClickConfig   *ClickConfig   `json:"click_config"`   // 点击文字配置
 	RotateConfig  *RotateConfig  `json:"rotate_config"`  // 旋转配置
+	AudioConfig   *AudioConfig   `json:"audio_config"`   // 语音配置
+
+	Protection    *ProtectionConfig           `json:"protection"`     // 防暴力破解配置，nil 表示不启用
+	TrackPolicies map[DriverType]*TrackPolicy `json:"track_policies"` // 按驱动类型的轨迹校验阈值，未配置的驱动不校验轨迹
+
+	Resources *ResourcePack `json:"-"`      // 行为验证码资源包，nil 表示使用内置资源
+	Output    *OutputConfig `json:"output"` // 图片输出格式配置
 }
 
 // DefaultDigitConfig 默认数字配置
@@ -251,6 +285,30 @@ func DefaultRotateConfig() *RotateConfig {
 	}
 }
 
+// DefaultAudioConfig 默认语音验证码配置
+func DefaultAudioConfig() *AudioConfig {
+	return &AudioConfig{
+		CaptchaCount: 6,
+		Language:     "zh",
+		Source:       "0123456789",
+		NoiseLevel:   0.2,
+		MinSpeed:     0.9,
+		MaxSpeed:     1.2,
+		MinGap:       300 * time.Millisecond,
+		MaxGap:       700 * time.Millisecond,
+	}
+}
+
+// DefaultProtectionConfig 默认防暴力破解配置：单个验证码最多失败 5 次，每分钟最多生成 20 次
+func DefaultProtectionConfig() *ProtectionConfig {
+	return &ProtectionConfig{
+		MaxAttempts:    5,
+		GenerateLimit:  20,
+		GenerateWindow: time.Minute,
+		FailureWindow:  15 * time.Minute,
+	}
+}
+
 // DefaultConfig 默认总配置
 func DefaultConfig() *Config {
 	return &Config{
@@ -264,6 +322,7 @@ func DefaultConfig() *Config {
 		SlideConfig:   DefaultSlideConfig(),
 		ClickConfig:   DefaultClickConfig(),
 		RotateConfig:  DefaultRotateConfig(),
+		AudioConfig:   DefaultAudioConfig(),
 	}
 }
 
@@ -670,3 +729,158 @@ func WithRotateThumbSize(width, height int) Option {
 		c.RotateConfig.ThumbHeight = height
 	}
 }
+
+// WithAudioConfig 设置语音验证码配置
+func WithAudioConfig(config *AudioConfig) Option {
+	return func(c *Config) {
+		c.AudioConfig = config
+	}
+}
+
+// WithAudioCount 设置语音验证码播报的字符数量
+func WithAudioCount(count int) Option {
+	return func(c *Config) {
+		if c.AudioConfig == nil {
+			c.AudioConfig = DefaultAudioConfig()
+		}
+		c.AudioConfig.CaptchaCount = count
+	}
+}
+
+// WithAudioLanguage 设置语音验证码语言
+func WithAudioLanguage(language string) Option {
+	return func(c *Config) {
+		if c.AudioConfig == nil {
+			c.AudioConfig = DefaultAudioConfig()
+		}
+		c.AudioConfig.Language = language
+	}
+}
+
+// WithAudioSource 设置语音验证码字符集，包含字母时需提供对应语言的字母样本
+func WithAudioSource(source string) Option {
+	return func(c *Config) {
+		if c.AudioConfig == nil {
+			c.AudioConfig = DefaultAudioConfig()
+		}
+		c.AudioConfig.Source = source
+	}
+}
+
+// WithAudioNoise 设置语音验证码背景噪声强度 0~1，负数表示不加噪声
+func WithAudioNoise(level float64) Option {
+	return func(c *Config) {
+		if c.AudioConfig == nil {
+			c.AudioConfig = DefaultAudioConfig()
+		}
+		c.AudioConfig.NoiseLevel = level
+	}
+}
+
+// WithAudioSpeed 设置语音验证码随机语速倍率范围
+func WithAudioSpeed(minSpeed, maxSpeed float64) Option {
+	return func(c *Config) {
+		if c.AudioConfig == nil {
+			c.AudioConfig = DefaultAudioConfig()
+		}
+		c.AudioConfig.MinSpeed, c.AudioConfig.MaxSpeed = minSpeed, maxSpeed
+	}
+}
+
+// WithAudioGap 设置语音验证码字符间随机静音范围
+func WithAudioGap(minGap, maxGap time.Duration) Option {
+	return func(c *Config) {
+		if c.AudioConfig == nil {
+			c.AudioConfig = DefaultAudioConfig()
+		}
+		c.AudioConfig.MinGap, c.AudioConfig.MaxGap = minGap, maxGap
+	}
+}
+
+// WithProtection 设置防暴力破解配置
+func WithProtection(config *ProtectionConfig) Option {
+	return func(c *Config) {
+		c.Protection = config
+	}
+}
+
+// WithMaxAttempts 设置单个验证码允许的最大验证次数，读取答案前先计数，并发提交也无法超出
+func WithMaxAttempts(n int) Option {
+	return func(c *Config) {
+		if c.Protection == nil {
+			c.Protection = DefaultProtectionConfig()
+		}
+		c.Protection.MaxAttempts = n
+	}
+}
+
+// WithGenerateRateLimit 设置单个客户端的生成限流
+func WithGenerateRateLimit(limit int, window time.Duration) Option {
+	return func(c *Config) {
+		if c.Protection == nil {
+			c.Protection = DefaultProtectionConfig()
+		}
+		c.Protection.GenerateLimit = limit
+		c.Protection.GenerateWindow = window
+	}
+}
+
+// WithFailureWindow 设置客户端失败计数窗口
+func WithFailureWindow(window time.Duration) Option {
+	return func(c *Config) {
+		if c.Protection == nil {
+			c.Protection = DefaultProtectionConfig()
+		}
+		c.Protection.FailureWindow = window
+	}
+}
+
+// WithEscalation 设置客户端累计失败 after 次后改用更难的驱动
+func WithEscalation(after int, driverType DriverType) Option {
+	return func(c *Config) {
+		if c.Protection == nil {
+			c.Protection = DefaultProtectionConfig()
+		}
+		c.Protection.EscalateAfter = after
+		c.Protection.EscalateDriver = driverType
+	}
+}
+
+// WithLockout 设置客户端累计失败 after 次后锁定，锁定时长从 base 起逐次翻倍，不超过 maxLockout
+func WithLockout(after int, base, maxLockout time.Duration) Option {
+	return func(c *Config) {
+		if c.Protection == nil {
+			c.Protection = DefaultProtectionConfig()
+		}
+		c.Protection.LockoutAfter = after
+		c.Protection.Lockout = base
+		c.Protection.MaxLockout = maxLockout
+	}
+}
+
+// WithTrackPolicy 为驱动类型（滑动、旋转）启用轨迹校验，policy 为 nil 时使用 DefaultTrackPolicy
+func WithTrackPolicy(driverType DriverType, policy *TrackPolicy) Option {
+	return func(c *Config) {
+		if policy == nil {
+			policy = DefaultTrackPolicy(driverType)
+		}
+		if c.TrackPolicies == nil {
+			c.TrackPolicies = make(map[DriverType]*TrackPolicy)
+		}
+		c.TrackPolicies[driverType] = policy
+	}
+}
+
+// WithResourcePack 设置行为验证码（滑动、点击、旋转）使用的资源包，每次生成时随机选取
+func WithResourcePack(pack *ResourcePack) Option {
+	return func(c *Config) {
+		c.Resources = pack
+	}
+}
+
+// WithImageFormat 设置图片输出格式与质量，quality 对 JPEG、WebP 生效（1-100，0 表示默认）；WebP 质量为 1-99 时有损，否则无损
+func WithImageFormat(format ImageFormat, quality int) Option {
+	return func(c *Config) {
+		c.Output = &OutputConfig{Format: format, Quality: quality}
+	}
+}
@@ -0,0 +1,129 @@
+package captcha
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strconv"
+
+	"github.com/mojocn/base64Captcha"
+)
+
+// ImageFormat 图片输出格式
+type ImageFormat string
+
+const (
+	ImageFormatDefault ImageFormat = ""     // 沿用各图片的默认格式（主图 JPEG，滑块/缩略图 PNG）
+	ImageFormatPNG     ImageFormat = "png"  // PNG
+	ImageFormatJPEG    ImageFormat = "jpeg" // JPEG，带透明通道的图片仍输出 PNG
+	ImageFormatWebP    ImageFormat = "webp" // WebP，Quality 为 1-99 时有损（VP8），否则无损（VP8L）
+)
+
+// defaultJPEGQuality 与 go-captcha 默认的 JPEG 质量一致
+const defaultJPEGQuality = 100
+
+// OutputConfig 图片输出配置
+type OutputConfig struct {
+	Format  ImageFormat `json:"format"`  // 输出格式，空表示默认
+	Quality int         `json:"quality"` // JPEG、WebP 质量 1-100，0 表示默认（WebP 默认与 100 均为无损）；PNG 忽略该值
+}
+
+// Media 编码后的图片或音频
+type Media struct {
+	ContentType string // MIME 类型，如 image/png、audio/wav
+	Data        []byte // 编码后的字节
+}
+
+// DataURI 返回 data:<mime>;base64,... 格式的字符串
+func (m *Media) DataURI() string {
+	if m == nil {
+		return ""
+	}
+	return "data:" + m.ContentType + ";base64," + base64.StdEncoding.EncodeToString(m.Data)
+}
+
+// ServeHTTP 直接输出媒体内容，并禁止缓存
+func (m *Media) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", m.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(m.Data)))
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(m.Data)
+}
+
+// RawCaptcha 未经 base64 包装的验证码数据
+type RawCaptcha struct {
+	ID     string // 验证码ID
+	Answer string // 答案
+	Master *Media // 主图（文字类验证码的图片、语音验证码的音频）
+	Tile   *Media // 滑块图（仅滑动验证码）
+	Thumb  *Media // 缩略图（点击、旋转验证码）
+
+	XPosition int          // 缺口 X 坐标（滑动验证码）
+	Dots      map[int]*Dot // 点击点数据（点击验证码）
+	Angle     int          // 正确角度（旋转验证码）
+}
+
+// encodeImage 按输出配置编码图片，fallback 为未配置格式时使用的格式
+func (c *Captcha) encodeImage(img image.Image, fallback ImageFormat) (*Media, error) {
+	if img == nil {
+		return nil, fmt.Errorf("image is nil")
+	}
+
+	format, quality := fallback, 0
+	if out := c.config.Output; out != nil {
+		if out.Format != ImageFormatDefault {
+			format = out.Format
+		}
+		quality = out.Quality
+	}
+	// JPEG 不支持透明通道，滑块与缩略图仍使用 PNG
+	if format == ImageFormatJPEG && fallback == ImageFormatPNG {
+		format = ImageFormatPNG
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case ImageFormatPNG:
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		return &Media{ContentType: "image/png", Data: buf.Bytes()}, nil
+	case ImageFormatJPEG:
+		if quality <= 0 {
+			quality = defaultJPEGQuality
+		}
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: min(quality, 100)}); err != nil {
+			return nil, err
+		}
+		return &Media{ContentType: "image/jpeg", Data: buf.Bytes()}, nil
+	case ImageFormatWebP:
+		if err := encodeWebP(&buf, img, quality); err != nil {
+			return nil, err
+		}
+		return &Media{ContentType: "image/webp", Data: buf.Bytes()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported image format: %s", format)
+	}
+}
+
+// encodeItem 编码 base64Captcha 绘制的图片或音频
+func (c *Captcha) encodeItem(item base64Captcha.Item) (*Media, error) {
+	var buf bytes.Buffer
+	if _, err := item.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to encode captcha: %w", err)
+	}
+
+	// 文字类验证码按 PNG 绘制，仅在配置了其他格式时重新编码
+	if out := c.config.Output; out == nil || out.Format == ImageFormatDefault || out.Format == ImageFormatPNG {
+		return &Media{ContentType: "image/png", Data: buf.Bytes()}, nil
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode captcha image: %w", err)
+	}
+	return c.encodeImage(img, ImageFormatJPEG)
+}
@@ -0,0 +1,93 @@
+package captcha
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func TestGenerateRaw_ImageFormats(t *testing.T) {
+	t.Run("默认格式与 Generate 一致", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverDigit))
+		raw, err := captchaInstance.GenerateRaw()
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", raw.Master.ContentType)
+		assert.True(t, strings.HasPrefix(raw.Master.DataURI(), "data:image/png;base64,"))
+		assert.Nil(t, raw.Tile)
+	})
+
+	t.Run("文字验证码输出 JPEG", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverString), WithImageFormat(ImageFormatJPEG, 60))
+		raw, err := captchaInstance.GenerateRaw()
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", raw.Master.ContentType)
+		_, err = jpeg.Decode(bytes.NewReader(raw.Master.Data))
+		assert.NoError(t, err)
+	})
+
+	t.Run("滑动验证码输出 WebP", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverSlide), WithImageFormat(ImageFormatWebP, 0))
+		raw, err := captchaInstance.GenerateRaw()
+		require.NoError(t, err)
+		for _, m := range []*Media{raw.Master, raw.Tile} {
+			assert.Equal(t, "image/webp", m.ContentType)
+			_, err := webp.Decode(bytes.NewReader(m.Data))
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("滑动验证码输出有损 WebP", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverSlide), WithImageFormat(ImageFormatWebP, 75))
+		raw, err := captchaInstance.GenerateRaw()
+		require.NoError(t, err)
+		assert.Equal(t, "VP8 ", string(raw.Master.Data[12:16]))
+		assert.Equal(t, "VP8X", string(raw.Tile.Data[12:16])) // 滑块带透明通道
+		for _, m := range []*Media{raw.Master, raw.Tile} {
+			assert.Equal(t, "image/webp", m.ContentType)
+			_, err := webp.Decode(bytes.NewReader(m.Data))
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("JPEG 下透明图片保持 PNG", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverSlide), WithImageFormat(ImageFormatJPEG, 80))
+		raw, err := captchaInstance.GenerateRaw()
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", raw.Master.ContentType)
+		assert.Equal(t, "image/png", raw.Tile.ContentType)
+	})
+
+	t.Run("语音验证码输出 WAV", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverAudio), WithImageFormat(ImageFormatWebP, 0))
+		raw, err := captchaInstance.GenerateRaw()
+		require.NoError(t, err)
+		assert.Equal(t, "audio/wav", raw.Master.ContentType)
+	})
+
+	t.Run("不支持的格式", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverSlide), WithImageFormat("gif", 0))
+		_, err := captchaInstance.GenerateRaw()
+		assert.ErrorContains(t, err, "unsupported image format")
+	})
+}
+
+func TestMedia_ServeHTTP(t *testing.T) {
+	captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverMath))
+	raw, err := captchaInstance.GenerateRaw()
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	raw.Master.ServeHTTP(rec, httptest.NewRequest("GET", "/captcha", nil))
+
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	_, _, err = image.Decode(rec.Body)
+	assert.NoError(t, err)
+}
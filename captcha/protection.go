@@ -0,0 +1,251 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const lockoutDecay = 24 * time.Hour // 锁定次数的记忆时长，超过后锁定时长从头计算
+
+var (
+	// ErrRateLimited 客户端生成验证码过于频繁
+	ErrRateLimited = errors.New("captcha generation rate limited")
+	// ErrLockedOut 客户端因连续失败被锁定
+	ErrLockedOut = errors.New("captcha client locked out")
+)
+
+// GenerateFor 为指定客户端（IP、会话 ID 等）生成验证码，在 Generate 的基础上执行防暴力破解策略：
+// 客户端被锁定时返回 ErrLockedOut，超出生成频率时返回 ErrRateLimited，累计失败过多时改用升级驱动。
+// 未配置 Protection 或 clientID 为空时等同于 Generate。
+func (c *Captcha) GenerateFor(ctx context.Context, clientID string) (id string, b64s string, answer string, err error) {
+	p := c.config.Protection
+	if p == nil || clientID == "" {
+		return c.Generate()
+	}
+
+	counter, err := c.counter()
+	if err != nil {
+		return "", "", "", err
+	}
+	if locked, err := c.isLocked(ctx, clientID); err != nil {
+		return "", "", "", err
+	} else if locked {
+		return "", "", "", ErrLockedOut
+	}
+
+	if p.GenerateLimit > 0 {
+		n, err := counter.Incr(ctx, c.stateKey("gen", clientID), p.GenerateWindow)
+		if err != nil {
+			return "", "", "", err
+		}
+		if n > int64(p.GenerateLimit) {
+			return "", "", "", ErrRateLimited
+		}
+	}
+
+	driverType := c.config.DriverType
+	if escalated, err := c.isEscalated(ctx, clientID); err != nil {
+		return "", "", "", err
+	} else if escalated {
+		driverType = p.EscalateDriver
+	}
+
+	id, b64s, answer, err = c.generate(driverType)
+	if err != nil {
+		return "", "", "", err
+	}
+	if driverType != c.config.DriverType {
+		// 记录升级后的驱动，验证时按对应方式比较答案
+		if err := c.stateStore().Set(ctx, c.stateKey("driver", id), string(driverType), c.config.Expire); err != nil {
+			return "", "", "", err
+		}
+	}
+	return id, b64s, answer, nil
+}
+
+// VerifyFor 校验指定客户端提交的验证码，失败计入客户端失败次数，达到阈值后升级驱动或锁定。
+// 客户端被锁定时返回 ErrLockedOut。
+func (c *Captcha) VerifyFor(ctx context.Context, clientID, captchaID, userInput string) (bool, error) {
+	p := c.config.Protection
+	if p == nil || clientID == "" {
+		return c.Verify(ctx, captchaID, userInput)
+	}
+
+	if locked, err := c.isLocked(ctx, clientID); err != nil {
+		return false, err
+	} else if locked {
+		return false, ErrLockedOut
+	}
+
+	ok, err := c.Verify(ctx, captchaID, userInput)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		_ = c.stateStore().Delete(ctx, c.stateKey("fail", clientID))
+		return true, nil
+	}
+	return false, c.recordClientFailure(ctx, clientID)
+}
+
+// LockoutRemaining 返回客户端剩余锁定时间，未锁定返回 0
+func (c *Captcha) LockoutRemaining(ctx context.Context, clientID string) (time.Duration, error) {
+	ttl, err := c.stateStore().TTL(ctx, c.stateKey("lock", clientID))
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// stateStore 返回保存计数与标记的存储；无状态存储使用其重放缓存
+func (c *Captcha) stateStore() Store {
+	if s, ok := c.store.(*StatelessStore); ok {
+		return s.replay
+	}
+	return c.store
+}
+
+func (c *Captcha) counter() (Counter, error) {
+	counter, ok := c.stateStore().(Counter)
+	if !ok {
+		return nil, ErrCounterUnsupported
+	}
+	return counter, nil
+}
+
+// stateKey 返回防暴力破解状态的 key
+func (c *Captcha) stateKey(kind, id string) string {
+	return fmt.Sprintf("%s:%s:%s", c.config.KeyPrefix, kind, id)
+}
+
+// driverOf 返回验证码生成时使用的驱动
+func (c *Captcha) driverOf(ctx context.Context, captchaID string) DriverType {
+	p := c.config.Protection
+	if p == nil || p.EscalateDriver == "" || p.EscalateDriver == c.config.DriverType {
+		return c.config.DriverType
+	}
+	if d, err := c.stateStore().Get(ctx, c.stateKey("driver", captchaID)); err == nil {
+		return DriverType(d)
+	}
+	return c.config.DriverType
+}
+
+// beginAttempt 在读取并比较答案之前计入一次尝试，返回本次是第几次尝试（未启用限制时为 0）。
+// 计数先于比较，并发提交的猜测同样受 MaxAttempts 限制；超过上限时作废验证码并返回 allowed=false
+func (c *Captcha) beginAttempt(ctx context.Context, captchaID string) (attempt int64, allowed bool, err error) {
+	p := c.config.Protection
+	if p == nil || p.MaxAttempts <= 0 {
+		return 0, true, nil
+	}
+
+	counter, err := c.counter()
+	if err != nil {
+		return 0, false, err
+	}
+	attempt, err = counter.Incr(ctx, c.stateKey("attempts", captchaID), c.config.Expire)
+	if err != nil {
+		return 0, false, err
+	}
+	if attempt > int64(p.MaxAttempts) {
+		// 保留计数直至过期，之后的尝试继续被拒绝
+		return attempt, false, c.store.Delete(ctx, c.key(captchaID))
+	}
+	return attempt, true, nil
+}
+
+// recordFailure 答案比较失败后，已用完 MaxAttempts 次尝试时作废验证码
+func (c *Captcha) recordFailure(ctx context.Context, captchaID string, attempt int64) error {
+	p := c.config.Protection
+	if p == nil || p.MaxAttempts <= 0 || attempt < int64(p.MaxAttempts) {
+		return nil
+	}
+	return c.store.Delete(ctx, c.key(captchaID))
+}
+
+// clearState 清除验证码的失败计数与驱动标记
+func (c *Captcha) clearState(ctx context.Context, captchaID string) {
+	if c.config.Protection == nil {
+		return
+	}
+	state := c.stateStore()
+	_ = state.Delete(ctx, c.stateKey("attempts", captchaID))
+	_ = state.Delete(ctx, c.stateKey("driver", captchaID))
+}
+
+// recordClientFailure 记录客户端的一次失败，达到 LockoutAfter 后锁定客户端
+func (c *Captcha) recordClientFailure(ctx context.Context, clientID string) error {
+	p := c.config.Protection
+	if p.EscalateAfter <= 0 && p.LockoutAfter <= 0 {
+		return nil
+	}
+
+	counter, err := c.counter()
+	if err != nil {
+		return err
+	}
+	n, err := counter.Incr(ctx, c.stateKey("fail", clientID), p.FailureWindow)
+	if err != nil {
+		return err
+	}
+	if p.LockoutAfter <= 0 || p.Lockout <= 0 || n < int64(p.LockoutAfter) {
+		return nil
+	}
+
+	// 锁定时长按锁定次数翻倍
+	locks, err := counter.Incr(ctx, c.stateKey("locks", clientID), lockoutDecay)
+	if err != nil {
+		return err
+	}
+	lockout := p.Lockout
+	for i := int64(1); i < locks && (p.MaxLockout <= 0 || lockout < p.MaxLockout); i++ {
+		lockout *= 2
+	}
+	if p.MaxLockout > 0 {
+		lockout = min(lockout, p.MaxLockout)
+	}
+
+	state := c.stateStore()
+	if err := state.Set(ctx, c.stateKey("lock", clientID), "1", lockout); err != nil {
+		return err
+	}
+	return state.Delete(ctx, c.stateKey("fail", clientID))
+}
+
+// isLocked 判断客户端是否处于锁定中
+func (c *Captcha) isLocked(ctx context.Context, clientID string) (bool, error) {
+	_, err := c.stateStore().Get(ctx, c.stateKey("lock", clientID))
+	if err != nil {
+		if errors.Is(err, ErrAnswerNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// isEscalated 判断客户端是否应使用升级驱动：失败次数达到 EscalateAfter 或曾被锁定
+func (c *Captcha) isEscalated(ctx context.Context, clientID string) (bool, error) {
+	p := c.config.Protection
+	if p.EscalateAfter <= 0 || p.EscalateDriver == "" {
+		return false, nil
+	}
+
+	state := c.stateStore()
+	for _, kind := range []string{"fail", "locks"} {
+		v, err := state.Get(ctx, c.stateKey(kind, clientID))
+		if errors.Is(err, ErrAnswerNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		n, _ := strconv.ParseInt(v, 10, 64)
+		if kind == "locks" && n > 0 || n >= int64(p.EscalateAfter) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
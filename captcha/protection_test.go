@@ -0,0 +1,204 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProtectedCaptcha(t *testing.T, opts ...Option) *Captcha {
+	store := NewMemoryStore()
+	t.Cleanup(func() { _ = store.Close() })
+	return NewCaptchaWithStore(store, opts...)
+}
+
+func TestProtection_MaxAttempts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Verify 失败达到上限后作废", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithMaxAttempts(3))
+		require.NoError(t, captchaInstance.Save(ctx, "id", "1234"))
+
+		for i := 0; i < 3; i++ {
+			ok, err := captchaInstance.Verify(ctx, "id", "0000")
+			require.NoError(t, err)
+			assert.False(t, ok)
+		}
+
+		ok, err := captchaInstance.Verify(ctx, "id", "1234")
+		require.NoError(t, err)
+		assert.False(t, ok, "captcha should be invalidated after max attempts")
+	})
+
+	t.Run("VerifyWithoutDelete 同样计数", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithMaxAttempts(2))
+		require.NoError(t, captchaInstance.Save(ctx, "id", "1234"))
+
+		ok, err := captchaInstance.VerifyWithoutDelete(ctx, "id", "0000")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = captchaInstance.VerifyWithoutDelete(ctx, "id", "1234")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		_, _ = captchaInstance.VerifyWithoutDelete(ctx, "id", "0000")
+		exists, err := captchaInstance.Exists(ctx, "id")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("未配置时不限制", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t)
+		require.NoError(t, captchaInstance.Save(ctx, "id", "1234"))
+
+		for i := 0; i < 10; i++ {
+			_, _ = captchaInstance.Verify(ctx, "id", "0000")
+		}
+		ok, err := captchaInstance.Verify(ctx, "id", "1234")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+// countingStore 统计读取答案的次数，读取后等待 delay 以放大并发竞争窗口
+type countingStore struct {
+	*MemoryStore
+	delay time.Duration
+	gets  atomic.Int32
+}
+
+func (s *countingStore) Get(ctx context.Context, key string) (string, error) {
+	s.gets.Add(1)
+	v, err := s.MemoryStore.Get(ctx, key)
+	time.Sleep(s.delay)
+	return v, err
+}
+
+func TestProtection_MaxAttemptsConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	for _, name := range []string{"Verify", "VerifyWithoutDelete"} {
+		t.Run(name, func(t *testing.T) {
+			store := &countingStore{MemoryStore: NewMemoryStore(), delay: 20 * time.Millisecond}
+			t.Cleanup(func() { _ = store.Close() })
+			captchaInstance := NewCaptchaWithStore(store, WithMaxAttempts(3))
+			require.NoError(t, captchaInstance.Save(ctx, "id", "1234"))
+
+			var wg sync.WaitGroup
+			for i := 0; i < 30; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					guess := fmt.Sprintf("%04d", 5000+i)
+					if name == "Verify" {
+						_, _ = captchaInstance.Verify(ctx, "id", guess)
+					} else {
+						_, _ = captchaInstance.VerifyWithoutDelete(ctx, "id", guess)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			assert.LessOrEqual(t, store.gets.Load(), int32(3), "answer read more than MaxAttempts times")
+
+			ok, err := captchaInstance.Verify(ctx, "id", "1234")
+			require.NoError(t, err)
+			assert.False(t, ok, "captcha should be invalidated after max attempts")
+		})
+	}
+}
+
+func TestProtection_GenerateRateLimit(t *testing.T) {
+	ctx := context.Background()
+	captchaInstance := newProtectedCaptcha(t, WithGenerateRateLimit(2, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		_, _, _, err := captchaInstance.GenerateFor(ctx, "1.2.3.4")
+		require.NoError(t, err)
+	}
+	_, _, _, err := captchaInstance.GenerateFor(ctx, "1.2.3.4")
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	// 其他客户端不受影响
+	_, _, _, err = captchaInstance.GenerateFor(ctx, "5.6.7.8")
+	assert.NoError(t, err)
+}
+
+func TestProtection_Lockout(t *testing.T) {
+	ctx := context.Background()
+	captchaInstance := newProtectedCaptcha(t,
+		WithMaxAttempts(0),
+		WithLockout(2, 50*time.Millisecond, 150*time.Millisecond),
+	)
+
+	fail := func() error {
+		id, _, answer, err := captchaInstance.GenerateFor(ctx, "client")
+		if err != nil {
+			return err
+		}
+		require.NoError(t, captchaInstance.Save(ctx, id, answer))
+		_, err = captchaInstance.VerifyFor(ctx, "client", id, "wrong")
+		return err
+	}
+
+	require.NoError(t, fail())
+	require.NoError(t, fail())
+
+	_, _, _, err := captchaInstance.GenerateFor(ctx, "client")
+	assert.ErrorIs(t, err, ErrLockedOut)
+	_, err = captchaInstance.VerifyFor(ctx, "client", "any", "any")
+	assert.ErrorIs(t, err, ErrLockedOut)
+
+	first, err := captchaInstance.LockoutRemaining(ctx, "client")
+	require.NoError(t, err)
+	assert.True(t, first > 0 && first <= 50*time.Millisecond)
+
+	// 解锁后再次锁定，时长翻倍
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, fail())
+	require.NoError(t, fail())
+
+	second, err := captchaInstance.LockoutRemaining(ctx, "client")
+	require.NoError(t, err)
+	assert.True(t, second > 50*time.Millisecond && second <= 100*time.Millisecond, "got %v", second)
+}
+
+func TestProtection_Escalation(t *testing.T) {
+	ctx := context.Background()
+	captchaInstance := newProtectedCaptcha(t,
+		WithDriverType(DriverDigit),
+		WithStringSource("ABCDEF"),
+		WithEscalation(1, DriverString),
+	)
+
+	id, _, answer, err := captchaInstance.GenerateFor(ctx, "client")
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9]+$`, answer)
+	require.NoError(t, captchaInstance.Save(ctx, id, answer))
+
+	ok, err := captchaInstance.VerifyFor(ctx, "client", id, "wrong")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 失败后改用字符串驱动
+	id, _, answer, err = captchaInstance.GenerateFor(ctx, "client")
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-F]+$`, answer)
+	require.NoError(t, captchaInstance.Save(ctx, id, answer))
+
+	ok, err = captchaInstance.VerifyFor(ctx, "client", id, answer)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// 成功后失败计数清零，恢复默认驱动
+	_, _, answer, err = captchaInstance.GenerateFor(ctx, "client")
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9]+$`, answer)
+}
@@ -0,0 +1,380 @@
+package captcha
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // 注册 JPEG 解码器
+	_ "image/png"  // 注册 PNG 解码器
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/mojocn/base64Captcha"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+)
+
+// TileMask 滑块图形，三张图会被缩放到滑块尺寸
+type TileMask struct {
+	Overlay image.Image // 叠加在滑块上的描边/高光
+	Shadow  image.Image // 主图缺口处的阴影
+	Mask    image.Image // 裁剪滑块的遮罩，不透明区域即滑块形状
+}
+
+// ResourcePack 行为验证码资源包，生成时从各类资源中随机选取；缺失的部分使用内置资源
+type ResourcePack struct {
+	Backgrounds  []image.Image    // 滑动、点击验证码背景图
+	RotateImages []image.Image    // 旋转验证码图片（建议为正方形）
+	TileMasks    []*TileMask      // 滑块图形
+	Fonts        []*truetype.Font // 点击验证码字体
+	Words        []string         // 点击验证码字词，单个汉字或不超过 2 个字符
+
+	Audio map[string]AudioSamples // 语音验证码发音样本，按语言补充或覆盖内置样本
+}
+
+// LoadResourcePack 从文件系统加载资源包，目录结构：
+//
+//	backgrounds/*.{png,jpg,jpeg,webp}       背景图
+//	rotate/*.{png,jpg,jpeg,webp}            旋转图片
+//	tiles/<name>/{overlay,shadow,mask}.png  滑块图形
+//	fonts/*.{ttf,ttc}                       字体
+//	words/*.txt                             字词词典，以空白分隔，# 开头的行为注释
+//	audio/<lang>/<char>.wav                 语音验证码发音样本（8kHz 单声道 PCM），文件名为单个字符，如 a.wav
+//
+// 各目录均可缺省；可配合 embed.FS 或 os.DirFS 使用。
+func LoadResourcePack(fsys fs.FS) (*ResourcePack, error) {
+	pack := &ResourcePack{}
+	var err error
+
+	if pack.Backgrounds, err = loadImages(fsys, "backgrounds"); err != nil {
+		return nil, err
+	}
+	if pack.RotateImages, err = loadImages(fsys, "rotate"); err != nil {
+		return nil, err
+	}
+	if pack.TileMasks, err = loadTileMasks(fsys, "tiles"); err != nil {
+		return nil, err
+	}
+	if pack.Fonts, err = loadFonts(fsys, "fonts"); err != nil {
+		return nil, err
+	}
+	if pack.Words, err = loadWords(fsys, "words"); err != nil {
+		return nil, err
+	}
+	if pack.Audio, err = loadAudio(fsys, "audio"); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+func (p *ResourcePack) backgrounds() []image.Image {
+	if p == nil {
+		return nil
+	}
+	return p.Backgrounds
+}
+
+func (p *ResourcePack) rotateImages() []image.Image {
+	if p == nil {
+		return nil
+	}
+	return p.RotateImages
+}
+
+func (p *ResourcePack) tileMasks() []*TileMask {
+	if p == nil {
+		return nil
+	}
+	return p.TileMasks
+}
+
+func (p *ResourcePack) fonts() []*truetype.Font {
+	if p == nil {
+		return nil
+	}
+	return p.Fonts
+}
+
+func (p *ResourcePack) words() []string {
+	if p == nil {
+		return nil
+	}
+	return p.Words
+}
+
+func (p *ResourcePack) audio(lang string) AudioSamples {
+	if p == nil {
+		return nil
+	}
+	return p.Audio[lang]
+}
+
+// readDir 按文件名排序列出目录项，目录不存在时返回空
+func readDir(fsys fs.FS, dir string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func hasExt(name string, exts ...string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeImageFile(fsys fs.FS, name string) (image.Image, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return img, nil
+}
+
+func loadImages(fsys fs.FS, dir string) ([]image.Image, error) {
+	entries, err := readDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var images []image.Image
+	for _, e := range entries {
+		if e.IsDir() || !hasExt(e.Name(), ".png", ".jpg", ".jpeg", ".webp") {
+			continue
+		}
+		img, err := decodeImageFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+func loadTileMasks(fsys fs.FS, dir string) ([]*TileMask, error) {
+	entries, err := readDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var masks []*TileMask
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		var imgs [3]image.Image
+		for i, name := range []string{"overlay.png", "shadow.png", "mask.png"} {
+			if imgs[i], err = decodeImageFile(fsys, path.Join(dir, e.Name(), name)); err != nil {
+				return nil, err
+			}
+		}
+		masks = append(masks, &TileMask{Overlay: imgs[0], Shadow: imgs[1], Mask: imgs[2]})
+	}
+	return masks, nil
+}
+
+func loadFonts(fsys fs.FS, dir string) ([]*truetype.Font, error) {
+	entries, err := readDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var fonts []*truetype.Font
+	for _, e := range entries {
+		if e.IsDir() || !hasExt(e.Name(), ".ttf", ".ttc") {
+			continue
+		}
+		name := path.Join(dir, e.Name())
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := truetype.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse font %s: %w", name, err)
+		}
+		fonts = append(fonts, f)
+	}
+	return fonts, nil
+}
+
+func loadWords(fsys fs.FS, dir string) ([]string, error) {
+	entries, err := readDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var words []string
+	seen := make(map[string]struct{})
+	for _, e := range entries {
+		if e.IsDir() || !hasExt(e.Name(), ".txt") {
+			continue
+		}
+		name := path.Join(dir, e.Name())
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		tokens, err := parseWords(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, t := range tokens {
+			if _, ok := seen[t]; ok {
+				continue
+			}
+			seen[t] = struct{}{}
+			words = append(words, t)
+		}
+	}
+	return words, nil
+}
+
+// parseWords 解析词典：包含汉字的词拆分为单字，其余词不能超过 2 个字符
+func parseWords(data []byte) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		for _, token := range strings.Fields(text) {
+			if strings.IndexFunc(token, isHan) >= 0 {
+				for _, r := range token {
+					words = append(words, string(r))
+				}
+				continue
+			}
+			if utf8.RuneCountInString(token) > 2 {
+				return nil, fmt.Errorf("line %d: word %q exceeds 2 characters", line, token)
+			}
+			words = append(words, token)
+		}
+	}
+	return words, scanner.Err()
+}
+
+func isHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+var (
+	defaultFontOnce sync.Once
+	defaultFont     *truetype.Font
+)
+
+// defaultClickFont 返回 base64Captcha 内置的文泉驿微米黑字体
+func defaultClickFont() *truetype.Font {
+	defaultFontOnce.Do(func() {
+		defaultFont = base64Captcha.DefaultEmbeddedFonts.LoadFontByName("fonts/wqy-microhei.ttc")
+	})
+	return defaultFont
+}
+
+// defaultTileMask 生成内置的拼图形状滑块：方块顶部与右侧各带一个半圆凸起
+func defaultTileMask(width, height int) *TileMask {
+	if width <= 0 || height <= 0 {
+		width, height = 60, 60
+	}
+	r := float64(min(width, height)) / 6
+	w, h := float64(width), float64(height)
+	inside := func(x, y int) bool {
+		fx, fy := float64(x)+0.5, float64(y)+0.5
+		if fx >= 0 && fx < w-r && fy >= r && fy < h {
+			return true
+		}
+		if math.Hypot(fx-(w-r)/2, fy-r) < r {
+			return true
+		}
+		return math.Hypot(fx-(w-r), fy-(h+r)/2) < r
+	}
+
+	rect := image.Rect(0, 0, width, height)
+	overlay := image.NewNRGBA(rect)
+	shadow := image.NewNRGBA(rect)
+	mask := image.NewNRGBA(rect)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !inside(x, y) {
+				continue
+			}
+			mask.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+			shadow.SetNRGBA(x, y, color.NRGBA{A: 150})
+			if !inside(x-2, y) || !inside(x+2, y) || !inside(x, y-2) || !inside(x, y+2) {
+				overlay.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 200})
+			}
+		}
+	}
+	return &TileMask{Overlay: overlay, Shadow: shadow, Mask: mask}
+}
+
+// defaultRotateImage 生成内置的旋转图片：按角度变化色相的圆盘，顶部带有方向标记
+func defaultRotateImage(size int) image.Image {
+	if size <= 0 {
+		size = 220
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	c := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)+0.5-c, float64(y)+0.5-c
+			d := math.Hypot(dx, dy)
+			if d > c {
+				continue
+			}
+			hue := (math.Atan2(dy, dx) + math.Pi) / (2 * math.Pi)
+			light := 0.35 + 0.4*d/c
+			// 顶部的方向标记
+			if math.Abs(dx) < c/10 && dy < -c/3 && dy > -c*0.8 {
+				light = 0.95
+			}
+			img.SetNRGBA(x, y, hslToNRGBA(hue, 0.7, light))
+		}
+	}
+	return img
+}
+
+func hslToNRGBA(h, s, l float64) color.NRGBA {
+	q := l * (1 + s)
+	if l >= 0.5 {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	channel := func(t float64) uint8 {
+		t -= math.Floor(t)
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 0.5:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(math.Round(v * 255))
+	}
+	return color.NRGBA{R: channel(h + 1.0/3), G: channel(h), B: channel(h - 1.0/3), A: 255}
+}
@@ -0,0 +1,126 @@
+package captcha
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeTestPNG(t *testing.T, w, h int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func testResourceFS(t *testing.T) fstest.MapFS {
+	tile := defaultTileMask(60, 60)
+	encode := func(img image.Image) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+		return buf.Bytes()
+	}
+	return fstest.MapFS{
+		"backgrounds/a.png":        {Data: encodeTestPNG(t, 300, 220, color.NRGBA{R: 200, A: 255})},
+		"backgrounds/b.png":        {Data: encodeTestPNG(t, 300, 220, color.NRGBA{G: 200, A: 255})},
+		"backgrounds/readme.md":    {Data: []byte("ignored")},
+		"rotate/a.png":             {Data: encode(defaultRotateImage(220))},
+		"tiles/puzzle/overlay.png": {Data: encode(tile.Overlay)},
+		"tiles/puzzle/shadow.png":  {Data: encode(tile.Shadow)},
+		"tiles/puzzle/mask.png":    {Data: encode(tile.Mask)},
+		"words/zh.txt":             {Data: []byte("# 常用字\n天地玄黄\n宇宙 洪荒\n天\n")},
+		"words/en.txt":             {Data: []byte("A B Cd\n")},
+	}
+}
+
+func TestLoadResourcePack(t *testing.T) {
+	pack, err := LoadResourcePack(testResourceFS(t))
+	require.NoError(t, err)
+
+	assert.Len(t, pack.Backgrounds, 2)
+	assert.Len(t, pack.RotateImages, 1)
+	require.Len(t, pack.TileMasks, 1)
+	assert.NotNil(t, pack.TileMasks[0].Mask)
+	assert.Empty(t, pack.Fonts)
+	// 汉字拆分为单字并去重，词典按文件名顺序合并
+	assert.Equal(t, []string{"A", "B", "Cd", "天", "地", "玄", "黄", "宇", "宙", "洪", "荒"}, pack.Words)
+
+	t.Run("词过长", func(t *testing.T) {
+		_, err := LoadResourcePack(fstest.MapFS{"words/bad.txt": {Data: []byte("abc")}})
+		assert.ErrorContains(t, err, "exceeds 2 characters")
+	})
+
+	t.Run("滑块缺少遮罩", func(t *testing.T) {
+		_, err := LoadResourcePack(fstest.MapFS{"tiles/x/overlay.png": {Data: encodeTestPNG(t, 4, 4, color.White)}})
+		assert.Error(t, err)
+	})
+
+	t.Run("空文件系统", func(t *testing.T) {
+		pack, err := LoadResourcePack(fstest.MapFS{})
+		require.NoError(t, err)
+		assert.Empty(t, pack.Backgrounds)
+	})
+}
+
+func TestGenerate_BehavioralDefaults(t *testing.T) {
+	for _, driverType := range []DriverType{DriverSlide, DriverClick, DriverRotate} {
+		t.Run(string(driverType), func(t *testing.T) {
+			captchaInstance := newProtectedCaptcha(t, WithDriverType(driverType))
+			id, data, answer, err := captchaInstance.Generate()
+			require.NoError(t, err)
+			assert.NotEmpty(t, id)
+			assert.NotEmpty(t, answer)
+			assert.True(t, json.Valid([]byte(data)))
+		})
+	}
+}
+
+func TestGenerate_WithResourcePack(t *testing.T) {
+	pack, err := LoadResourcePack(testResourceFS(t))
+	require.NoError(t, err)
+
+	t.Run("滑动", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverSlide), WithResourcePack(pack))
+		raw, err := captchaInstance.GenerateRaw()
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", raw.Master.ContentType)
+		assert.Equal(t, "image/png", raw.Tile.ContentType)
+	})
+
+	t.Run("点击", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t,
+			WithDriverType(DriverClick),
+			WithResourcePack(pack),
+			WithClickCaptchaCount(4),
+			WithClickVerifyCount(2),
+		)
+		_, data, _, err := captchaInstance.Generate()
+		require.NoError(t, err)
+
+		var click ClickCaptchaData
+		require.NoError(t, json.Unmarshal([]byte(data), &click))
+		assert.Len(t, click.Dots, 2)
+	})
+
+	t.Run("旋转", func(t *testing.T) {
+		captchaInstance := newProtectedCaptcha(t, WithDriverType(DriverRotate), WithResourcePack(pack))
+		raw, err := captchaInstance.GenerateRaw()
+		require.NoError(t, err)
+
+		img, err := png.Decode(bytes.NewReader(raw.Master.Data))
+		require.NoError(t, err)
+		assert.Equal(t, DefaultRotateConfig().MasterWidth, img.Bounds().Dx())
+	})
+}
@@ -0,0 +1,200 @@
+package captcha
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	minStatelessSecretLen = 16                    // 密钥最短长度
+	statelessAAD          = "go-utils/captcha/v1" // 附加认证数据，区分其他用途的密文
+	replayKeyPrefix       = "captcha:replay:"     // 重放缓存 key 前缀
+)
+
+// ErrStatelessSecret 无状态存储密钥过短
+var ErrStatelessSecret = errors.New("stateless store secret must be at least 16 bytes")
+
+type StatelessOption func(*StatelessStore)
+
+// WithReplayStore 设置已使用凭证的重放缓存，需实现 Counter，多实例部署时应使用共享存储（如 RedisStore）
+func WithReplayStore(store Store) StatelessOption {
+	return func(s *StatelessStore) {
+		if store != nil {
+			s.replay = store
+		}
+	}
+}
+
+// statelessPayload 凭证内的明文
+type statelessPayload struct {
+	Answer   string `json:"a"`
+	ExpireAt int64  `json:"e"` // 过期时间（Unix 毫秒），必须大于 0
+}
+
+// StatelessStore 无状态答案存储：答案与过期时间经 AES-GCM 加密并认证后作为凭证交给客户端
+// （例如放入 Cookie 或随表单回传），服务端只需在重放缓存中记录已使用凭证的随机数。
+//
+// 该存储无法按验证码 ID 保存答案，需配合 Captcha.Issue 使用，验证时传入凭证代替 ID。
+type StatelessStore struct {
+	aead    cipher.AEAD
+	replay  Store
+	counter Counter // 即 replay，用于原子地标记凭证已使用
+}
+
+// NewStatelessStore 创建无状态存储，secret 至少 16 字节，经 SHA-256 派生为 AES-256 密钥
+func NewStatelessStore(secret []byte, opts ...StatelessOption) (*StatelessStore, error) {
+	if len(secret) < minStatelessSecretLen {
+		return nil, ErrStatelessSecret
+	}
+
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StatelessStore{aead: aead}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.replay == nil {
+		s.replay = NewMemoryStore()
+	}
+	counter, ok := s.replay.(Counter)
+	if !ok {
+		return nil, ErrCounterUnsupported
+	}
+	s.counter = counter
+	return s, nil
+}
+
+// Seal 将答案封装为凭证；ttl 必须大于 0，重放缓存中的记录随凭证一同过期
+func (s *StatelessStore) Seal(_ context.Context, answer string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", ErrStatelessExpire
+	}
+	payload := statelessPayload{Answer: answer, ExpireAt: time.Now().Add(ttl).UnixMilli()}
+	plain, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, plain, []byte(statelessAAD))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Set 无状态存储不支持按 ID 保存，始终返回 ErrStatelessSave
+func (s *StatelessStore) Set(context.Context, string, string, time.Duration) error {
+	return ErrStatelessSave
+}
+
+func (s *StatelessStore) Get(ctx context.Context, token string) (string, error) {
+	payload, nonce, err := s.open(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.replay.Get(ctx, replayKeyPrefix+nonce); err == nil {
+		return "", ErrAnswerNotFound
+	} else if !errors.Is(err, ErrAnswerNotFound) {
+		return "", err
+	}
+	return payload.Answer, nil
+}
+
+// Delete 将凭证记入重放缓存直至其过期，此后凭证不再可用
+func (s *StatelessStore) Delete(ctx context.Context, token string) error {
+	if err := s.Consume(ctx, token); err != nil && !errors.Is(err, ErrAnswerNotFound) {
+		return err
+	}
+	return nil // 无效、已过期或已使用的凭证无需记录
+}
+
+// Consume 原子地将凭证记入重放缓存直至其过期，凭证无效、已过期或已被使用时返回 ErrAnswerNotFound。
+// 以 Counter.Incr 返回 1 作为 set-if-absent，并发验证同一凭证时只有一个能成功
+func (s *StatelessStore) Consume(ctx context.Context, token string) error {
+	payload, nonce, err := s.open(ctx, token)
+	if err != nil {
+		return err
+	}
+	n, err := s.counter.Incr(ctx, replayKeyPrefix+nonce, payload.remaining())
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return ErrAnswerNotFound
+	}
+	return nil
+}
+
+func (s *StatelessStore) TTL(ctx context.Context, token string) (time.Duration, error) {
+	if _, err := s.Get(ctx, token); err != nil {
+		if errors.Is(err, ErrAnswerNotFound) {
+			return -2, nil
+		}
+		return 0, err
+	}
+	payload, _, _ := s.open(ctx, token)
+	return payload.remaining(), nil
+}
+
+// Cookie 构造携带凭证的 HttpOnly Cookie，有效期与凭证一致
+func (s *StatelessStore) Cookie(ctx context.Context, name, token string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if payload, _, err := s.open(ctx, token); err == nil {
+		cookie.Expires = time.UnixMilli(payload.ExpireAt)
+		cookie.MaxAge = max(int(payload.remaining()/time.Second), 1)
+	}
+	return cookie
+}
+
+// open 解密并校验凭证，返回明文与十六进制随机数；凭证无效或已过期时返回 ErrAnswerNotFound
+func (s *StatelessStore) open(_ context.Context, token string) (statelessPayload, string, error) {
+	var payload statelessPayload
+
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(sealed) < s.aead.NonceSize()+s.aead.Overhead() {
+		return payload, "", ErrAnswerNotFound
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, []byte(statelessAAD))
+	if err != nil {
+		return payload, "", ErrAnswerNotFound
+	}
+	if err := json.Unmarshal(plain, &payload); err != nil {
+		return payload, "", ErrAnswerNotFound
+	}
+	// 不过期的凭证会让重放缓存永久保留记录，一律拒绝
+	if payload.ExpireAt <= 0 || payload.remaining() <= 0 {
+		return payload, "", ErrAnswerNotFound
+	}
+	return payload, hex.EncodeToString(nonce), nil
+}
+
+// remaining 返回剩余有效期
+func (p statelessPayload) remaining() time.Duration {
+	return time.Until(time.UnixMilli(p.ExpireAt))
+}
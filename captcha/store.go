@@ -0,0 +1,92 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrAnswerNotFound 验证码答案不存在、已过期或已被使用
+	ErrAnswerNotFound = errors.New("captcha answer not found")
+	// ErrStatelessSave 无状态存储无法按 ID 保存答案，需使用 Captcha.Issue 获取凭证
+	ErrStatelessSave = errors.New("stateless store cannot save by id, use Captcha.Issue")
+	// ErrStatelessExpire 无状态凭证必须设置有效期，否则重放缓存需要永久保存已使用的凭证
+	ErrStatelessExpire = errors.New("stateless captcha token must expire, set a positive Expire")
+	// ErrCounterUnsupported 存储未实现 Counter，无法启用防暴力破解策略
+	ErrCounterUnsupported = errors.New("captcha store does not implement Counter")
+)
+
+// Store 验证码答案存储
+type Store interface {
+	// Set 保存答案，ttl <= 0 表示不过期
+	Set(ctx context.Context, key, answer string, ttl time.Duration) error
+	// Get 读取答案，不存在或已过期时返回 ErrAnswerNotFound
+	Get(ctx context.Context, key string) (string, error)
+	// Delete 删除答案，key 不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// TTL 返回剩余有效期，语义与 Redis 一致：不存在返回 -2，不过期返回 -1
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// Sealer 无状态存储：答案被封装进凭证交由客户端保存，验证时凭证即为 key
+type Sealer interface {
+	Seal(ctx context.Context, answer string, ttl time.Duration) (token string, err error)
+}
+
+// Consumer 支持原子消费答案的存储：同一答案只能被成功消费一次，验证成功时代替 Delete 使用
+type Consumer interface {
+	// Consume 原子地将答案标记为已使用；答案不存在、已过期或已被使用时返回 ErrAnswerNotFound
+	Consume(ctx context.Context, key string) error
+}
+
+// Counter 支持原子计数的存储，防暴力破解策略依赖该能力
+type Counter interface {
+	// Incr 将 key 加一并返回新值；key 不存在时创建并设置 ttl（固定窗口）
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// incrScript 原子地自增并在首次创建时设置过期时间
+var incrScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// RedisStore 基于 Redis 的答案存储
+type RedisStore struct {
+	rdb *redis.Client
+}
+
+// NewRedisStore 创建 Redis 存储
+func NewRedisStore(rdb *redis.Client) *RedisStore {
+	return &RedisStore{rdb: rdb}
+}
+
+func (s *RedisStore) Set(ctx context.Context, key, answer string, ttl time.Duration) error {
+	return s.rdb.Set(ctx, key, answer, ttl).Err()
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (string, error) {
+	ans, err := s.rdb.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrAnswerNotFound
+	}
+	return ans, err
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.rdb.Del(ctx, key).Err()
+}
+
+func (s *RedisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return s.rdb.TTL(ctx, key).Result()
+}
+
+func (s *RedisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, s.rdb, []string{key}, ttl.Milliseconds()).Int64()
+}
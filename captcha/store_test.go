@@ -0,0 +1,324 @@
+package captcha
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("读写与删除", func(t *testing.T) {
+		store := NewMemoryStore()
+		defer store.Close()
+
+		require.NoError(t, store.Set(ctx, "k", "1234", time.Minute))
+		ans, err := store.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, "1234", ans)
+
+		ttl, err := store.TTL(ctx, "k")
+		require.NoError(t, err)
+		assert.True(t, ttl > 0 && ttl <= time.Minute)
+
+		require.NoError(t, store.Delete(ctx, "k"))
+		_, err = store.Get(ctx, "k")
+		assert.ErrorIs(t, err, ErrAnswerNotFound)
+
+		ttl, err = store.TTL(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(-2), ttl)
+	})
+
+	t.Run("过期后不可读取", func(t *testing.T) {
+		store := NewMemoryStore(WithCleanupInterval(0))
+		defer store.Close()
+
+		require.NoError(t, store.Set(ctx, "k", "1234", 20*time.Millisecond))
+		time.Sleep(40 * time.Millisecond)
+
+		_, err := store.Get(ctx, "k")
+		assert.ErrorIs(t, err, ErrAnswerNotFound)
+	})
+
+	t.Run("后台清理过期答案", func(t *testing.T) {
+		store := NewMemoryStore(WithCleanupInterval(10 * time.Millisecond))
+		defer store.Close()
+
+		require.NoError(t, store.Set(ctx, "short", "1", 10*time.Millisecond))
+		require.NoError(t, store.Set(ctx, "forever", "2", 0))
+
+		assert.Eventually(t, func() bool { return store.Len() == 1 }, time.Second, 5*time.Millisecond)
+
+		ttl, err := store.TTL(ctx, "forever")
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(-1), ttl)
+	})
+}
+
+func TestStatelessStore(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	t.Run("密钥过短", func(t *testing.T) {
+		_, err := NewStatelessStore([]byte("short"))
+		assert.ErrorIs(t, err, ErrStatelessSecret)
+	})
+
+	t.Run("封装与读取", func(t *testing.T) {
+		store, err := NewStatelessStore(secret)
+		require.NoError(t, err)
+
+		token, err := store.Seal(ctx, "1234", time.Minute)
+		require.NoError(t, err)
+
+		ans, err := store.Get(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, "1234", ans)
+
+		ttl, err := store.TTL(ctx, token)
+		require.NoError(t, err)
+		assert.True(t, ttl > 0 && ttl <= time.Minute)
+
+		assert.ErrorIs(t, store.Set(ctx, "id", "1234", time.Minute), ErrStatelessSave)
+	})
+
+	t.Run("篡改或密钥不同", func(t *testing.T) {
+		store, err := NewStatelessStore(secret)
+		require.NoError(t, err)
+		other, err := NewStatelessStore([]byte("another-secret-of-enough-length"))
+		require.NoError(t, err)
+
+		token, err := store.Seal(ctx, "1234", time.Minute)
+		require.NoError(t, err)
+
+		_, err = other.Get(ctx, token)
+		assert.ErrorIs(t, err, ErrAnswerNotFound)
+
+		tampered := []byte(token)
+		tampered[len(tampered)/2] ^= 1
+		_, err = store.Get(ctx, string(tampered))
+		assert.ErrorIs(t, err, ErrAnswerNotFound)
+
+		_, err = store.Get(ctx, "not-a-token")
+		assert.ErrorIs(t, err, ErrAnswerNotFound)
+	})
+
+	t.Run("过期", func(t *testing.T) {
+		store, err := NewStatelessStore(secret)
+		require.NoError(t, err)
+
+		token, err := store.Seal(ctx, "1234", 10*time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(30 * time.Millisecond)
+
+		_, err = store.Get(ctx, token)
+		assert.ErrorIs(t, err, ErrAnswerNotFound)
+	})
+
+	t.Run("拒绝不过期的凭证", func(t *testing.T) {
+		replay := NewMemoryStore()
+		defer replay.Close()
+		store, err := NewStatelessStore(secret, WithReplayStore(replay))
+		require.NoError(t, err)
+
+		_, err = store.Seal(ctx, "1234", 0)
+		assert.ErrorIs(t, err, ErrStatelessExpire)
+
+		// 旧版本签发的不过期凭证：无法验证，也不会在重放缓存中写入永久记录
+		plain, err := json.Marshal(statelessPayload{Answer: "1234"})
+		require.NoError(t, err)
+		nonce := make([]byte, store.aead.NonceSize())
+		token := base64.RawURLEncoding.EncodeToString(store.aead.Seal(nonce, nonce, plain, []byte(statelessAAD)))
+
+		_, err = store.Get(ctx, token)
+		assert.ErrorIs(t, err, ErrAnswerNotFound)
+		assert.ErrorIs(t, store.Consume(ctx, token), ErrAnswerNotFound)
+		_, err = replay.Get(ctx, replayKeyPrefix+hex.EncodeToString(nonce))
+		assert.ErrorIs(t, err, ErrAnswerNotFound)
+	})
+
+	t.Run("删除后拒绝重放", func(t *testing.T) {
+		replay := NewMemoryStore()
+		defer replay.Close()
+		store, err := NewStatelessStore(secret, WithReplayStore(replay))
+		require.NoError(t, err)
+
+		token, err := store.Seal(ctx, "1234", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, store.Delete(ctx, token))
+
+		_, err = store.Get(ctx, token)
+		assert.ErrorIs(t, err, ErrAnswerNotFound)
+		assert.Equal(t, 1, replay.Len())
+	})
+
+	t.Run("并发消费只成功一次", func(t *testing.T) {
+		store, err := NewStatelessStore(secret)
+		require.NoError(t, err)
+
+		token, err := store.Seal(ctx, "1234", time.Minute)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		var consumed atomic.Int32
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if store.Consume(ctx, token) == nil {
+					consumed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), consumed.Load())
+		assert.ErrorIs(t, store.Consume(ctx, token), ErrAnswerNotFound)
+		assert.NoError(t, store.Delete(ctx, token))
+	})
+
+	t.Run("重放缓存需实现 Counter", func(t *testing.T) {
+		_, err := NewStatelessStore(secret, WithReplayStore(storeOnly{NewMemoryStore()}))
+		assert.ErrorIs(t, err, ErrCounterUnsupported)
+	})
+
+	t.Run("Cookie", func(t *testing.T) {
+		store, err := NewStatelessStore(secret)
+		require.NoError(t, err)
+
+		token, err := store.Seal(ctx, "1234", time.Minute)
+		require.NoError(t, err)
+
+		cookie := store.Cookie(ctx, "captcha", token)
+		assert.Equal(t, token, cookie.Value)
+		assert.True(t, cookie.HttpOnly)
+		assert.True(t, cookie.MaxAge > 0 && cookie.MaxAge <= 60)
+	})
+}
+
+func TestCaptcha_WithMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	captchaInstance := NewCaptchaWithStore(store, WithKeyPrefix("test"))
+	ctx := context.Background()
+
+	id, _, answer, err := captchaInstance.Generate()
+	require.NoError(t, err)
+	require.NoError(t, captchaInstance.Save(ctx, id, answer))
+
+	exists, err := captchaInstance.Exists(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = store.Get(ctx, "test:"+id)
+	require.NoError(t, err)
+
+	ok, err := captchaInstance.Verify(ctx, id, "wrong")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = captchaInstance.Verify(ctx, id, answer)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	exists, err = captchaInstance.Exists(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestCaptcha_Issue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("有状态存储返回ID", func(t *testing.T) {
+		store := NewMemoryStore()
+		defer store.Close()
+		captchaInstance := NewCaptchaWithStore(store)
+
+		token, err := captchaInstance.Issue(ctx, "abc", "1234")
+		require.NoError(t, err)
+		assert.Equal(t, "abc", token)
+
+		ok, err := captchaInstance.Verify(ctx, token, "1234")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("无状态存储返回凭证", func(t *testing.T) {
+		store, err := NewStatelessStore([]byte("0123456789abcdef0123456789abcdef"))
+		require.NoError(t, err)
+		captchaInstance := NewCaptchaWithStore(store, WithExpire(time.Minute))
+
+		token, err := captchaInstance.Issue(ctx, "abc", "1234")
+		require.NoError(t, err)
+		assert.NotEqual(t, "abc", token)
+
+		ok, err := captchaInstance.VerifyWithoutDelete(ctx, token, "1234")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = captchaInstance.Verify(ctx, token, "1234")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = captchaInstance.Verify(ctx, token, "1234")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.ErrorIs(t, captchaInstance.Save(ctx, "abc", "1234"), ErrStatelessSave)
+	})
+
+	t.Run("无状态凭证并发验证只通过一次", func(t *testing.T) {
+		// 放慢重放缓存的读取，让所有并发验证都在凭证被标记前读到未使用
+		replay := &slowGetStore{MemoryStore: NewMemoryStore(), delay: 20 * time.Millisecond}
+		defer replay.Close()
+		store, err := NewStatelessStore([]byte("0123456789abcdef0123456789abcdef"), WithReplayStore(replay))
+		require.NoError(t, err)
+		captchaInstance := NewCaptchaWithStore(store, WithExpire(time.Minute))
+
+		token, err := captchaInstance.Issue(ctx, "abc", "1234")
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		var passed atomic.Int32
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if ok, err := captchaInstance.Verify(ctx, token, "1234"); err == nil && ok {
+					passed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), passed.Load())
+	})
+}
+
+// storeOnly 只暴露 Store 方法，隐藏底层存储实现的 Counter
+type storeOnly struct {
+	Store
+}
+
+// slowGetStore 读取后等待 delay 再返回，用于放大并发竞争窗口
+type slowGetStore struct {
+	*MemoryStore
+	delay time.Duration
+}
+
+func (s *slowGetStore) Get(ctx context.Context, key string) (string, error) {
+	v, err := s.MemoryStore.Get(ctx, key)
+	time.Sleep(s.delay)
+	return v, err
+}
@@ -0,0 +1,259 @@
+package captcha
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// 轨迹风险项
+const (
+	RiskMissingTrack      = "missing_track"      // 要求轨迹但未提交
+	RiskInvalidTimestamps = "invalid_timestamps" // 时间戳非递增
+	RiskTooFewPoints      = "too_few_points"     // 采样点过少
+	RiskTooFast           = "too_fast"           // 拖动耗时过短
+	RiskTooSlow           = "too_slow"           // 拖动耗时过长
+	RiskConstantVelocity  = "constant_velocity"  // 速度几乎不变
+	RiskNoDeceleration    = "no_deceleration"    // 接近终点时没有减速
+	RiskStraightLine      = "straight_line"      // 轨迹为严格直线
+	RiskNoJitter          = "no_jitter"          // 缺少人手的细微抖动
+	RiskTrackMismatch     = "track_mismatch"     // 轨迹终点与提交值不一致
+)
+
+// riskWeights 各风险项的权重，风险分为命中项权重之和（上限 1）
+var riskWeights = map[string]float64{
+	RiskMissingTrack:      1,
+	RiskInvalidTimestamps: 1,
+	RiskTooFewPoints:      0.6,
+	RiskTooFast:           0.5,
+	RiskTooSlow:           0.2,
+	RiskConstantVelocity:  0.4,
+	RiskNoDeceleration:    0.2,
+	RiskStraightLine:      0.4,
+	RiskNoJitter:          0.3,
+	RiskTrackMismatch:     0.6,
+}
+
+// TrackPoint 轨迹采样点
+type TrackPoint struct {
+	X float64 `json:"x"` // X 坐标
+	Y float64 `json:"y"` // Y 坐标
+	T int64   `json:"t"` // 时间戳（毫秒，可以是相对起点的偏移）
+}
+
+// TrackInput 滑动/旋转验证码携带轨迹的用户输入，JSON 格式：
+//
+//	{"value": 132, "track": [{"x": 0, "y": 0, "t": 0}, {"x": 3, "y": 1, "t": 16}, ...]}
+//
+// value 为最终位移（滑动）或角度（旋转）；不携带轨迹时仍可直接提交数值字符串。
+type TrackInput struct {
+	Value float64      `json:"value"` // 最终位移或角度
+	Track []TrackPoint `json:"track"` // 拖动轨迹
+}
+
+// TrackPolicy 轨迹校验阈值
+type TrackPolicy struct {
+	Required              bool          `json:"required"`                // 是否必须提交轨迹
+	MinPoints             int           `json:"min_points"`              // 最少采样点数
+	MinDuration           time.Duration `json:"min_duration"`            // 最短拖动耗时
+	MaxDuration           time.Duration `json:"max_duration"`            // 最长拖动耗时，0 表示不限制
+	MinVelocityCV         float64       `json:"min_velocity_cv"`         // 速度变异系数下限，低于此视为匀速
+	MinJitter             float64       `json:"min_jitter"`              // 平均抖动下限（像素）
+	StraightLineTolerance float64       `json:"straight_line_tolerance"` // 偏离起止连线的最大距离不超过该值时视为直线（像素）
+	ValueTolerance        float64       `json:"value_tolerance"`         // 轨迹水平位移与提交值的允许误差，0 表示不比较
+	MaxRisk               float64       `json:"max_risk"`                // 风险分超过该值判定失败
+}
+
+// DefaultTrackPolicy 返回驱动类型的默认轨迹阈值
+func DefaultTrackPolicy(driverType DriverType) *TrackPolicy {
+	p := &TrackPolicy{
+		Required:              true,
+		MinPoints:             8,
+		MinDuration:           300 * time.Millisecond,
+		MaxDuration:           15 * time.Second,
+		MinVelocityCV:         0.15,
+		MinJitter:             0.2,
+		StraightLineTolerance: 1,
+		MaxRisk:               0.5,
+	}
+	if driverType == DriverSlide {
+		// 滑块轨迹的水平位移即为提交的缺口位置
+		p.ValueTolerance = 10
+	}
+	return p
+}
+
+// TrackAnalysis 轨迹分析结果
+type TrackAnalysis struct {
+	Risk         float64       // 风险分 0~1
+	Reasons      []string      // 命中的风险项
+	Duration     time.Duration // 拖动耗时
+	VelocityCV   float64       // 速度变异系数
+	Jitter       float64       // 平均抖动（像素）
+	MaxDeviation float64       // 偏离起止连线的最大距离（像素）
+}
+
+// AnalyzeTrack 按策略对轨迹打分；value 为提交的最终位移或角度
+func AnalyzeTrack(points []TrackPoint, value float64, policy *TrackPolicy) *TrackAnalysis {
+	a := &TrackAnalysis{}
+	if policy == nil {
+		return a
+	}
+
+	if len(points) == 0 {
+		if policy.Required {
+			a.add(RiskMissingTrack)
+		}
+		return a
+	}
+	for i := 1; i < len(points); i++ {
+		if points[i].T < points[i-1].T {
+			a.add(RiskInvalidTimestamps)
+			return a
+		}
+	}
+
+	a.Duration = time.Duration(points[len(points)-1].T-points[0].T) * time.Millisecond
+	if len(points) < max(policy.MinPoints, 3) {
+		a.add(RiskTooFewPoints)
+	}
+	if a.Duration < policy.MinDuration {
+		a.add(RiskTooFast)
+	}
+	if policy.MaxDuration > 0 && a.Duration > policy.MaxDuration {
+		a.add(RiskTooSlow)
+	}
+	if policy.ValueTolerance > 0 && math.Abs(points[len(points)-1].X-points[0].X-value) > policy.ValueTolerance {
+		a.add(RiskTrackMismatch)
+	}
+	if len(points) < 3 {
+		return a
+	}
+
+	speeds := trackSpeeds(points)
+	if len(speeds) >= 2 {
+		mean, std := meanStd(speeds)
+		if mean > 0 {
+			a.VelocityCV = std / mean
+		}
+		if a.VelocityCV < policy.MinVelocityCV {
+			a.add(RiskConstantVelocity)
+		}
+		if !decelerates(speeds) {
+			a.add(RiskNoDeceleration)
+		}
+	}
+
+	a.MaxDeviation = maxDeviation(points)
+	if a.MaxDeviation <= policy.StraightLineTolerance {
+		a.add(RiskStraightLine)
+	}
+
+	a.Jitter = jitter(points)
+	if a.Jitter < policy.MinJitter {
+		a.add(RiskNoJitter)
+	}
+	return a
+}
+
+func (a *TrackAnalysis) add(reason string) {
+	a.Reasons = append(a.Reasons, reason)
+	a.Risk = min(a.Risk+riskWeights[reason], 1)
+}
+
+// parseTrackInput 解析用户输入：JSON 格式返回轨迹，否则与旧版一致只读取开头的整数，
+// 兼容 "132,40"、"132px" 等已有客户端的提交格式
+func parseTrackInput(userInput string) (*TrackInput, bool) {
+	s := strings.TrimSpace(userInput)
+	if strings.HasPrefix(s, "{") {
+		var in TrackInput
+		if err := json.Unmarshal([]byte(s), &in); err != nil {
+			return nil, false
+		}
+		return &in, true
+	}
+	var v int
+	if _, err := fmt.Sscanf(s, "%d", &v); err != nil {
+		return nil, false
+	}
+	return &TrackInput{Value: float64(v)}, true
+}
+
+// trackSpeeds 计算相邻采样点间的速度（像素/毫秒），跳过时间间隔为 0 的点
+func trackSpeeds(points []TrackPoint) []float64 {
+	speeds := make([]float64, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		dt := float64(points[i].T - points[i-1].T)
+		if dt <= 0 {
+			continue
+		}
+		speeds = append(speeds, math.Hypot(points[i].X-points[i-1].X, points[i].Y-points[i-1].Y)/dt)
+	}
+	return speeds
+}
+
+// decelerates 判断末段平均速度是否低于峰值所在的中段，人手拖动接近目标时会减速
+func decelerates(speeds []float64) bool {
+	tail := max(len(speeds)/5, 1)
+	tailMean, _ := meanStd(speeds[len(speeds)-tail:])
+	peak := 0.0
+	for _, v := range speeds[:len(speeds)-tail] {
+		peak = max(peak, v)
+	}
+	return tailMean < peak
+}
+
+// maxDeviation 计算各点到起止连线的最大距离
+func maxDeviation(points []TrackPoint) float64 {
+	first, last := points[0], points[len(points)-1]
+	dx, dy := last.X-first.X, last.Y-first.Y
+	length := math.Hypot(dx, dy)
+
+	var dev float64
+	for _, p := range points {
+		var d float64
+		if length == 0 {
+			d = math.Hypot(p.X-first.X, p.Y-first.Y)
+		} else {
+			d = math.Abs(dy*(p.X-first.X)-dx*(p.Y-first.Y)) / length
+		}
+		dev = max(dev, d)
+	}
+	return dev
+}
+
+// jitter 计算各点相对前后两点中点在起止连线法向上的平均偏移，
+// 平滑曲线（含缓动函数生成的轨迹）接近 0，人手拖动存在细微抖动
+func jitter(points []TrackPoint) float64 {
+	first, last := points[0], points[len(points)-1]
+	dx, dy := last.X-first.X, last.Y-first.Y
+	length := math.Hypot(dx, dy)
+
+	var sum float64
+	for i := 1; i < len(points)-1; i++ {
+		ox := points[i].X - (points[i-1].X+points[i+1].X)/2
+		oy := points[i].Y - (points[i-1].Y+points[i+1].Y)/2
+		if length == 0 {
+			sum += math.Hypot(ox, oy)
+		} else {
+			sum += math.Abs(dy*ox-dx*oy) / length
+		}
+	}
+	return sum / float64(len(points)-2)
+}
+
+func meanStd(values []float64) (mean, std float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		std += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(values)))
+}
@@ -0,0 +1,190 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// humanTrack 生成类人的拖动轨迹：先加速后减速，带有细微抖动与不均匀采样间隔
+func humanTrack(distance float64) []TrackPoint {
+	r := rand.New(rand.NewPCG(1, 2))
+	const steps = 60
+	points := make([]TrackPoint, 0, steps+1)
+	var ts int64
+	for i := 0; i <= steps; i++ {
+		p := float64(i) / steps
+		eased := (1 - math.Cos(p*math.Pi)) / 2
+		points = append(points, TrackPoint{
+			X: distance*eased + r.Float64() - 0.5,
+			Y: 2*math.Sin(p*math.Pi) + r.Float64() - 0.5,
+			T: ts,
+		})
+		ts += 12 + r.Int64N(10)
+	}
+	points[len(points)-1].X = distance
+	return points
+}
+
+// botTrack 生成匀速直线轨迹
+func botTrack(distance float64, duration time.Duration) []TrackPoint {
+	const steps = 20
+	points := make([]TrackPoint, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		points = append(points, TrackPoint{
+			X: distance * float64(i) / steps,
+			T: duration.Milliseconds() * int64(i) / steps,
+		})
+	}
+	return points
+}
+
+func TestAnalyzeTrack(t *testing.T) {
+	policy := DefaultTrackPolicy(DriverSlide)
+
+	t.Run("类人轨迹", func(t *testing.T) {
+		a := AnalyzeTrack(humanTrack(150), 150, policy)
+		assert.Empty(t, a.Reasons)
+		assert.Zero(t, a.Risk)
+		assert.Greater(t, a.VelocityCV, policy.MinVelocityCV)
+	})
+
+	t.Run("匀速直线", func(t *testing.T) {
+		a := AnalyzeTrack(botTrack(150, time.Second), 150, policy)
+		assert.Contains(t, a.Reasons, RiskConstantVelocity)
+		assert.Contains(t, a.Reasons, RiskStraightLine)
+		assert.Contains(t, a.Reasons, RiskNoJitter)
+		assert.Greater(t, a.Risk, policy.MaxRisk)
+	})
+
+	t.Run("耗时过短", func(t *testing.T) {
+		points := humanTrack(150)
+		for i := range points {
+			points[i].T /= 10
+		}
+		a := AnalyzeTrack(points, 150, policy)
+		assert.Contains(t, a.Reasons, RiskTooFast)
+	})
+
+	t.Run("时间戳倒退", func(t *testing.T) {
+		points := humanTrack(150)
+		points[10].T = points[9].T - 1
+		a := AnalyzeTrack(points, 150, policy)
+		assert.Equal(t, []string{RiskInvalidTimestamps}, a.Reasons)
+		assert.Equal(t, 1.0, a.Risk)
+	})
+
+	t.Run("轨迹与提交值不符", func(t *testing.T) {
+		a := AnalyzeTrack(humanTrack(150), 80, policy)
+		assert.Contains(t, a.Reasons, RiskTrackMismatch)
+	})
+
+	t.Run("缺少轨迹", func(t *testing.T) {
+		a := AnalyzeTrack(nil, 150, policy)
+		assert.Equal(t, []string{RiskMissingTrack}, a.Reasons)
+	})
+}
+
+func TestVerifyWithRisk_SlideTrack(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	defer store.Close()
+
+	captchaInstance := NewCaptchaWithStore(store,
+		WithDriverType(DriverSlide),
+		WithTrackPolicy(DriverSlide, nil),
+	)
+
+	input := func(value float64, track []TrackPoint) string {
+		b, err := json.Marshal(TrackInput{Value: value, Track: track})
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("机器人轨迹被拒绝", func(t *testing.T) {
+		require.NoError(t, captchaInstance.Save(ctx, "bot", "150"))
+		res, err := captchaInstance.VerifyWithRisk(ctx, "bot", input(150, botTrack(150, time.Second)))
+		require.NoError(t, err)
+		assert.True(t, res.Match)
+		assert.False(t, res.OK)
+		assert.Greater(t, res.Risk, 0.5)
+	})
+
+	t.Run("只提交数值被拒绝", func(t *testing.T) {
+		require.NoError(t, captchaInstance.Save(ctx, "plain", "150"))
+		ok, err := captchaInstance.Verify(ctx, "plain", "150")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("类人轨迹通过", func(t *testing.T) {
+		require.NoError(t, captchaInstance.Save(ctx, "human", "150"))
+		res, err := captchaInstance.VerifyWithRisk(ctx, "human", input(152, humanTrack(152)))
+		require.NoError(t, err)
+		assert.True(t, res.OK)
+		assert.Empty(t, res.Reasons)
+
+		exists, err := captchaInstance.Exists(ctx, "human")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestVerifyWithRisk_RotateWithoutPolicy(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	defer store.Close()
+
+	captchaInstance := NewCaptchaWithStore(store, WithDriverType(DriverRotate))
+	require.NoError(t, captchaInstance.Save(ctx, "id", "90"))
+
+	// 未配置轨迹策略时兼容 JSON 与纯数值输入
+	res, err := captchaInstance.VerifyWithRisk(ctx, "id", `{"value": 92}`)
+	require.NoError(t, err)
+	assert.True(t, res.OK)
+	assert.Zero(t, res.Risk)
+}
+
+func TestVerify_LegacyNumericInput(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	defer store.Close()
+
+	// 旧版按前导整数解析，已有客户端提交的这些格式必须继续可用
+	tests := []struct {
+		driver DriverType
+		answer string
+		input  string
+	}{
+		{DriverSlide, "132", "132"},
+		{DriverSlide, "132", "132,40"},
+		{DriverSlide, "132", "132px"},
+		{DriverSlide, "132", " 130 "},
+		{DriverSlide, "132", "134.9"},
+		{DriverRotate, "90", "90deg"},
+		{DriverRotate, "90", "88,0"},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%q", tt.driver, tt.input), func(t *testing.T) {
+			captchaInstance := NewCaptchaWithStore(store, WithDriverType(tt.driver))
+			id := fmt.Sprintf("legacy-%d", i)
+			require.NoError(t, captchaInstance.Save(ctx, id, tt.answer))
+			ok, err := captchaInstance.Verify(ctx, id, tt.input)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		})
+	}
+
+	captchaInstance := NewCaptchaWithStore(store, WithDriverType(DriverSlide))
+	require.NoError(t, captchaInstance.Save(ctx, "legacy-bad", "132"))
+	ok, err := captchaInstance.Verify(ctx, "legacy-bad", "px132")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,26 @@
+package captcha
+
+import (
+	"errors"
+	"image"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+)
+
+// vp8lMaxDimension VP8L 头部以 14 位记录宽高
+const vp8lMaxDimension = 1 << 14
+
+var errWebPTooLarge = errors.New("webp: image dimensions exceed 16384")
+
+// encodeWebP 将图片编码为 WebP：quality 为 1-99 时输出有损 VP8，否则使用纯 Go 的 nativewebp 输出无损 VP8L
+func encodeWebP(w io.Writer, img image.Image, quality int) error {
+	if quality > 0 && quality < 100 {
+		return encodeVP8(w, img, quality)
+	}
+	b := img.Bounds()
+	if b.Dx() > vp8lMaxDimension || b.Dy() > vp8lMaxDimension {
+		return errWebPTooLarge
+	}
+	return nativewebp.Encode(w, img, nil)
+}
@@ -0,0 +1,161 @@
+package captcha
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebP_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 300, 220))
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 23))
+	uniform := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	twoColor := image.NewNRGBA(image.Rect(0, 0, 9, 5))
+	for y := 0; y < 220; y++ {
+		for x := 0; x < 300; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{R: uint8(100 + x%50), G: uint8(150 + y%50), B: 200, A: 255})
+		}
+	}
+	for i := range noise.Pix {
+		noise.Pix[i] = uint8(r.IntN(256))
+	}
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			uniform.SetNRGBA(x, y, color.NRGBA{R: 7, G: 8, B: 9, A: 255})
+		}
+	}
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 9; x++ {
+			twoColor.SetNRGBA(x, y, color.NRGBA{A: uint8(255 * ((x + y) % 2))})
+		}
+	}
+
+	for name, img := range map[string]*image.NRGBA{
+		"gradient": gradient,
+		"noise":    noise,
+		"uniform":  uniform,
+		"twoColor": twoColor,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, encodeWebP(&buf, img, 0))
+
+			decoded, err := webp.Decode(&buf)
+			require.NoError(t, err)
+			require.Equal(t, img.Bounds(), decoded.Bounds())
+
+			for y := 0; y < img.Bounds().Dy(); y++ {
+				for x := 0; x < img.Bounds().Dx(); x++ {
+					want := img.NRGBAAt(x, y)
+					got := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+					if want.A == 0 {
+						assert.Zero(t, got.A)
+						continue
+					}
+					if !assert.Equal(t, want, got, "pixel (%d,%d)", x, y) {
+						return
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebP_Quality(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+
+	// 未设置质量或质量为 100 时输出无损 VP8L，1-99 输出有损 VP8
+	var lossless [][]byte
+	for quality, chunk := range map[int]string{0: "VP8L", 100: "VP8L", 1: "VP8X", 50: "VP8X", 99: "VP8X"} {
+		captchaInstance := newProtectedCaptcha(t, WithImageFormat(ImageFormatWebP, quality))
+		m, err := captchaInstance.encodeImage(img, ImageFormatPNG)
+		require.NoError(t, err)
+		require.Equal(t, "image/webp", m.ContentType)
+		require.True(t, len(m.Data) > 16)
+		assert.Equal(t, chunk, string(m.Data[12:16]), "quality %d", quality)
+		if chunk == "VP8L" {
+			lossless = append(lossless, m.Data)
+		}
+	}
+	assert.Equal(t, lossless[0], lossless[1])
+
+	var buf bytes.Buffer
+	assert.ErrorIs(t, encodeWebP(&buf, image.NewNRGBA(image.Rect(0, 0, vp8lMaxDimension+1, 1)), 0), errWebPTooLarge)
+	assert.ErrorIs(t, encodeWebP(&buf, image.NewNRGBA(image.Rect(0, 0, 1, vp8MaxDimension+1)), 80), errVP8TooLarge)
+}
+
+func TestEncodeWebP_Lossy(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 220))
+	for y := 0; y < 220; y++ {
+		for x := 0; x < 300; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x * y % 256), A: 255})
+		}
+	}
+	// 解码结果与编码前的亮度平面比较
+	ref := newVP8Encoder(300, 220, 0)
+	require.Nil(t, ref.importImage(img))
+
+	var prevSize int
+	prevPSNR := 0.0
+	for _, quality := range []int{10, 50, 90} {
+		var buf bytes.Buffer
+		require.NoError(t, encodeWebP(&buf, img, quality))
+		assert.Equal(t, "VP8 ", string(buf.Bytes()[12:16]))
+		size := buf.Len()
+
+		decoded, err := webp.Decode(&buf)
+		require.NoError(t, err)
+		yc, ok := decoded.(*image.YCbCr)
+		require.True(t, ok)
+		require.Equal(t, img.Bounds(), yc.Bounds())
+
+		var sse float64
+		for y := 0; y < 220; y++ {
+			for x := 0; x < 300; x++ {
+				d := float64(yc.Y[y*yc.YStride+x]) - float64(ref.srcY[y*ref.yStride+x])
+				sse += d * d
+			}
+		}
+		psnr := 10 * math.Log10(255*255/(sse/(300*220)))
+		assert.Greater(t, psnr, 28.0, "quality %d", quality)
+		assert.Greater(t, psnr, prevPSNR, "quality %d", quality)
+		assert.Greater(t, size, prevSize, "quality %d", quality)
+		prevSize, prevPSNR = size, psnr
+	}
+	assert.Greater(t, prevPSNR, 38.0)
+}
+
+func TestEncodeWebP_LossyAlpha(t *testing.T) {
+	// 宽高不是宏块的整数倍，且起点不在原点
+	img := image.NewNRGBA(image.Rect(3, 4, 3+37, 4+23))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 31)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, encodeWebP(&buf, img, 60))
+	decoded, err := webp.Decode(&buf)
+	require.NoError(t, err)
+	nyca, ok := decoded.(*image.NYCbCrA)
+	require.True(t, ok, "%T", decoded)
+	require.Equal(t, 37, nyca.Bounds().Dx())
+	require.Equal(t, 23, nyca.Bounds().Dy())
+
+	for y := 0; y < 23; y++ {
+		for x := 0; x < 37; x++ {
+			require.Equal(t, img.NRGBAAt(3+x, 4+y).A, nyca.A[y*nyca.AStride+x], "pixel (%d,%d)", x, y)
+		}
+	}
+}
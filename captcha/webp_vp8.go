@@ -0,0 +1,747 @@
+package captcha
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// 有损 WebP（VP8 关键帧）编码器。
+// 只使用 16x16 亮度与 8x8 色度帧内预测（在 DC/TM/V/H 中选误差最小的模式）、默认系数概率和单个系数分区，
+// 输出体积不如 libwebp，但不依赖 cgo。带透明通道的图片以未压缩的 ALPH 块保存 alpha。
+
+// vp8MaxDimension VP8 帧头以 14 位记录宽高
+const vp8MaxDimension = 1<<14 - 1
+
+var errVP8TooLarge = errors.New("webp: image dimensions exceed 16383")
+
+// 16x16 亮度与 8x8 色度的预测模式，取值与解码器一致
+const (
+	vp8PredDC = iota
+	vp8PredTM
+	vp8PredVE
+	vp8PredHE
+	vp8PredCount
+)
+
+var (
+	// vp8Bands 扫描位置到系数频带的映射
+	vp8Bands = [17]uint8{0, 1, 2, 3, 6, 4, 5, 6, 6, 6, 6, 6, 6, 6, 6, 7, 0}
+	// vp8Zigzag 扫描位置到 4x4 块内系数下标的映射
+	vp8Zigzag = [16]uint8{0, 1, 4, 8, 5, 2, 3, 6, 9, 12, 13, 10, 7, 11, 14, 15}
+	// vp8Cat3456 DCT_CAT3-6 额外位的概率
+	vp8Cat3456 = [4][]uint8{
+		{173, 148, 140},
+		{176, 155, 140, 135},
+		{180, 157, 141, 134, 130},
+		{254, 254, 243, 230, 196, 177, 153, 140, 133, 130, 129},
+	}
+)
+
+// 系数概率表的平面
+const (
+	vp8PlaneY1WithY2 = iota
+	vp8PlaneY2
+	vp8PlaneUV
+)
+
+// vp8MaxLevel DCT_CAT6 能表示的最大量化值
+const vp8MaxLevel = 2048
+
+// vp8BoolEncoder 布尔熵编码器（RFC 6386 7.3）
+type vp8BoolEncoder struct {
+	buf    []byte
+	rng    uint32
+	bottom uint32
+	count  int
+}
+
+func newVP8BoolEncoder() *vp8BoolEncoder {
+	return &vp8BoolEncoder{rng: 255, count: 24}
+}
+
+// putBit 以 prob/256 为 bit 等于 0 的概率写入一位
+func (e *vp8BoolEncoder) putBit(bit bool, prob uint8) {
+	split := 1 + ((e.rng-1)*uint32(prob))>>8
+	if bit {
+		e.bottom += split
+		e.rng -= split
+	} else {
+		e.rng = split
+	}
+	for e.rng < 128 {
+		e.rng <<= 1
+		if e.bottom&(1<<31) != 0 {
+			// 进位传递到已输出的字节
+			for i := len(e.buf) - 1; i >= 0; i-- {
+				e.buf[i]++
+				if e.buf[i] != 0 {
+					break
+				}
+			}
+		}
+		e.bottom <<= 1
+		if e.count--; e.count == 0 {
+			e.buf = append(e.buf, byte(e.bottom>>24))
+			e.bottom &= 1<<24 - 1
+			e.count = 8
+		}
+	}
+}
+
+// putLiteral 以均匀概率写入 n 位无符号整数，高位在前
+func (e *vp8BoolEncoder) putLiteral(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		e.putBit(v>>uint(i)&1 == 1, 128)
+	}
+}
+
+// finish 写入足够的填充位使所有有效位输出，返回编码结果
+func (e *vp8BoolEncoder) finish() []byte {
+	for range 32 {
+		e.putBit(false, 128)
+	}
+	return e.buf
+}
+
+// vp8Quant 各类系数的 DC、AC 量化步长
+type vp8Quant struct {
+	y1, y2, uv [2]int32
+}
+
+// newVP8Quant 按量化索引计算量化步长（RFC 6386 14.1）
+func newVP8Quant(q int) vp8Quant {
+	var m vp8Quant
+	m.y1 = [2]int32{int32(vp8DequantDC[q]), int32(vp8DequantAC[q])}
+	m.y2 = [2]int32{int32(vp8DequantDC[q]) * 2, max(int32(vp8DequantAC[q])*155/100, 8)}
+	m.uv = [2]int32{int32(vp8DequantDC[min(q, 117)]), int32(vp8DequantAC[q])}
+	return m
+}
+
+// vp8QualityIndex 将 1-99 的质量线性映射为 0-127 的量化索引，质量越高量化越细
+func vp8QualityIndex(quality int) int {
+	quality = min(max(quality, 1), 100)
+	return (100 - quality) * 127 / 99
+}
+
+// vp8Encoder 单帧编码状态，平面宽高补齐到宏块的整数倍
+type vp8Encoder struct {
+	mbw, mbh int
+	q        int // 量化索引
+	quant    vp8Quant
+
+	// 源图与重建图的 Y、U、V 平面
+	srcY, srcU, srcV []uint8
+	recY, recU, recV []uint8
+	yStride, cStride int
+
+	// 非零系数上下文：0-3 亮度、4-5 U、6-7 V、8 Y2
+	topNz  [][9]uint8
+	leftNz [9]uint8
+
+	hdr, tok *vp8BoolEncoder
+}
+
+// encodeVP8 将图片编码为有损 WebP，quality 取值 1-99
+func encodeVP8(w io.Writer, img image.Image, quality int) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width > vp8MaxDimension || height > vp8MaxDimension {
+		return errVP8TooLarge
+	}
+	if width == 0 || height == 0 {
+		return errors.New("webp: empty image")
+	}
+
+	e := newVP8Encoder(width, height, vp8QualityIndex(quality))
+	alpha := e.importImage(img)
+	frame, err := e.encodeFrame(width, height)
+	if err != nil {
+		return err
+	}
+
+	var chunks []byte
+	if alpha != nil {
+		vp8x := make([]byte, 10)
+		vp8x[0] = 0x10 // 含 alpha
+		putUint24(vp8x[4:], uint32(width-1))
+		putUint24(vp8x[7:], uint32(height-1))
+		chunks = appendChunk(chunks, "VP8X", vp8x)
+		chunks = appendChunk(chunks, "ALPH", append([]byte{0}, alpha...)) // 未压缩、无滤波
+	}
+	chunks = appendChunk(chunks, "VP8 ", frame)
+
+	out := make([]byte, 12, 12+len(chunks))
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(4+len(chunks)))
+	copy(out[8:], "WEBP")
+	_, err = w.Write(append(out, chunks...))
+	return err
+}
+
+func appendChunk(dst []byte, fourCC string, data []byte) []byte {
+	dst = append(dst, fourCC...)
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(data)))
+	dst = append(dst, data...)
+	if len(data)%2 == 1 {
+		dst = append(dst, 0)
+	}
+	return dst
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+func newVP8Encoder(width, height, q int) *vp8Encoder {
+	mbw, mbh := (width+15)/16, (height+15)/16
+	e := &vp8Encoder{
+		mbw:     mbw,
+		mbh:     mbh,
+		q:       q,
+		quant:   newVP8Quant(q),
+		yStride: 16 * mbw,
+		cStride: 8 * mbw,
+		topNz:   make([][9]uint8, mbw),
+		hdr:     newVP8BoolEncoder(),
+		tok:     newVP8BoolEncoder(),
+	}
+	e.srcY = make([]uint8, e.yStride*16*mbh)
+	e.srcU = make([]uint8, e.cStride*8*mbh)
+	e.srcV = make([]uint8, e.cStride*8*mbh)
+	e.recY = make([]uint8, len(e.srcY))
+	e.recU = make([]uint8, len(e.srcU))
+	e.recV = make([]uint8, len(e.srcV))
+	return e
+}
+
+// importImage 按 BT.601（16-235）转换为 YUV 4:2:0，补齐部分复制边缘像素；
+// 图片不透明时返回 nil，否则返回逐像素的 alpha
+func (e *vp8Encoder) importImage(img image.Image) []byte {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	alpha := make([]byte, width*height)
+	u, v := make([]int32, e.yStride*16*e.mbh), make([]int32, e.yStride*16*e.mbh)
+	for y := 0; y < 16*e.mbh; y++ {
+		sy := min(y, height-1)
+		for x := 0; x < e.yStride; x++ {
+			sx := min(x, width-1)
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+sx, b.Min.Y+sy)).(color.NRGBA)
+			r, g, bl := int32(c.R), int32(c.G), int32(c.B)
+			i := y*e.yStride + x
+			e.srcY[i] = uint8((16839*r + 33059*g + 6420*bl + 16<<16 + 1<<15) >> 16)
+			u[i] = -9719*r - 19081*g + 28800*bl
+			v[i] = 28800*r - 24116*g - 4684*bl
+			if x == sx && y == sy {
+				alpha[sy*width+sx] = c.A
+			}
+		}
+	}
+	for y := 0; y < 8*e.mbh; y++ {
+		for x := 0; x < e.cStride; x++ {
+			i := 2*y*e.yStride + 2*x
+			su := u[i] + u[i+1] + u[i+e.yStride] + u[i+e.yStride+1]
+			sv := v[i] + v[i+1] + v[i+e.yStride] + v[i+e.yStride+1]
+			e.srcU[y*e.cStride+x] = uint8((su + 128<<18 + 1<<17) >> 18)
+			e.srcV[y*e.cStride+x] = uint8((sv + 128<<18 + 1<<17) >> 18)
+		}
+	}
+
+	for _, a := range alpha {
+		if a != 0xff {
+			return alpha
+		}
+	}
+	return nil
+}
+
+// encodeFrame 编码关键帧，返回 VP8 块的内容
+func (e *vp8Encoder) encodeFrame(width, height int) ([]byte, error) {
+	h := e.hdr
+	h.putBit(false, 128)           // 色彩空间
+	h.putBit(false, 128)           // 像素截断
+	h.putBit(false, 128)           // 不分段
+	h.putBit(false, 128)           // 普通环路滤波
+	h.putLiteral(uint32(e.q/2), 6) // 滤波强度随量化加大
+	h.putLiteral(0, 3)             // 锐度
+	h.putBit(false, 128)           // 不按模式调整滤波
+	h.putLiteral(0, 2)             // 单个系数分区
+	h.putLiteral(uint32(e.q), 7)   // 量化索引
+	for range 5 {
+		h.putBit(false, 128) // 各类系数的量化索引不做调整
+	}
+	h.putBit(false, 128) // refresh_entropy_probs
+	for i := range vp8TokenUpdateProb {
+		for j := range vp8TokenUpdateProb[i] {
+			for k := range vp8TokenUpdateProb[i][j] {
+				for _, p := range vp8TokenUpdateProb[i][j][k] {
+					h.putBit(false, p) // 使用默认概率
+				}
+			}
+		}
+	}
+	h.putBit(false, 128) // 不使用宏块跳过标志
+
+	for mby := 0; mby < e.mbh; mby++ {
+		e.leftNz = [9]uint8{}
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+
+	first, tokens := e.hdr.finish(), e.tok.finish()
+	if len(first) >= 1<<19 {
+		return nil, errors.New("webp: first partition too large")
+	}
+	tag := uint32(1<<4) | uint32(len(first))<<5 // 关键帧、版本 0、显示
+	frame := make([]byte, 10, 10+len(first)+len(tokens))
+	putUint24(frame, tag)
+	frame[3], frame[4], frame[5] = 0x9d, 0x01, 0x2a
+	binary.LittleEndian.PutUint16(frame[6:], uint16(width))
+	binary.LittleEndian.PutUint16(frame[8:], uint16(height))
+	frame = append(frame, first...)
+	return append(frame, tokens...), nil
+}
+
+// vp8Border 宏块的上方行、左侧列与左上角，超出图片时使用解码器约定的常量
+type vp8Border struct {
+	top, left [16]uint8
+	corner    uint8
+}
+
+func (e *vp8Encoder) border(rec []uint8, stride, size, mbx, mby int) vp8Border {
+	var bd vp8Border
+	x0, y0 := mbx*size, mby*size
+	for i := 0; i < size; i++ {
+		bd.top[i], bd.left[i] = 127, 129
+		if mby > 0 {
+			bd.top[i] = rec[(y0-1)*stride+x0+i]
+		}
+		if mbx > 0 {
+			bd.left[i] = rec[(y0+i)*stride+x0-1]
+		}
+	}
+	switch {
+	case mby == 0:
+		bd.corner = 127
+	case mbx == 0:
+		bd.corner = 129
+	default:
+		bd.corner = rec[(y0-1)*stride+x0-1]
+	}
+	return bd
+}
+
+// predict 生成 size x size 的预测块，DC 模式在图片边缘只使用存在的一侧
+func predict(bd vp8Border, size, mode, mbx, mby int, out []uint8) {
+	switch mode {
+	case vp8PredDC:
+		var sum, n int
+		if mby > 0 {
+			for i := 0; i < size; i++ {
+				sum += int(bd.top[i])
+			}
+			n += size
+		}
+		if mbx > 0 {
+			for i := 0; i < size; i++ {
+				sum += int(bd.left[i])
+			}
+			n += size
+		}
+		dc := uint8(0x80)
+		if n > 0 {
+			dc = uint8((sum + n/2) / n)
+		}
+		for i := range out[:size*size] {
+			out[i] = dc
+		}
+	case vp8PredTM:
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				out[y*size+x] = clip8(int32(bd.left[y]) + int32(bd.top[x]) - int32(bd.corner))
+			}
+		}
+	case vp8PredVE:
+		for y := 0; y < size; y++ {
+			copy(out[y*size:], bd.top[:size])
+		}
+	case vp8PredHE:
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				out[y*size+x] = bd.left[y]
+			}
+		}
+	}
+}
+
+// bestPrediction 选择与源图平方误差最小的预测模式，planes 为需要共用同一模式的平面
+func (e *vp8Encoder) bestPrediction(size, mbx, mby int, planes ...vp8Plane) (int, [][]uint8) {
+	best, bestErr := 0, -1
+	var bestPred [][]uint8
+	for mode := range vp8PredCount {
+		preds := make([][]uint8, len(planes))
+		var sse int
+		for i, p := range planes {
+			preds[i] = make([]uint8, size*size)
+			predict(e.border(p.rec, p.stride, size, mbx, mby), size, mode, mbx, mby, preds[i])
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					d := int(p.src[(mby*size+y)*p.stride+mbx*size+x]) - int(preds[i][y*size+x])
+					sse += d * d
+				}
+			}
+		}
+		if bestErr < 0 || sse < bestErr {
+			best, bestErr, bestPred = mode, sse, preds
+		}
+	}
+	return best, bestPred
+}
+
+type vp8Plane struct {
+	src, rec []uint8
+	stride   int
+}
+
+// encodeMacroblock 编码一个宏块：写入预测模式与残差系数，并按解码器的方式重建像素
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int) {
+	luma := vp8Plane{e.srcY, e.recY, e.yStride}
+	yMode, yPred := e.bestPrediction(16, mbx, mby, luma)
+	cMode, cPred := e.bestPrediction(8, mbx, mby,
+		vp8Plane{e.srcU, e.recU, e.cStride}, vp8Plane{e.srcV, e.recV, e.cStride})
+
+	e.hdr.putBit(true, 145) // 16x16 亮度预测
+	switch yMode {
+	case vp8PredDC:
+		e.hdr.putBit(false, 156)
+		e.hdr.putBit(false, 163)
+	case vp8PredVE:
+		e.hdr.putBit(false, 156)
+		e.hdr.putBit(true, 163)
+	case vp8PredHE:
+		e.hdr.putBit(true, 156)
+		e.hdr.putBit(false, 128)
+	case vp8PredTM:
+		e.hdr.putBit(true, 156)
+		e.hdr.putBit(true, 128)
+	}
+	e.hdr.putBit(cMode != vp8PredDC, 142)
+	if cMode != vp8PredDC {
+		e.hdr.putBit(cMode != vp8PredVE, 114)
+		if cMode != vp8PredVE {
+			e.hdr.putBit(cMode == vp8PredTM, 183)
+		}
+	}
+
+	top := &e.topNz[mbx]
+
+	// 亮度：16 个 4x4 块的 DC 经 WHT 合并为 Y2 块
+	var coeffs [16][16]int32
+	var dc [16]int32
+	for n := range 16 {
+		bx, by := n%4*4, n/4*4
+		var res [16]int32
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				src := e.srcY[(mby*16+by+y)*e.yStride+mbx*16+bx+x]
+				res[y*4+x] = int32(src) - int32(yPred[0][(by+y)*16+bx+x])
+			}
+		}
+		coeffs[n] = fdct4(res)
+		dc[n] = coeffs[n][0]
+	}
+	y2 := quantize(fwht4(dc), e.quant.y2, 0)
+	nz := e.putCoeffs(y2, vp8PlaneY2, top[8]+e.leftNz[8], 0)
+	top[8], e.leftNz[8] = nz, nz
+
+	dcRec := iwht4(dequantize(y2, e.quant.y2))
+	for n := range 16 {
+		bx, by := n%4, n/4
+		levels := quantize(coeffs[n], e.quant.y1, 1)
+		nz := e.putCoeffs(levels, vp8PlaneY1WithY2, top[bx]+e.leftNz[by], 1)
+		top[bx], e.leftNz[by] = nz, nz
+
+		rec := dequantize(levels, e.quant.y1)
+		rec[0] = dcRec[n]
+		e.reconstruct(e.recY, e.yStride, mbx*16+bx*4, mby*16+by*4, yPred[0][by*4*16+bx*4:], 16, rec)
+	}
+
+	// 色度：U、V 各 4 个 4x4 块
+	for c, rec := range [][]uint8{e.recU, e.recV} {
+		src := [][]uint8{e.srcU, e.srcV}[c]
+		for n := range 4 {
+			bx, by := n%2, n/2
+			var res [16]int32
+			for y := 0; y < 4; y++ {
+				for x := 0; x < 4; x++ {
+					s := src[(mby*8+by*4+y)*e.cStride+mbx*8+bx*4+x]
+					res[y*4+x] = int32(s) - int32(cPred[c][(by*4+y)*8+bx*4+x])
+				}
+			}
+			levels := quantize(fdct4(res), e.quant.uv, 0)
+			ti, li := 4+2*c+bx, 4+2*c+by
+			nz := e.putCoeffs(levels, vp8PlaneUV, top[ti]+e.leftNz[li], 0)
+			top[ti], e.leftNz[li] = nz, nz
+			e.reconstruct(rec, e.cStride, mbx*8+bx*4, mby*8+by*4, cPred[c][by*4*8+bx*4:], 8, dequantize(levels, e.quant.uv))
+		}
+	}
+}
+
+// reconstruct 预测值加上反变换后的残差，写入重建平面
+func (e *vp8Encoder) reconstruct(dst []uint8, stride, x0, y0 int, pred []uint8, predStride int, coeffs [16]int32) {
+	res := idct4(coeffs)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			dst[(y0+y)*stride+x0+x] = clip8(int32(pred[y*predStride+x]) + res[y*4+x])
+		}
+	}
+}
+
+// putCoeffs 按 RFC 6386 13 写入一个 4x4 块的量化系数，返回块内是否有非零系数
+func (e *vp8Encoder) putCoeffs(levels [16]int32, plane int, ctx uint8, first int) uint8 {
+	probs := &vp8DefaultTokenProb[plane]
+	last := -1
+	for n := 15; n >= first; n-- {
+		if levels[vp8Zigzag[n]] != 0 {
+			last = n
+			break
+		}
+	}
+
+	n := first
+	p := probs[vp8Bands[n]][ctx]
+	if last < 0 {
+		e.tok.putBit(false, p[0])
+		return 0
+	}
+	e.tok.putBit(true, p[0])
+	for n < 16 {
+		c := levels[vp8Zigzag[n]]
+		n++
+		if c == 0 {
+			e.tok.putBit(false, p[1])
+			p = probs[vp8Bands[n]][0]
+			continue
+		}
+		e.tok.putBit(true, p[1])
+		v := min(abs32(c), vp8MaxLevel)
+		if v == 1 {
+			e.tok.putBit(false, p[2])
+			p = probs[vp8Bands[n]][1]
+		} else {
+			e.tok.putBit(true, p[2])
+			e.putLevel(v, p)
+			p = probs[vp8Bands[n]][2]
+		}
+		e.tok.putBit(c < 0, 128)
+		if n == 16 {
+			break
+		}
+		e.tok.putBit(n <= last, p[0])
+		if n > last {
+			break
+		}
+	}
+	return 1
+}
+
+// putLevel 写入大于 1 的系数绝对值
+func (e *vp8Encoder) putLevel(v int32, p [11]uint8) {
+	t := e.tok
+	switch {
+	case v <= 4:
+		t.putBit(false, p[3])
+		t.putBit(v != 2, p[4])
+		if v != 2 {
+			t.putBit(v == 4, p[5])
+		}
+	case v <= 10:
+		t.putBit(true, p[3])
+		t.putBit(false, p[6])
+		if v <= 6 {
+			t.putBit(false, p[7])
+			t.putBit(v == 6, 159)
+		} else {
+			t.putBit(true, p[7])
+			t.putBit((v-7)&2 != 0, 165)
+			t.putBit((v-7)&1 != 0, 145)
+		}
+	default:
+		t.putBit(true, p[3])
+		t.putBit(true, p[6])
+		cat := 3
+		switch {
+		case v < 19:
+			cat = 0
+		case v < 35:
+			cat = 1
+		case v < 67:
+			cat = 2
+		}
+		t.putBit(cat >= 2, p[8])
+		t.putBit(cat&1 == 1, p[9+cat/2])
+		extra := v - 3 - 8<<cat
+		tab := vp8Cat3456[cat]
+		for i, prob := range tab {
+			t.putBit(extra>>uint(len(tab)-1-i)&1 == 1, prob)
+		}
+	}
+}
+
+// quantize 量化 4x4 块的系数，first 之前的系数置零；AC 系数带死区以减小体积
+func quantize(c [16]int32, q [2]int32, first int) [16]int32 {
+	var out [16]int32
+	for i := first; i < 16; i++ {
+		step := q[min(i, 1)]
+		bias := step / 2
+		if i > 0 {
+			bias = step / 3
+		}
+		v := min((abs32(c[i])+bias)/step, vp8MaxLevel)
+		if c[i] < 0 {
+			v = -v
+		}
+		out[i] = v
+	}
+	return out
+}
+
+func dequantize(levels [16]int32, q [2]int32) [16]int32 {
+	var out [16]int32
+	for i, v := range levels {
+		out[i] = v * q[min(i, 1)]
+	}
+	return out
+}
+
+// fdct4 4x4 正向 DCT，与 libvpx 的 vp8_short_fdct4x4_c 一致
+func fdct4(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		r := in[i*4:]
+		a1 := (r[0] + r[3]) * 8
+		b1 := (r[1] + r[2]) * 8
+		c1 := (r[1] - r[2]) * 8
+		d1 := (r[0] - r[3]) * 8
+		tmp[i*4+0] = a1 + b1
+		tmp[i*4+2] = a1 - b1
+		tmp[i*4+1] = (c1*2217 + d1*5352 + 14500) >> 12
+		tmp[i*4+3] = (d1*2217 - c1*5352 + 7500) >> 12
+	}
+	for i := 0; i < 4; i++ {
+		a1 := tmp[i] + tmp[12+i]
+		b1 := tmp[4+i] + tmp[8+i]
+		c1 := tmp[4+i] - tmp[8+i]
+		d1 := tmp[i] - tmp[12+i]
+		out[i] = (a1 + b1 + 7) >> 4
+		out[8+i] = (a1 - b1 + 7) >> 4
+		out[4+i] = (c1*2217 + d1*5352 + 12000) >> 16
+		if d1 != 0 {
+			out[4+i]++
+		}
+		out[12+i] = (d1*2217 - c1*5352 + 51000) >> 16
+	}
+	return out
+}
+
+// idct4 4x4 反向 DCT，与解码器一致
+func idct4(in [16]int32) [16]int32 {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2)
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2)
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := in[i] + in[8+i]
+		b := in[i] - in[8+i]
+		c := (in[4+i]*c2)>>16 - (in[12+i]*c1)>>16
+		d := (in[4+i]*c1)>>16 + (in[12+i]*c2)>>16
+		m[i] = [4]int32{a + d, b + c, b - c, a - d}
+	}
+	var out [16]int32
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		out[j*4+0] = (a + d) >> 3
+		out[j*4+1] = (b + c) >> 3
+		out[j*4+2] = (b - c) >> 3
+		out[j*4+3] = (a - d) >> 3
+	}
+	return out
+}
+
+// fwht4 16 个 DC 系数的正向 Walsh-Hadamard 变换，与 libvpx 的 vp8_short_walsh4x4_c 一致
+func fwht4(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		r := in[i*4:]
+		a1 := (r[0] + r[2]) * 4
+		d1 := (r[1] + r[3]) * 4
+		c1 := (r[1] - r[3]) * 4
+		b1 := (r[0] - r[2]) * 4
+		tmp[i*4+0] = a1 + d1
+		if a1 != 0 {
+			tmp[i*4+0]++
+		}
+		tmp[i*4+1] = b1 + c1
+		tmp[i*4+2] = b1 - c1
+		tmp[i*4+3] = a1 - d1
+	}
+	for i := 0; i < 4; i++ {
+		a1 := tmp[i] + tmp[8+i]
+		d1 := tmp[4+i] + tmp[12+i]
+		c1 := tmp[4+i] - tmp[12+i]
+		b1 := tmp[i] - tmp[8+i]
+		for k, v := range [4]int32{a1 + d1, b1 + c1, b1 - c1, a1 - d1} {
+			if v < 0 {
+				v++
+			}
+			out[4*k+i] = (v + 3) >> 3
+		}
+	}
+	return out
+}
+
+// iwht4 反向 Walsh-Hadamard 变换，与解码器一致
+func iwht4(in [16]int32) [16]int32 {
+	var m, out [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := in[i] + in[12+i]
+		a1 := in[4+i] + in[8+i]
+		a2 := in[4+i] - in[8+i]
+		a3 := in[i] - in[12+i]
+		m[i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[i*4] + 3
+		a0 := dc + m[i*4+3]
+		a1 := m[i*4+1] + m[i*4+2]
+		a2 := m[i*4+1] - m[i*4+2]
+		a3 := dc - m[i*4+3]
+		out[i*4+0] = (a0 + a1) >> 3
+		out[i*4+1] = (a3 + a2) >> 3
+		out[i*4+2] = (a0 - a1) >> 3
+		out[i*4+3] = (a3 - a2) >> 3
+	}
+	return out
+}
+
+func clip8(v int32) uint8 {
+	return uint8(min(max(v, 0), 255))
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -179,6 +179,11 @@ func (a *AESGCMCipher) Decrypt(ciphertext []byte) ([]byte, error) {
 	return plain, nil
 }
 
+// NonceSize 返回密文前部 nonce 的长度
+func (a *AESGCMCipher) NonceSize() int {
+	return 12
+}
+
 func (a *AESGCMCipher) Name() string {
 	return "AES-GCM"
 }
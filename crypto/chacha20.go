@@ -0,0 +1,64 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// XChaCha20Poly1305Cipher 实现 Cipher 接口，使用 XChaCha20-Poly1305 认证加密
+// 24 字节随机 nonce 可放心随机生成，适合同一密钥加密大量消息
+type XChaCha20Poly1305Cipher struct {
+	key []byte
+}
+
+// NewXChaCha20Poly1305Cipher 创建XChaCha20-Poly1305的Cipher，密钥长度为32字节
+func NewXChaCha20Poly1305Cipher(key []byte) (*XChaCha20Poly1305Cipher, error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("invalid key length: %d, must be %d bytes", len(key), chacha20poly1305.KeySize)
+	}
+	return &XChaCha20Poly1305Cipher{key: key}, nil
+}
+
+// GenerateXChaCha20Key 生成随机XChaCha20-Poly1305密钥
+func GenerateXChaCha20Key() ([]byte, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	_, err := rand.Read(key)
+	return key, err
+}
+
+// Encrypt 加密，输出格式: nonce(24)|ciphertext|tag(16)
+func (c *XChaCha20Poly1305Cipher) Encrypt(plain []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(c.key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+// Decrypt 解密
+func (c *XChaCha20Poly1305Cipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(c.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+}
+
+// NonceSize 返回密文前部 nonce 的长度
+func (c *XChaCha20Poly1305Cipher) NonceSize() int {
+	return chacha20poly1305.NonceSizeX
+}
+
+// Name 返回算法名称
+func (c *XChaCha20Poly1305Cipher) Name() string {
+	return "XChaCha20-Poly1305"
+}
@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXChaCha20Poly1305Cipher_EncryptDecrypt(t *testing.T) {
+	key, err := GenerateXChaCha20Key()
+	require.NoError(t, err)
+	cipher, err := NewXChaCha20Poly1305Cipher(key)
+	require.NoError(t, err)
+
+	for _, plain := range [][]byte{{}, []byte("hello, xchacha20-poly1305!")} {
+		crypted, err := cipher.Encrypt(plain)
+		require.NoError(t, err)
+		assert.Len(t, crypted, 24+len(plain)+16)
+
+		decrypted, err := cipher.Decrypt(crypted)
+		require.NoError(t, err)
+		assert.Equal(t, len(plain), len(decrypted))
+		assert.Equal(t, string(plain), string(decrypted))
+	}
+
+	c1, _ := cipher.Encrypt([]byte("same"))
+	c2, _ := cipher.Encrypt([]byte("same"))
+	assert.NotEqual(t, c1, c2)
+}
+
+func TestXChaCha20Poly1305Cipher_Invalid(t *testing.T) {
+	_, err := NewXChaCha20Poly1305Cipher(make([]byte, 16))
+	assert.Error(t, err)
+
+	key, _ := GenerateXChaCha20Key()
+	cipher, _ := NewXChaCha20Poly1305Cipher(key)
+	crypted, _ := cipher.Encrypt([]byte("authenticated"))
+	crypted[len(crypted)-1] ^= 1
+	_, err = cipher.Decrypt(crypted)
+	assert.Error(t, err)
+	_, err = cipher.Decrypt(crypted[:30])
+	assert.Error(t, err)
+
+	other, _ := GenerateXChaCha20Key()
+	otherCipher, _ := NewXChaCha20Poly1305Cipher(other)
+	crypted, _ = cipher.Encrypt([]byte("wrong key"))
+	_, err = otherCipher.Decrypt(crypted)
+	assert.Error(t, err)
+}
@@ -34,8 +34,16 @@ func NewECDSACipher() (*ECDSACipher, error) {
 	}, nil
 }
 
+// NewECDSACipherFromKey 用已有密钥初始化
+func NewECDSACipherFromKey(priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey) *ECDSACipher {
+	return &ECDSACipher{privateKey: priv, publicKey: pub}
+}
+
 // Sign 签名
 func (e *ECDSACipher) Sign(data []byte) (string, error) {
+	if e.privateKey == nil {
+		return "", errors.New("private key is nil")
+	}
 	hash := sha256.Sum256(data)
 	r, s, err := ecdsa.Sign(rand.Reader, e.privateKey, hash[:])
 	if err != nil {
@@ -75,6 +83,9 @@ func (e *ECDSACipher) PublicKeyBytes() []byte {
 }
 func (e *ECDSACipher) PrivateKey() *ecdsa.PrivateKey { return e.privateKey }
 
+// PublicKey 获取公钥
+func (e *ECDSACipher) PublicKey() *ecdsa.PublicKey { return e.publicKey }
+
 // Name 返回算法名称
 func (e *ECDSACipher) Name() string {
 	return "ECDSA"
@@ -107,7 +118,10 @@ func (e *ECDHCipher) PublicKeyBytes() []byte {
 
 // DeriveSharedSecret 计算共享密钥
 func (e *ECDHCipher) DeriveSharedSecret(peerPubBytes []byte) ([]byte, error) {
-	peerPub, err := ecdh.P256().NewPublicKey(peerPubBytes)
+	if e.privateKey == nil {
+		return nil, errors.New("private key is nil")
+	}
+	peerPub, err := e.privateKey.Curve().NewPublicKey(peerPubBytes)
 	if err != nil {
 		return nil, fmt.Errorf("ecdh public key: %w", err)
 	}
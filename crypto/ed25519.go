@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+// Ed25519Cipher 实现 Signer/Verifier 接口，使用 Ed25519 签名
+// 签名确定性、无需随机数，推荐作为新系统的默认签名算法
+type Ed25519Cipher struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewEd25519Cipher 生成新的Ed25519密钥对
+func NewEd25519Cipher() (*Ed25519Cipher, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Ed25519Cipher{privateKey: priv, publicKey: pub}, nil
+}
+
+// NewEd25519CipherFromKey 用已有密钥初始化，只有公钥时仅可验签
+func NewEd25519CipherFromKey(priv ed25519.PrivateKey, pub ed25519.PublicKey) *Ed25519Cipher {
+	if pub == nil && priv != nil {
+		pub = priv.Public().(ed25519.PublicKey)
+	}
+	return &Ed25519Cipher{privateKey: priv, publicKey: pub}
+}
+
+// Sign 使用私钥签名，返回base64编码的签名
+func (e *Ed25519Cipher) Sign(data []byte) (string, error) {
+	if len(e.privateKey) != ed25519.PrivateKeySize {
+		return "", errors.New("private key is nil")
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(e.privateKey, data)), nil
+}
+
+// Verify 使用公钥验签
+func (e *Ed25519Cipher) Verify(data []byte, signature string) (bool, error) {
+	if len(e.publicKey) != ed25519.PublicKeySize {
+		return false, errors.New("public key is nil")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, err
+	}
+	return ed25519.Verify(e.publicKey, data, sig), nil
+}
+
+// PublicKey 获取公钥（用于导出、传输给对方）
+func (e *Ed25519Cipher) PublicKey() ed25519.PublicKey { return e.publicKey }
+
+// PrivateKey 获取私钥（仅用于安全存储，禁止对外泄露）
+func (e *Ed25519Cipher) PrivateKey() ed25519.PrivateKey { return e.privateKey }
+
+// Name 返回算法名称
+func (e *Ed25519Cipher) Name() string {
+	return "Ed25519"
+}
@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEd25519Cipher_SignVerify(t *testing.T) {
+	cipher, err := NewEd25519Cipher()
+	require.NoError(t, err)
+
+	data := []byte("hello, ed25519!")
+	sig, err := cipher.Sign(data)
+	require.NoError(t, err)
+
+	ok, err := cipher.Verify(data, sig)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = cipher.Verify([]byte("tampered"), sig)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 只有公钥时仅可验签
+	verifier := NewEd25519CipherFromKey(nil, cipher.PublicKey())
+	ok, err = verifier.Verify(data, sig)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, err = verifier.Sign(data)
+	assert.Error(t, err)
+}
+
+func TestEd25519Cipher_RFC8032(t *testing.T) {
+	// RFC 8032 第 7.1 节 TEST 1
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	cipher := NewEd25519CipherFromKey(ed25519.NewKeyFromSeed(seed), nil)
+	assert.Equal(t, "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a", hex.EncodeToString(cipher.PublicKey()))
+
+	sig, err := cipher.Sign(nil)
+	require.NoError(t, err)
+	raw, _ := base64.StdEncoding.DecodeString(sig)
+	assert.Equal(t, "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b", hex.EncodeToString(raw))
+}
+
+func TestEd25519Cipher_JWSAndKeys(t *testing.T) {
+	cipher, err := NewEd25519Cipher()
+	require.NoError(t, err)
+
+	token, err := SignJWS(cipher, []byte(`{"sub":"alice"}`))
+	require.NoError(t, err)
+	header, err := ParseJOSEHeader(token)
+	require.NoError(t, err)
+	assert.Equal(t, AlgEdDSA, header.Alg)
+
+	pem, err := ExportPublicKey(cipher)
+	require.NoError(t, err)
+	verifier, err := LoadEd25519Cipher(pem, nil)
+	require.NoError(t, err)
+	payload, _, err := VerifyJWS(verifier, token)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"sub":"alice"}`), payload)
+
+	pem, err = ExportPrivateKey(cipher)
+	require.NoError(t, err)
+	loaded, err := LoadEd25519Cipher(pem, nil)
+	require.NoError(t, err)
+	assert.Equal(t, cipher.PrivateKey(), loaded.PrivateKey())
+}
@@ -0,0 +1,187 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// 信封加密密文格式：
+//
+//	version(1) | algorithm(1) | kekNameLen(1) | kekName | wrappedLen(2, 大端) | wrappedKey | nonce(12) | ciphertext
+//
+// 每次加密生成随机数据密钥（DEK）加密数据，DEK 由密钥加密密钥（KEK）包裹后随密文保存。
+// 仅 version 与 algorithm 作为附加数据参与认证，替换包裹的 DEK 会使解密失败，
+// 因此 Rewrap 只需重新包裹 DEK 而无需重新加密数据。
+const envelopeVersion = 1
+
+var ErrKEKMismatch = errors.New("crypto: key-encryption key mismatch")
+
+// EnvelopeOption 信封加密选项
+type EnvelopeOption func(*Envelope)
+
+// WithEnvelopeAlgorithm 设置加密数据使用的算法，默认 AES-GCM（256 位数据密钥）
+func WithEnvelopeAlgorithm(alg AEADAlgorithm) EnvelopeOption {
+	return func(e *Envelope) {
+		e.algorithm = alg
+	}
+}
+
+// Envelope 信封加密，KEK 可以是 RSACipher、SM2Cipher、AESGCMCipher 或 KeyRing 等任意 Cipher
+type Envelope struct {
+	kek       Cipher
+	algorithm AEADAlgorithm
+}
+
+// NewEnvelope 创建信封加密实例
+func NewEnvelope(kek Cipher, opts ...EnvelopeOption) (*Envelope, error) {
+	if kek == nil {
+		return nil, errors.New("kek is nil")
+	}
+	e := &Envelope{kek: kek, algorithm: AEADAESGCM}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.algorithm != AEADAESGCM && e.algorithm != AEADSM4GCM {
+		return nil, fmt.Errorf("unsupported algorithm: %s", e.algorithm)
+	}
+	if len(kek.Name()) > 255 {
+		return nil, errors.New("kek name too long")
+	}
+	return e, nil
+}
+
+// Encrypt 生成数据密钥加密明文，并用 KEK 包裹数据密钥
+func (e *Envelope) Encrypt(plain []byte) ([]byte, error) {
+	dek := make([]byte, e.algorithm.KeySize())
+	if _, err := rand.Read(dek); err != nil {
+		return nil, err
+	}
+	wrapped, err := e.kek.Encrypt(dek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return e.seal(e.algorithm, dek, wrapped, plain)
+}
+
+// Decrypt 用 KEK 解开数据密钥后解密
+func (e *Envelope) Decrypt(ciphertext []byte) ([]byte, error) {
+	env, err := e.parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := e.kek.Decrypt(env.wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return env.open(dek)
+}
+
+// Rewrap 仅重新包裹数据密钥而不重新加密数据，KEK 为 KeyRing 时可将密文迁移到激活密钥
+func (e *Envelope) Rewrap(ciphertext []byte) ([]byte, error) {
+	env, err := e.parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if ring, ok := e.kek.(*KeyRing); ok {
+		if id, err := KeyIDOf(env.wrapped); err == nil && id == ring.ActiveKeyID() {
+			return ciphertext, nil
+		}
+	}
+
+	dek, err := e.kek.Decrypt(env.wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	wrapped, err := e.kek.Encrypt(dek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	header, err := e.header(env.algorithm, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, env.body...), nil
+}
+
+// Name 返回算法名称
+func (e *Envelope) Name() string {
+	return "Envelope(" + e.algorithm.String() + "/" + e.kek.Name() + ")"
+}
+
+func (e *Envelope) seal(alg AEADAlgorithm, dek, wrapped, plain []byte) ([]byte, error) {
+	aead, err := newAEAD(alg, dek)
+	if err != nil {
+		return nil, err
+	}
+	out, err := e.header(alg, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize := len(out)
+	out = append(out, make([]byte, aead.NonceSize())...)
+	nonce := out[headerSize:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, plain, out[:2]), nil
+}
+
+// header 构造包含 KEK 名称与包裹后 DEK 的头部
+func (e *Envelope) header(alg AEADAlgorithm, wrapped []byte) ([]byte, error) {
+	if len(wrapped) > 0xffff {
+		return nil, errors.New("wrapped data key too long")
+	}
+	name := e.kek.Name()
+	out := make([]byte, 0, 5+len(name)+len(wrapped)+64)
+	out = append(out, envelopeVersion, byte(alg), byte(len(name)))
+	out = append(out, name...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(wrapped)))
+	return append(out, wrapped...), nil
+}
+
+type envelope struct {
+	algorithm AEADAlgorithm
+	wrapped   []byte
+	header    []byte
+	body      []byte
+}
+
+func (e *Envelope) parse(ciphertext []byte) (*envelope, error) {
+	if len(ciphertext) < 3 || ciphertext[0] != envelopeVersion {
+		return nil, ErrInvalidCiphertext
+	}
+	nameLen := int(ciphertext[2])
+	if len(ciphertext) < 5+nameLen {
+		return nil, ErrInvalidCiphertext
+	}
+	if name := string(ciphertext[3 : 3+nameLen]); name != e.kek.Name() {
+		return nil, fmt.Errorf("%w: ciphertext wrapped by %s, got %s", ErrKEKMismatch, name, e.kek.Name())
+	}
+	wrappedLen := int(binary.BigEndian.Uint16(ciphertext[3+nameLen:]))
+	headerSize := 5 + nameLen + wrappedLen
+	if len(ciphertext) < headerSize {
+		return nil, ErrInvalidCiphertext
+	}
+	return &envelope{
+		algorithm: AEADAlgorithm(ciphertext[1]),
+		wrapped:   ciphertext[5+nameLen : headerSize],
+		header:    ciphertext[:headerSize],
+		body:      ciphertext[headerSize:],
+	}, nil
+}
+
+// open 使用数据密钥解密正文
+func (env *envelope) open(dek []byte) ([]byte, error) {
+	aead, err := newAEAD(env.algorithm, dek)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := aead.NonceSize()
+	if len(env.body) < nonceSize+aead.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+	return aead.Open(nil, env.body[:nonceSize], env.body[nonceSize:], env.header[:2])
+}
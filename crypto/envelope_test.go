@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope_KEKs(t *testing.T) {
+	rsaCipher, err := NewRSACipher(2048)
+	require.NoError(t, err)
+	sm2Cipher, err := NewSM2Cipher()
+	require.NoError(t, err)
+	aesKey, _ := GenerateAESKey(32)
+	aesCipher, err := NewAESGCMCipher(aesKey)
+	require.NoError(t, err)
+
+	plain := []byte("hello, envelope encryption!")
+	for _, kek := range []Cipher{rsaCipher, sm2Cipher, aesCipher} {
+		for _, alg := range []AEADAlgorithm{AEADAESGCM, AEADSM4GCM} {
+			env, err := NewEnvelope(kek, WithEnvelopeAlgorithm(alg))
+			require.NoError(t, err)
+
+			t.Run(env.Name(), func(t *testing.T) {
+				ciphertext, err := env.Encrypt(plain)
+				require.NoError(t, err)
+
+				decrypted, err := env.Decrypt(ciphertext)
+				require.NoError(t, err)
+				assert.Equal(t, plain, decrypted)
+
+				ciphertext[len(ciphertext)-1] ^= 1
+				_, err = env.Decrypt(ciphertext)
+				assert.Error(t, err)
+			})
+		}
+	}
+}
+
+func TestEnvelope_KEKMismatch(t *testing.T) {
+	rsaCipher, err := NewRSACipher(2048)
+	require.NoError(t, err)
+	sm2Cipher, err := NewSM2Cipher()
+	require.NoError(t, err)
+
+	rsaEnv, _ := NewEnvelope(rsaCipher)
+	sm2Env, _ := NewEnvelope(sm2Cipher)
+
+	ciphertext, err := rsaEnv.Encrypt([]byte("data"))
+	require.NoError(t, err)
+	_, err = sm2Env.Decrypt(ciphertext)
+	assert.ErrorIs(t, err, ErrKEKMismatch)
+}
+
+func TestEnvelope_RewrapWithKeyRing(t *testing.T) {
+	ring := NewKeyRing()
+	_, err := ring.Rotate(AEADAESGCM)
+	require.NoError(t, err)
+
+	env, err := NewEnvelope(ring)
+	require.NoError(t, err)
+
+	plain := []byte("large payload encrypted once")
+	ciphertext, err := env.Encrypt(plain)
+	require.NoError(t, err)
+
+	activeID, err := ring.Rotate(AEADSM4GCM)
+	require.NoError(t, err)
+
+	rewrapped, err := env.Rewrap(ciphertext)
+	require.NoError(t, err)
+	// 数据部分保持不变，只替换包裹的数据密钥
+	assert.Equal(t, ciphertext[len(ciphertext)-len(plain)-16:], rewrapped[len(rewrapped)-len(plain)-16:])
+
+	parsed, err := env.parse(rewrapped)
+	require.NoError(t, err)
+	keyID, err := KeyIDOf(parsed.wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, activeID, keyID)
+
+	// 旧 KEK 移除后，迁移后的密文仍可解密
+	require.NoError(t, ring.RemoveKey(1))
+	decrypted, err := env.Decrypt(rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	_, err = env.Decrypt(ciphertext)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	same, err := env.Rewrap(rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, rewrapped, same)
+}
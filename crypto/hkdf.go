@@ -0,0 +1,42 @@
+package crypto
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"hash"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// DeriveKey 使用 HKDF-SHA256（RFC 5869）从输入密钥材料派生指定长度的密钥
+// salt 可为空；info 用于区分不同用途，同一 secret 派生多个密钥时应使用不同的 info
+func DeriveKey(secret, salt, info []byte, length int) ([]byte, error) {
+	return DeriveKeyWithHash(sha256.New, secret, salt, info, length)
+}
+
+// DeriveKeySM3 使用 HKDF-SM3 派生密钥，用于国密场景
+func DeriveKeySM3(secret, salt, info []byte, length int) ([]byte, error) {
+	return DeriveKeyWithHash(sm3.New, secret, salt, info, length)
+}
+
+// DeriveKeyWithHash 使用指定哈希算法的 HKDF 派生密钥
+func DeriveKeyWithHash(h func() hash.Hash, secret, salt, info []byte, length int) ([]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("secret is empty")
+	}
+	if length <= 0 {
+		return nil, errors.New("key length must be positive")
+	}
+	return hkdf.Key(h, secret, salt, string(info), length)
+}
+
+// DeriveSessionKey 完成密钥协商并通过 HKDF-SHA256 派生会话密钥，
+// 适用于 ECDHCipher、X25519Cipher 等 KeyExchanger；协商得到的原始共享密钥不应直接用作加密密钥
+func DeriveSessionKey(kx KeyExchanger, peerPubBytes, salt, info []byte, length int) ([]byte, error) {
+	secret, err := kx.DeriveSharedSecret(peerPubBytes)
+	if err != nil {
+		return nil, err
+	}
+	return DeriveKey(secret, salt, info, length)
+}
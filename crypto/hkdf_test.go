@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveKey_RFC5869(t *testing.T) {
+	// RFC 5869 附录 A.1
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+
+	okm, err := DeriveKey(ikm, salt, info, 42)
+	require.NoError(t, err)
+	assert.Equal(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", hex.EncodeToString(okm))
+
+	_, err = DeriveKey(nil, salt, info, 32)
+	assert.Error(t, err)
+	_, err = DeriveKey(ikm, salt, info, 0)
+	assert.Error(t, err)
+}
+
+func TestDeriveKey_Info(t *testing.T) {
+	secret := []byte("shared secret")
+	k1, err := DeriveKey(secret, nil, []byte("encryption"), 32)
+	require.NoError(t, err)
+	k2, err := DeriveKey(secret, nil, []byte("mac"), 32)
+	require.NoError(t, err)
+	assert.NotEqual(t, k1, k2)
+
+	k3, err := DeriveKeySM3(secret, nil, []byte("encryption"), 32)
+	require.NoError(t, err)
+	assert.NotEqual(t, k1, k3)
+}
+
+func TestDeriveSessionKey(t *testing.T) {
+	for _, newPair := range []func() (KeyExchanger, error){
+		func() (KeyExchanger, error) { return NewECDHCipher() },
+		func() (KeyExchanger, error) { return NewX25519Cipher() },
+	} {
+		alice, err := newPair()
+		require.NoError(t, err)
+		bob, err := newPair()
+		require.NoError(t, err)
+
+		salt := []byte("handshake-nonce")
+		k1, err := DeriveSessionKey(alice, bob.PublicKeyBytes(), salt, []byte("app v1"), 32)
+		require.NoError(t, err)
+		k2, err := DeriveSessionKey(bob, alice.PublicKeyBytes(), salt, []byte("app v1"), 32)
+		require.NoError(t, err)
+		assert.Equal(t, k1, k2)
+
+		raw, err := alice.DeriveSharedSecret(bob.PublicKeyBytes())
+		require.NoError(t, err)
+		assert.NotEqual(t, raw, k1)
+
+		// 派生密钥可直接用于 AEAD
+		cipher, err := NewXChaCha20Poly1305Cipher(k1)
+		require.NoError(t, err)
+		crypted, err := cipher.Encrypt([]byte("session data"))
+		require.NoError(t, err)
+		peer, _ := NewXChaCha20Poly1305Cipher(k2)
+		plain, err := peer.Decrypt(crypted)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("session data"), plain)
+	}
+}
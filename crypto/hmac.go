@@ -4,27 +4,36 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"hash"
+
+	"github.com/tjfoc/gmsm/sm3"
 )
 
 type HMAC struct {
-	key []byte
+	key  []byte
+	hash func() hash.Hash
 }
 
 // NewHMAC 创建HMAC实例
 func NewHMAC(key []byte) *HMAC {
-	return &HMAC{key: key}
+	return &HMAC{key: key, hash: sha256.New}
+}
+
+// NewSM3HMAC 创建HMAC-SM3实例
+func NewSM3HMAC(key []byte) *HMAC {
+	return &HMAC{key: key, hash: sm3.New}
 }
 
-// Sum 计算HMAC-SHA256
+// Sum 计算HMAC（默认HMAC-SHA256），返回十六进制字符串
 func (h *HMAC) Sum(data []byte) string {
-	mac := hmac.New(sha256.New, h.key)
+	mac := hmac.New(h.hash, h.key)
 	mac.Write(data)
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
 // Verify 校验HMAC
 func (h *HMAC) Verify(data []byte, expected string) bool {
-	mac := hmac.New(sha256.New, h.key)
+	mac := hmac.New(h.hash, h.key)
 	mac.Write(data)
 	actual := hex.EncodeToString(mac.Sum(nil))
 	return hmac.Equal([]byte(actual), []byte(expected))
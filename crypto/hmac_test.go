@@ -51,3 +51,19 @@ func TestHMAC_VerifyFail(t *testing.T) {
 		t.Fatalf("HMAC verify should fail with wrong hash")
 	}
 }
+
+func TestSM3HMAC_KnownAnswer(t *testing.T) {
+	// 与 openssl dgst -sm3 -hmac 的输出对照
+	h := NewSM3HMAC([]byte("secret-key"))
+	data := []byte("hello, sm3 hmac")
+	want := "f6c5acf4712776578ec62d0f315664c53d076c43f4ea23fba1066ca160722a26"
+	if got := h.Sum(data); got != want {
+		t.Fatalf("HMAC-SM3 mismatch, got: %s, want: %s", got, want)
+	}
+	if !h.Verify(data, want) {
+		t.Fatal("HMAC-SM3 verify failed")
+	}
+	if NewHMAC([]byte("secret-key")).Verify(data, want) {
+		t.Fatal("HMAC-SHA256 should not verify HMAC-SM3 value")
+	}
+}
@@ -0,0 +1,340 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+const jweCEKSize = 32 // A256GCM 内容加密密钥长度
+
+// WithJWEAlgorithm 设置密钥管理算法，RSA 接收方可选 RSA-OAEP 或 RSA-OAEP-256（默认）
+func WithJWEAlgorithm(alg string) JOSEOption {
+	return func(h *JOSEHeader) {
+		h.Alg = alg
+	}
+}
+
+// WithJWEPartyInfo 设置 ECDH-ES 密钥派生使用的 apu、apv
+func WithJWEPartyInfo(apu, apv []byte) JOSEOption {
+	return func(h *JOSEHeader) {
+		h.Apu = base64.RawURLEncoding.EncodeToString(apu)
+		h.Apv = base64.RawURLEncoding.EncodeToString(apv)
+	}
+}
+
+// EncryptJWE 生成 JWE 紧凑序列化，内容加密固定为 A256GCM。key 决定密钥管理算法：
+//
+//	*RSACipher、*rsa.PublicKey    RSA-OAEP-256（可通过 WithJWEAlgorithm 改为 RSA-OAEP）
+//	*ECDHCipher、*ecdh.PublicKey  ECDH-ES
+//	[]byte（32 字节）             dir
+//	*JWK                          按 kty 选择上述算法
+func EncryptJWE(key any, plaintext []byte, opts ...JOSEOption) (string, error) {
+	header := &JOSEHeader{Enc: EncA256GCM}
+	for _, opt := range opts {
+		opt(header)
+	}
+
+	key, err := jweKey(key, false)
+	if err != nil {
+		return "", err
+	}
+
+	var cek, encryptedKey []byte
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if header.Alg == "" {
+			header.Alg = AlgRSAOAEP256
+		}
+		h, err := oaepHash(header.Alg)
+		if err != nil {
+			return "", err
+		}
+		cek = make([]byte, jweCEKSize)
+		if _, err := rand.Read(cek); err != nil {
+			return "", err
+		}
+		if encryptedKey, err = rsa.EncryptOAEP(h, rand.Reader, k, cek, nil); err != nil {
+			return "", err
+		}
+	case *ecdh.PublicKey:
+		if err := expectAlg(header, AlgECDHES); err != nil {
+			return "", err
+		}
+		ephemeral, err := k.Curve().GenerateKey(rand.Reader)
+		if err != nil {
+			return "", err
+		}
+		if header.Epk, err = ecdhPublicJWK(ephemeral.PublicKey()); err != nil {
+			return "", err
+		}
+		if cek, err = ecdhESKey(ephemeral, k, header); err != nil {
+			return "", err
+		}
+	case []byte:
+		if err := expectAlg(header, AlgDir); err != nil {
+			return "", err
+		}
+		cek = k
+	}
+
+	protected, err := encodeJOSEHeader(header)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newA256GCM(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	enc := base64.RawURLEncoding
+	return strings.Join([]string{
+		protected,
+		enc.EncodeToString(encryptedKey),
+		enc.EncodeToString(iv),
+		enc.EncodeToString(ciphertext),
+		enc.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptJWE 解密 JWE 紧凑序列化。key 为接收方私钥：
+//
+//	*RSACipher、*rsa.PrivateKey    RSA-OAEP、RSA-OAEP-256
+//	*ECDHCipher、*ecdh.PrivateKey  ECDH-ES
+//	[]byte（32 字节）              dir
+//	*JWK                           按 kty 选择上述算法
+func DecryptJWE(key any, token string) ([]byte, *JOSEHeader, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, nil, ErrInvalidJOSE
+	}
+	header, err := decodeJOSEHeader(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Enc != EncA256GCM {
+		return nil, nil, fmt.Errorf("%w: enc %q", ErrJOSEAlgorithm, header.Enc)
+	}
+
+	var decoded [4][]byte
+	for i, part := range parts[1:] {
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+		}
+	}
+	encryptedKey, iv, ciphertext, tag := decoded[0], decoded[1], decoded[2], decoded[3]
+
+	key, err = jweKey(key, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cek []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		h, err := oaepHash(header.Alg)
+		if err != nil {
+			return nil, nil, err
+		}
+		// 解密失败时使用随机 CEK 继续，避免暴露填充校验结果（RFC 7516 第 11.5 节）
+		cek, err = rsa.DecryptOAEP(h, nil, k, encryptedKey, nil)
+		if err != nil || len(cek) != jweCEKSize {
+			cek = make([]byte, jweCEKSize)
+			if _, err := rand.Read(cek); err != nil {
+				return nil, nil, err
+			}
+		}
+	case *ecdh.PrivateKey:
+		if header.Alg != AlgECDHES {
+			return nil, nil, fmt.Errorf("%w: got %s, want %s", ErrJOSEAlgorithm, header.Alg, AlgECDHES)
+		}
+		if len(encryptedKey) != 0 || header.Epk == nil {
+			return nil, nil, ErrInvalidJOSE
+		}
+		epk, err := jwkECDHPublicKey(header.Epk)
+		if err != nil {
+			return nil, nil, err
+		}
+		if epk.Curve() != k.Curve() {
+			return nil, nil, fmt.Errorf("%w: epk curve mismatch", ErrInvalidJOSE)
+		}
+		if cek, err = ecdhESKey(k, epk, header); err != nil {
+			return nil, nil, err
+		}
+	case []byte:
+		if header.Alg != AlgDir {
+			return nil, nil, fmt.Errorf("%w: got %s, want %s", ErrJOSEAlgorithm, header.Alg, AlgDir)
+		}
+		if len(encryptedKey) != 0 {
+			return nil, nil, ErrInvalidJOSE
+		}
+		cek = k
+	}
+
+	gcm, err := newA256GCM(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return nil, nil, ErrInvalidJOSE
+	}
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, nil, ErrJWEDecryptFailed
+	}
+	return plaintext, header, nil
+}
+
+// jweKey 将支持的密钥类型统一为 *rsa.PublicKey/*rsa.PrivateKey、*ecdh.PublicKey/*ecdh.PrivateKey 或 []byte
+func jweKey(key any, private bool) (any, error) {
+	if jwk, ok := key.(*JWK); ok {
+		switch jwk.Kty {
+		case "RSA":
+			r, err := NewRSACipherFromJWK(jwk)
+			if err != nil {
+				return nil, err
+			}
+			key = r
+		case "EC":
+			e, err := NewECDHCipherFromJWK(jwk)
+			if err != nil {
+				return nil, err
+			}
+			key = e
+		case "oct":
+			k, err := jwk.SymmetricKey()
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		}
+	}
+
+	switch k := key.(type) {
+	case *RSACipher:
+		if private {
+			key = k.privateKey
+		} else {
+			key = k.publicKey
+		}
+	case *ECDHCipher:
+		if private {
+			key = k.privateKey
+		} else {
+			key = k.publicKey
+		}
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if k != nil && !private {
+			return k, nil
+		}
+	case *rsa.PrivateKey:
+		if k != nil && private {
+			return k, nil
+		}
+	case *ecdh.PublicKey:
+		if k != nil && !private {
+			return k, nil
+		}
+	case *ecdh.PrivateKey:
+		if k != nil && private {
+			return k, nil
+		}
+	case []byte:
+		if len(k) != jweCEKSize {
+			return nil, fmt.Errorf("invalid key length: %d, must be %d bytes for A256GCM", len(k), jweCEKSize)
+		}
+		return k, nil
+	}
+	if private {
+		return nil, fmt.Errorf("%w: unsupported decryption key %T", ErrJOSEAlgorithm, key)
+	}
+	return nil, fmt.Errorf("%w: unsupported encryption key %T", ErrJOSEAlgorithm, key)
+}
+
+func expectAlg(header *JOSEHeader, alg string) error {
+	if header.Alg == "" {
+		header.Alg = alg
+		return nil
+	}
+	if header.Alg != alg {
+		return fmt.Errorf("%w: got %s, want %s", ErrJOSEAlgorithm, header.Alg, alg)
+	}
+	return nil
+}
+
+func oaepHash(alg string) (hash.Hash, error) {
+	switch alg {
+	case AlgRSAOAEP:
+		return sha1.New(), nil
+	case AlgRSAOAEP256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrJOSEAlgorithm, alg)
+	}
+}
+
+func newA256GCM(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ecdhESKey 计算共享密钥并按 Concat KDF 派生内容加密密钥（RFC 7518 第 4.6 节）
+func ecdhESKey(priv *ecdh.PrivateKey, pub *ecdh.PublicKey, header *JOSEHeader) ([]byte, error) {
+	z, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	apu, err := base64.RawURLEncoding.DecodeString(header.Apu)
+	if err != nil {
+		return nil, fmt.Errorf("%w: apu: %v", ErrInvalidJOSE, err)
+	}
+	apv, err := base64.RawURLEncoding.DecodeString(header.Apv)
+	if err != nil {
+		return nil, fmt.Errorf("%w: apv: %v", ErrInvalidJOSE, err)
+	}
+	return concatKDF(z, header.Enc, apu, apv, jweCEKSize), nil
+}
+
+// concatKDF NIST SP 800-56A Concat KDF（SHA-256）
+func concatKDF(z []byte, algID string, apu, apv []byte, keyLen int) []byte {
+	lenPrefixed := func(dst, data []byte) []byte {
+		dst = binary.BigEndian.AppendUint32(dst, uint32(len(data)))
+		return append(dst, data...)
+	}
+	var otherInfo []byte
+	otherInfo = lenPrefixed(otherInfo, []byte(algID))
+	otherInfo = lenPrefixed(otherInfo, apu)
+	otherInfo = lenPrefixed(otherInfo, apv)
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(keyLen*8))
+
+	out := make([]byte, 0, keyLen+sha256.Size)
+	for counter := uint32(1); len(out) < keyLen; counter++ {
+		h := sha256.New()
+		_ = binary.Write(h, binary.BigEndian, counter)
+		h.Write(z)
+		h.Write(otherInfo)
+		out = h.Sum(out)
+	}
+	return out[:keyLen]
+}
@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWE_EncryptDecrypt(t *testing.T) {
+	rsaCipher, err := NewRSACipher(2048)
+	require.NoError(t, err)
+	ecdhCipher, err := NewECDHCipher()
+	require.NoError(t, err)
+	aesKey, _ := GenerateAESKey(32)
+
+	plaintext := []byte("The true sign of intelligence is not knowledge but imagination.")
+	cases := []struct {
+		name    string
+		encKey  any
+		decKey  any
+		opts    []JOSEOption
+		wantAlg string
+	}{
+		{"RSA-OAEP-256", rsaCipher, rsaCipher, nil, AlgRSAOAEP256},
+		{"RSA-OAEP", rsaCipher.PublicKey(), rsaCipher.PrivateKey(), []JOSEOption{WithJWEAlgorithm(AlgRSAOAEP)}, AlgRSAOAEP},
+		{"ECDH-ES", ecdhCipher.PublicKey(), ecdhCipher, []JOSEOption{WithJWEPartyInfo([]byte("Alice"), []byte("Bob"))}, AlgECDHES},
+		{"dir", aesKey, aesKey, nil, AlgDir},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			token, err := EncryptJWE(c.encKey, plaintext, append(c.opts, WithJOSEKeyID("k1"))...)
+			require.NoError(t, err)
+			assert.Len(t, strings.Split(token, "."), 5)
+
+			got, header, err := DecryptJWE(c.decKey, token)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, got)
+			assert.Equal(t, c.wantAlg, header.Alg)
+			assert.Equal(t, EncA256GCM, header.Enc)
+			assert.Equal(t, "k1", header.Kid)
+
+			// 篡改受保护头部
+			parts := strings.Split(token, ".")
+			h, _ := base64.RawURLEncoding.DecodeString(parts[0])
+			parts[0] = base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(h), `"k1"`, `"k2"`, 1)))
+			_, _, err = DecryptJWE(c.decKey, strings.Join(parts, "."))
+			assert.ErrorIs(t, err, ErrJWEDecryptFailed)
+		})
+	}
+}
+
+func TestJWE_WithJWK(t *testing.T) {
+	ecdhCipher, err := NewECDHCipher()
+	require.NoError(t, err)
+	privJWK, err := ecdhCipher.ExportJWK(true)
+	require.NoError(t, err)
+
+	token, err := EncryptJWE(privJWK.Public(), []byte("partner payload"))
+	require.NoError(t, err)
+	got, _, err := DecryptJWE(privJWK, token)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("partner payload"), got)
+
+	// 公钥无法解密，算法与密钥类型不匹配时拒绝
+	_, _, err = DecryptJWE(privJWK.Public(), token)
+	assert.Error(t, err)
+	aesKey, _ := GenerateAESKey(32)
+	_, _, err = DecryptJWE(aesKey, token)
+	assert.ErrorIs(t, err, ErrJOSEAlgorithm)
+}
+
+func TestConcatKDF_RFC7518(t *testing.T) {
+	// RFC 7518 附录 C
+	z := []byte{158, 86, 217, 29, 129, 113, 53, 211, 114, 131, 66, 131, 191, 132, 38, 156,
+		251, 49, 110, 163, 218, 128, 106, 72, 246, 218, 167, 121, 140, 254, 144, 196}
+	key := concatKDF(z, "A128GCM", []byte("Alice"), []byte("Bob"), 16)
+	assert.Equal(t, "VqqN6vgjbSBcIijNcacQGg", base64.RawURLEncoding.EncodeToString(key))
+}
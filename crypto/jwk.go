@@ -0,0 +1,405 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrInvalidJWK = errors.New("crypto: invalid jwk")
+
+// JWK JSON Web Key（RFC 7517），支持 RSA、EC（P-256/P-384/P-521）与 oct（AES）密钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	// RSA
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// EC / RSA 私钥
+	D string `json:"d,omitempty"`
+
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// JWKSet JSON Web Key Set
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// Find 按 kid 查找密钥
+func (s *JWKSet) Find(kid string) *JWK {
+	for _, k := range s.Keys {
+		if k.Kid == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// ParseJWK 解析 JSON 格式的 JWK
+func ParseJWK(data []byte) (*JWK, error) {
+	var jwk JWK
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	switch jwk.Kty {
+	case "RSA", "EC", "oct":
+		return &jwk, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported kty %q", ErrInvalidJWK, jwk.Kty)
+	}
+}
+
+// IsPrivate 是否包含私钥或对称密钥
+func (j *JWK) IsPrivate() bool {
+	return j.D != "" || j.K != ""
+}
+
+// Public 返回去除私钥参数后的副本
+func (j *JWK) Public() *JWK {
+	return &JWK{Kty: j.Kty, Use: j.Use, Alg: j.Alg, Kid: j.Kid, Crv: j.Crv, X: j.X, Y: j.Y, N: j.N, E: j.E}
+}
+
+// Thumbprint 计算 RFC 7638 SHA-256 指纹（base64url），可用作 kid
+func (j *JWK) Thumbprint() (string, error) {
+	var members string
+	switch j.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, j.E, j.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, j.Crv, j.X, j.Y)
+	case "oct":
+		members = fmt.Sprintf(`{"k":%q,"kty":"oct"}`, j.K)
+	default:
+		return "", fmt.Errorf("%w: unsupported kty %q", ErrInvalidJWK, j.Kty)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return b64Encode(sum[:]), nil
+}
+
+// NewAESJWK 将 AES 密钥导出为 oct 类型 JWK
+func NewAESJWK(key []byte) (*JWK, error) {
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("invalid key length: %d, must be 16, 24, or 32 bytes", len(key))
+	}
+	return &JWK{Kty: "oct", K: b64Encode(key)}, nil
+}
+
+// SymmetricKey 返回 oct 类型 JWK 的密钥字节
+func (j *JWK) SymmetricKey() ([]byte, error) {
+	if j.Kty != "oct" {
+		return nil, fmt.Errorf("%w: kty %q is not oct", ErrInvalidJWK, j.Kty)
+	}
+	return b64Decode(j.K)
+}
+
+// ExportJWK 导出 RSA 密钥，includePrivate 为 false 时只导出公钥
+func (r *RSACipher) ExportJWK(includePrivate bool) (*JWK, error) {
+	if r.publicKey == nil {
+		return nil, errors.New("public key is nil")
+	}
+	jwk := &JWK{
+		Kty: "RSA",
+		N:   b64Encode(r.publicKey.N.Bytes()),
+		E:   b64Encode(big.NewInt(int64(r.publicKey.E)).Bytes()),
+	}
+	if !includePrivate {
+		return jwk, nil
+	}
+	priv := r.privateKey
+	if priv == nil {
+		return nil, errors.New("private key is nil")
+	}
+	if len(priv.Primes) != 2 {
+		return nil, errors.New("multi-prime RSA keys are not supported")
+	}
+	priv.Precompute()
+	jwk.D = b64Encode(priv.D.Bytes())
+	jwk.P = b64Encode(priv.Primes[0].Bytes())
+	jwk.Q = b64Encode(priv.Primes[1].Bytes())
+	jwk.DP = b64Encode(priv.Precomputed.Dp.Bytes())
+	jwk.DQ = b64Encode(priv.Precomputed.Dq.Bytes())
+	jwk.QI = b64Encode(priv.Precomputed.Qinv.Bytes())
+	return jwk, nil
+}
+
+// NewRSACipherFromJWK 从 JWK 导入 RSA 密钥，只含公钥时仅可加密与验签
+func NewRSACipherFromJWK(jwk *JWK) (*RSACipher, error) {
+	if jwk.Kty != "RSA" {
+		return nil, fmt.Errorf("%w: kty %q is not RSA", ErrInvalidJWK, jwk.Kty)
+	}
+	n, err := b64BigInt(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := b64BigInt(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("%w: invalid exponent", ErrInvalidJWK)
+	}
+	pub := &rsa.PublicKey{N: n, E: int(e.Int64())}
+	if jwk.D == "" {
+		return NewRSACipherFromKey(nil, pub), nil
+	}
+
+	d, err := b64BigInt(jwk.D)
+	if err != nil {
+		return nil, err
+	}
+	if jwk.P == "" || jwk.Q == "" {
+		return nil, fmt.Errorf("%w: RSA private key requires p and q", ErrInvalidJWK)
+	}
+	p, err := b64BigInt(jwk.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := b64BigInt(jwk.Q)
+	if err != nil {
+		return nil, err
+	}
+	priv := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+	if err := priv.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	priv.Precompute()
+	return NewRSACipherFromKey(priv, &priv.PublicKey), nil
+}
+
+// ExportJWK 导出 ECDSA 密钥，includePrivate 为 false 时只导出公钥
+func (e *ECDSACipher) ExportJWK(includePrivate bool) (*JWK, error) {
+	if e.publicKey == nil {
+		return nil, errors.New("public key is nil")
+	}
+	crv, err := curveName(e.publicKey.Curve)
+	if err != nil {
+		return nil, err
+	}
+	point, err := e.publicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	jwk := ecJWK(crv, point)
+	if includePrivate {
+		if e.privateKey == nil {
+			return nil, errors.New("private key is nil")
+		}
+		d, err := e.privateKey.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		jwk.D = b64Encode(d)
+	}
+	return jwk, nil
+}
+
+// NewECDSACipherFromJWK 从 JWK 导入 ECDSA 密钥，只含公钥时仅可验签
+func NewECDSACipherFromJWK(jwk *JWK) (*ECDSACipher, error) {
+	curve, _, err := jwkCurve(jwk)
+	if err != nil {
+		return nil, err
+	}
+	point, err := jwkPoint(jwk, curve.Params().BitSize)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	if jwk.D == "" {
+		return NewECDSACipherFromKey(nil, pub), nil
+	}
+
+	d, err := b64Decode(jwk.D)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ecdsa.ParseRawPrivateKey(curve, d)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	if !priv.PublicKey.Equal(pub) {
+		return nil, fmt.Errorf("%w: private key does not match public key", ErrInvalidJWK)
+	}
+	return NewECDSACipherFromKey(priv, &priv.PublicKey), nil
+}
+
+// ExportJWK 导出 ECDH 密钥，includePrivate 为 false 时只导出公钥
+func (e *ECDHCipher) ExportJWK(includePrivate bool) (*JWK, error) {
+	if e.publicKey == nil {
+		return nil, errors.New("public key is nil")
+	}
+	jwk, err := ecdhPublicJWK(e.publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if includePrivate {
+		if e.privateKey == nil {
+			return nil, errors.New("private key is nil")
+		}
+		jwk.D = b64Encode(e.privateKey.Bytes())
+	}
+	return jwk, nil
+}
+
+// NewECDHCipherFromJWK 从 JWK 导入 ECDH 密钥；只含公钥时可作为 JWE 接收方公钥使用
+func NewECDHCipherFromJWK(jwk *JWK) (*ECDHCipher, error) {
+	pub, err := jwkECDHPublicKey(jwk)
+	if err != nil {
+		return nil, err
+	}
+	if jwk.D == "" {
+		return &ECDHCipher{publicKey: pub}, nil
+	}
+
+	d, err := b64Decode(jwk.D)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := pub.Curve().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	if !priv.PublicKey().Equal(pub) {
+		return nil, fmt.Errorf("%w: private key does not match public key", ErrInvalidJWK)
+	}
+	return &ECDHCipher{privateKey: priv, publicKey: pub}, nil
+}
+
+func ecdhPublicJWK(pub *ecdh.PublicKey) (*JWK, error) {
+	var crv string
+	switch pub.Curve() {
+	case ecdh.P256():
+		crv = "P-256"
+	case ecdh.P384():
+		crv = "P-384"
+	case ecdh.P521():
+		crv = "P-521"
+	default:
+		return nil, fmt.Errorf("%w: unsupported curve", ErrInvalidJWK)
+	}
+	return ecJWK(crv, pub.Bytes()), nil
+}
+
+func jwkECDHPublicKey(jwk *JWK) (*ecdh.PublicKey, error) {
+	curve, ecdhCurve, err := jwkCurve(jwk)
+	if err != nil {
+		return nil, err
+	}
+	point, err := jwkPoint(jwk, curve.Params().BitSize)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := ecdhCurve.NewPublicKey(point)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	return pub, nil
+}
+
+// ecJWK 由未压缩点（0x04 || X || Y）构造 EC 公钥 JWK
+func ecJWK(crv string, point []byte) *JWK {
+	size := (len(point) - 1) / 2
+	return &JWK{
+		Kty: "EC",
+		Crv: crv,
+		X:   b64Encode(point[1 : 1+size]),
+		Y:   b64Encode(point[1+size:]),
+	}
+}
+
+// jwkPoint 将 JWK 的 x、y 还原为未压缩点
+func jwkPoint(jwk *JWK, bitSize int) ([]byte, error) {
+	x, err := b64Decode(jwk.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := b64Decode(jwk.Y)
+	if err != nil {
+		return nil, err
+	}
+	size := (bitSize + 7) / 8
+	if len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("%w: invalid coordinate length", ErrInvalidJWK)
+	}
+	point := make([]byte, 0, 1+2*size)
+	point = append(point, 4)
+	point = append(point, x...)
+	return append(point, y...), nil
+}
+
+func jwkCurve(jwk *JWK) (elliptic.Curve, ecdh.Curve, error) {
+	if jwk.Kty != "EC" {
+		return nil, nil, fmt.Errorf("%w: kty %q is not EC", ErrInvalidJWK, jwk.Kty)
+	}
+	switch jwk.Crv {
+	case "P-256":
+		return elliptic.P256(), ecdh.P256(), nil
+	case "P-384":
+		return elliptic.P384(), ecdh.P384(), nil
+	case "P-521":
+		return elliptic.P521(), ecdh.P521(), nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidJWK, jwk.Crv)
+	}
+}
+
+func curveName(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return "P-256", nil
+	case elliptic.P384():
+		return "P-384", nil
+	case elliptic.P521():
+		return "P-521", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported curve", ErrInvalidJWK)
+	}
+}
+
+func b64Encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func b64Decode(s string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	return data, nil
+}
+
+func b64BigInt(s string) (*big.Int, error) {
+	data, err := b64Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty integer", ErrInvalidJWK)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
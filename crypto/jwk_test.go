@@ -0,0 +1,102 @@
+package crypto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWK_RSA(t *testing.T) {
+	rsaCipher, err := NewRSACipher(2048)
+	require.NoError(t, err)
+
+	priv, err := rsaCipher.ExportJWK(true)
+	require.NoError(t, err)
+	assert.Equal(t, "RSA", priv.Kty)
+	assert.Equal(t, "AQAB", priv.E)
+	assert.True(t, priv.IsPrivate())
+
+	data, err := json.Marshal(priv)
+	require.NoError(t, err)
+	parsed, err := ParseJWK(data)
+	require.NoError(t, err)
+
+	imported, err := NewRSACipherFromJWK(parsed)
+	require.NoError(t, err)
+	crypted, err := rsaCipher.Encrypt([]byte("hello"))
+	require.NoError(t, err)
+	plain, err := imported.Decrypt(crypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), plain)
+
+	// 公钥 JWK 只能加密
+	pubOnly, err := NewRSACipherFromJWK(priv.Public())
+	require.NoError(t, err)
+	assert.False(t, priv.Public().IsPrivate())
+	_, err = pubOnly.Decrypt(crypted)
+	assert.Error(t, err)
+}
+
+func TestJWK_EC(t *testing.T) {
+	ecdsaCipher, err := NewECDSACipher()
+	require.NoError(t, err)
+
+	jwk, err := ecdsaCipher.ExportJWK(true)
+	require.NoError(t, err)
+	assert.Equal(t, "P-256", jwk.Crv)
+
+	imported, err := NewECDSACipherFromJWK(jwk)
+	require.NoError(t, err)
+	sig, err := imported.Sign([]byte("data"))
+	require.NoError(t, err)
+	ok, err := ecdsaCipher.Verify([]byte("data"), sig)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// 同一 EC 密钥也可作为 ECDH 密钥导入
+	ecdhCipher, err := NewECDHCipherFromJWK(jwk)
+	require.NoError(t, err)
+	exported, err := ecdhCipher.ExportJWK(true)
+	require.NoError(t, err)
+	assert.Equal(t, jwk, exported)
+
+	// 私钥与公钥不匹配
+	other, _ := NewECDSACipher()
+	otherJWK, _ := other.ExportJWK(true)
+	otherJWK.X, otherJWK.Y = jwk.X, jwk.Y
+	_, err = NewECDSACipherFromJWK(otherJWK)
+	assert.ErrorIs(t, err, ErrInvalidJWK)
+}
+
+func TestJWK_AESAndThumbprint(t *testing.T) {
+	key, _ := GenerateAESKey(32)
+	jwk, err := NewAESJWK(key)
+	require.NoError(t, err)
+	got, err := jwk.SymmetricKey()
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	_, err = NewAESJWK([]byte("short"))
+	assert.Error(t, err)
+
+	// RFC 7638 第 3.1 节示例
+	rsaJWK := &JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+		Alg: "RS256",
+		Kid: "2011-04-29",
+	}
+	thumbprint, err := rsaJWK.Thumbprint()
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+
+	set := &JWKSet{Keys: []*JWK{jwk, rsaJWK}}
+	assert.Equal(t, rsaJWK, set.Find("2011-04-29"))
+	assert.Nil(t, set.Find("missing"))
+
+	_, err = ParseJWK([]byte(`{"kty":"OKP"}`))
+	assert.ErrorIs(t, err, ErrInvalidJWK)
+}
@@ -0,0 +1,228 @@
+package crypto
+
+import (
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// JOSE 算法名称
+const (
+	AlgRS256      = "RS256"        // RSASSA-PKCS1-v1_5 + SHA-256（RSACipher）
+	AlgES256      = "ES256"        // ECDSA P-256 + SHA-256（ECDSACipher）
+	AlgEdDSA      = "EdDSA"        // Ed25519（Ed25519Cipher）
+	AlgSM2        = "SM2"          // SM2 + SM3（SM2Cipher，非标准算法名，仅用于双方约定的场景）
+	AlgRSAOAEP    = "RSA-OAEP"     // RSAES-OAEP + SHA-1
+	AlgRSAOAEP256 = "RSA-OAEP-256" // RSAES-OAEP + SHA-256
+	AlgECDHES     = "ECDH-ES"      // ECDH-ES 直接密钥协商
+	AlgDir        = "dir"          // 直接使用共享的对称密钥
+	EncA256GCM    = "A256GCM"      // AES-256-GCM 内容加密
+)
+
+var (
+	ErrInvalidJOSE      = errors.New("crypto: invalid jose compact serialization")
+	ErrJOSEAlgorithm    = errors.New("crypto: unexpected jose algorithm")
+	ErrJWSSignature     = errors.New("crypto: jws signature verification failed")
+	ErrJWEDecryptFailed = errors.New("crypto: jwe decryption failed")
+)
+
+// JOSEHeader JWS/JWE 受保护头部
+type JOSEHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+	Cty string `json:"cty,omitempty"`
+	Epk *JWK   `json:"epk,omitempty"`
+	Apu string `json:"apu,omitempty"`
+	Apv string `json:"apv,omitempty"`
+}
+
+// JOSEOption JWS/JWE 头部选项
+type JOSEOption func(*JOSEHeader)
+
+// WithJOSEKeyID 设置 kid
+func WithJOSEKeyID(kid string) JOSEOption {
+	return func(h *JOSEHeader) {
+		h.Kid = kid
+	}
+}
+
+// WithJOSEType 设置 typ，如 "JWT"
+func WithJOSEType(typ string) JOSEOption {
+	return func(h *JOSEHeader) {
+		h.Typ = typ
+	}
+}
+
+// WithJOSEContentType 设置 cty，如嵌套 JWT 时为 "JWT"
+func WithJOSEContentType(cty string) JOSEOption {
+	return func(h *JOSEHeader) {
+		h.Cty = cty
+	}
+}
+
+// ParseJOSEHeader 解析 JWS/JWE 紧凑序列化的头部，可用于按 kid 选择密钥
+func ParseJOSEHeader(token string) (*JOSEHeader, error) {
+	protected, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidJOSE
+	}
+	return decodeJOSEHeader(protected)
+}
+
+func decodeJOSEHeader(protected string) (*JOSEHeader, error) {
+	data, err := base64.RawURLEncoding.DecodeString(protected)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+	var header JOSEHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+	if header.Alg == "" {
+		return nil, fmt.Errorf("%w: missing alg", ErrInvalidJOSE)
+	}
+	return &header, nil
+}
+
+func encodeJOSEHeader(header *JOSEHeader) (string, error) {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// SignJWS 使用 Signer 生成 JWS 紧凑序列化，支持 RSACipher（RS256）、ECDSACipher（ES256）、Ed25519Cipher（EdDSA）与 SM2Cipher（SM2）
+func SignJWS(signer Signer, payload []byte, opts ...JOSEOption) (string, error) {
+	alg, err := jwsAlgorithm(signer)
+	if err != nil {
+		return "", err
+	}
+	header := &JOSEHeader{Alg: alg}
+	for _, opt := range opts {
+		opt(header)
+	}
+	protected, err := encodeJOSEHeader(header)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := protected + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := signer.Sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	raw, err := jwsSignatureBytes(alg, sig)
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// VerifyJWS 校验 JWS 紧凑序列化并返回载荷；头部 alg 必须与 Verifier 的算法一致
+func VerifyJWS(verifier Verifier, token string) ([]byte, *JOSEHeader, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, ErrInvalidJOSE
+	}
+	header, err := decodeJOSEHeader(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	alg, err := jwsAlgorithm(verifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Alg != alg {
+		return nil, nil, fmt.Errorf("%w: got %s, want %s", ErrJOSEAlgorithm, header.Alg, alg)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+	sig, err := jwsSignatureString(alg, raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ok, err := verifier.Verify([]byte(parts[0]+"."+parts[1]), sig)
+	if err != nil || !ok {
+		return nil, nil, ErrJWSSignature
+	}
+	return payload, header, nil
+}
+
+// jwsAlgorithm 按 Signer/Verifier 的算法名确定 JWS 算法
+func jwsAlgorithm(v interface{ Name() string }) (string, error) {
+	switch v.Name() {
+	case "RSA":
+		return AlgRS256, nil
+	case "ECDSA":
+		// ECDSACipher 固定使用 SHA-256，仅 P-256 曲线与 ES256 对应
+		if e, ok := v.(*ECDSACipher); ok && e.publicKey != nil && e.publicKey.Curve != elliptic.P256() {
+			return "", fmt.Errorf("%w: ECDSA curve %s", ErrJOSEAlgorithm, e.publicKey.Curve.Params().Name)
+		}
+		return AlgES256, nil
+	case "Ed25519":
+		return AlgEdDSA, nil
+	case "SM2":
+		return AlgSM2, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrJOSEAlgorithm, v.Name())
+	}
+}
+
+// jwsSignatureBytes 将 Signer 返回的签名转换为 JWS 签名字节
+func jwsSignatureBytes(alg, sig string) ([]byte, error) {
+	switch alg {
+	case AlgRS256, AlgEdDSA:
+		return base64.StdEncoding.DecodeString(sig)
+	case AlgES256:
+		// ECDSACipher 签名格式为 base64(r)$base64(s)，JWS 要求定长的 r || s
+		rs := strings.Split(sig, "$")
+		if len(rs) != 2 {
+			return nil, errors.New("invalid signature format")
+		}
+		out := make([]byte, 64)
+		for i, part := range rs {
+			b, err := base64.StdEncoding.DecodeString(part)
+			if err != nil {
+				return nil, err
+			}
+			n := new(big.Int).SetBytes(b)
+			if n.BitLen() > 256 {
+				return nil, errors.New("invalid signature format")
+			}
+			n.FillBytes(out[i*32 : (i+1)*32])
+		}
+		return out, nil
+	default:
+		return []byte(sig), nil
+	}
+}
+
+// jwsSignatureString 将 JWS 签名字节转换为 Verifier 接受的格式
+func jwsSignatureString(alg string, raw []byte) (string, error) {
+	switch alg {
+	case AlgRS256, AlgEdDSA:
+		return base64.StdEncoding.EncodeToString(raw), nil
+	case AlgES256:
+		if len(raw) != 64 {
+			return "", ErrJWSSignature
+		}
+		return base64.StdEncoding.EncodeToString(raw[:32]) + "$" + base64.StdEncoding.EncodeToString(raw[32:]), nil
+	default:
+		return string(raw), nil
+	}
+}
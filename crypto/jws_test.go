@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWS_SignVerify(t *testing.T) {
+	rsaCipher, err := NewRSACipher(2048)
+	require.NoError(t, err)
+	ecdsaCipher, err := NewECDSACipher()
+	require.NoError(t, err)
+	sm2Cipher, err := NewSM2Cipher()
+	require.NoError(t, err)
+
+	payload := []byte(`{"sub":"1234567890","name":"John Doe"}`)
+	cases := []struct {
+		signer interface {
+			Signer
+			Verifier
+		}
+		alg string
+	}{
+		{rsaCipher, AlgRS256},
+		{ecdsaCipher, AlgES256},
+		{sm2Cipher, AlgSM2},
+	}
+	for _, c := range cases {
+		t.Run(c.alg, func(t *testing.T) {
+			token, err := SignJWS(c.signer, payload, WithJOSEKeyID("k1"), WithJOSEType("JWT"))
+			require.NoError(t, err)
+
+			header, err := ParseJOSEHeader(token)
+			require.NoError(t, err)
+			assert.Equal(t, c.alg, header.Alg)
+			assert.Equal(t, "k1", header.Kid)
+
+			got, header, err := VerifyJWS(c.signer, token)
+			require.NoError(t, err)
+			assert.Equal(t, payload, got)
+			assert.Equal(t, "JWT", header.Typ)
+
+			// 篡改载荷
+			parts := strings.Split(token, ".")
+			parts[1] = parts[1][:len(parts[1])-2] + "AA"
+			_, _, err = VerifyJWS(c.signer, strings.Join(parts, "."))
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("算法不匹配", func(t *testing.T) {
+		token, err := SignJWS(rsaCipher, payload)
+		require.NoError(t, err)
+		_, _, err = VerifyJWS(ecdsaCipher, token)
+		assert.ErrorIs(t, err, ErrJOSEAlgorithm)
+	})
+}
+
+func TestJWS_RFC7515ES256(t *testing.T) {
+	// RFC 7515 附录 A.3
+	jwk := &JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   "f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",
+		Y:   "x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",
+	}
+	token := "eyJhbGciOiJFUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".DtEhU3ljbEg8L38VWAfUAqOyKAM6-Xx-F4GawxaepmXFCgfTjDxw5djxLa8ISlSApmWQxfKTUJqPP3-Kg6NU1Q"
+
+	verifier, err := NewECDSACipherFromJWK(jwk)
+	require.NoError(t, err)
+	payload, _, err := VerifyJWS(verifier, token)
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"iss":"joe"`)
+}
@@ -0,0 +1,271 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+var (
+	ErrKeyNotFound       = errors.New("crypto: key not found")
+	ErrKeyExists         = errors.New("crypto: key already exists")
+	ErrNoActiveKey       = errors.New("crypto: key ring has no active key")
+	ErrRemoveActiveKey   = errors.New("crypto: cannot remove the active key")
+	ErrInvalidCiphertext = errors.New("crypto: invalid ciphertext")
+)
+
+// AEADAlgorithm 对称 AEAD 算法标识，写入密文头部
+type AEADAlgorithm byte
+
+const (
+	AEADAESGCM AEADAlgorithm = 1 // AES-GCM，密钥 16、24 或 32 字节
+	AEADSM4GCM AEADAlgorithm = 2 // SM4-GCM，密钥 16 字节
+)
+
+func (a AEADAlgorithm) String() string {
+	switch a {
+	case AEADAESGCM:
+		return "AES-GCM"
+	case AEADSM4GCM:
+		return "SM4-GCM"
+	default:
+		return fmt.Sprintf("AEADAlgorithm(%d)", byte(a))
+	}
+}
+
+// KeySize 返回生成新密钥时使用的长度
+func (a AEADAlgorithm) KeySize() int {
+	if a == AEADSM4GCM {
+		return 16
+	}
+	return 32
+}
+
+// newAEAD 按算法创建 AEAD 实例并校验密钥长度
+func newAEAD(alg AEADAlgorithm, key []byte) (cipher.AEAD, error) {
+	var (
+		block cipher.Block
+		err   error
+	)
+	switch alg {
+	case AEADAESGCM:
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, fmt.Errorf("invalid key length: %d, must be 16, 24, or 32 bytes", len(key))
+		}
+		block, err = aes.NewCipher(key)
+	case AEADSM4GCM:
+		if len(key) != 16 {
+			return nil, errors.New("SM4 key length must be 16 bytes")
+		}
+		block, err = sm4.NewCipher(key)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// 密钥环密文格式：version(1) | keyID(4, 大端) | algorithm(1) | nonce(12) | ciphertext
+// 头部作为附加数据参与认证。
+const (
+	keyRingVersion    = 1
+	keyRingHeaderSize = 1 + 4 + 1
+)
+
+type keyRingEntry struct {
+	algorithm AEADAlgorithm
+	aead      cipher.AEAD
+}
+
+// KeyRing 持有多个带版本号的数据密钥，始终使用当前激活的密钥加密，
+// 解密时按密文头部的密钥 ID 选择密钥，从而可以在不重新加密存量数据的情况下轮换密钥
+type KeyRing struct {
+	mu     sync.RWMutex
+	keys   map[uint32]*keyRingEntry
+	active uint32
+}
+
+// NewKeyRing 创建空的密钥环
+func NewKeyRing() *KeyRing {
+	return &KeyRing{keys: make(map[uint32]*keyRingEntry)}
+}
+
+// AddKey 添加密钥；密钥环中的第一个密钥自动成为激活密钥
+func (k *KeyRing) AddKey(id uint32, alg AEADAlgorithm, key []byte) error {
+	if id == 0 {
+		return errors.New("key id must be greater than 0")
+	}
+	aead, err := newAEAD(alg, key)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("%w: %d", ErrKeyExists, id)
+	}
+	k.keys[id] = &keyRingEntry{algorithm: alg, aead: aead}
+	if k.active == 0 {
+		k.active = id
+	}
+	return nil
+}
+
+// Rotate 生成新的随机密钥（ID 为当前最大 ID 加 1）并设为激活密钥，返回新密钥 ID
+func (k *KeyRing) Rotate(alg AEADAlgorithm) (uint32, error) {
+	key := make([]byte, alg.KeySize())
+	if _, err := rand.Read(key); err != nil {
+		return 0, err
+	}
+	aead, err := newAEAD(alg, key)
+	if err != nil {
+		return 0, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var id uint32
+	for existing := range k.keys {
+		id = max(id, existing)
+	}
+	id++
+	k.keys[id] = &keyRingEntry{algorithm: alg, aead: aead}
+	k.active = id
+	return id, nil
+}
+
+// SetActive 设置激活密钥
+func (k *KeyRing) SetActive(id uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %d", ErrKeyNotFound, id)
+	}
+	k.active = id
+	return nil
+}
+
+// RemoveKey 移除不再使用的旧密钥，移除后以该密钥加密的密文将无法解密
+func (k *KeyRing) RemoveKey(id uint32) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %d", ErrKeyNotFound, id)
+	}
+	if id == k.active {
+		return ErrRemoveActiveKey
+	}
+	delete(k.keys, id)
+	return nil
+}
+
+// ActiveKeyID 返回激活密钥 ID，密钥环为空时返回 0
+func (k *KeyRing) ActiveKeyID() uint32 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// KeyIDs 按升序返回所有密钥 ID
+func (k *KeyRing) KeyIDs() []uint32 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	ids := make([]uint32, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Encrypt 使用激活密钥加密
+func (k *KeyRing) Encrypt(plain []byte) ([]byte, error) {
+	k.mu.RLock()
+	id, entry := k.active, k.keys[k.active]
+	k.mu.RUnlock()
+
+	if entry == nil {
+		return nil, ErrNoActiveKey
+	}
+
+	nonceSize := entry.aead.NonceSize()
+	out := make([]byte, keyRingHeaderSize+nonceSize, keyRingHeaderSize+nonceSize+len(plain)+entry.aead.Overhead())
+	out[0] = keyRingVersion
+	binary.BigEndian.PutUint32(out[1:5], id)
+	out[5] = byte(entry.algorithm)
+	nonce := out[keyRingHeaderSize:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return entry.aead.Seal(out, nonce, plain, out[:keyRingHeaderSize]), nil
+}
+
+// Decrypt 按密文中的密钥 ID 选择密钥解密
+func (k *KeyRing) Decrypt(ciphertext []byte) ([]byte, error) {
+	id, err := KeyIDOf(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.RLock()
+	entry := k.keys[id]
+	k.mu.RUnlock()
+
+	if entry == nil {
+		return nil, fmt.Errorf("%w: %d", ErrKeyNotFound, id)
+	}
+	if AEADAlgorithm(ciphertext[5]) != entry.algorithm {
+		return nil, fmt.Errorf("%w: algorithm %s does not match key %d", ErrInvalidCiphertext, AEADAlgorithm(ciphertext[5]), id)
+	}
+
+	nonceSize := entry.aead.NonceSize()
+	if len(ciphertext) < keyRingHeaderSize+nonceSize+entry.aead.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce := ciphertext[keyRingHeaderSize : keyRingHeaderSize+nonceSize]
+	return entry.aead.Open(nil, nonce, ciphertext[keyRingHeaderSize+nonceSize:], ciphertext[:keyRingHeaderSize])
+}
+
+// Rewrap 将密文迁移到激活密钥；已使用激活密钥加密的密文原样返回
+func (k *KeyRing) Rewrap(ciphertext []byte) ([]byte, error) {
+	id, err := KeyIDOf(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if id == k.ActiveKeyID() {
+		return ciphertext, nil
+	}
+	plain, err := k.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return k.Encrypt(plain)
+}
+
+// Name 返回算法名称
+func (k *KeyRing) Name() string {
+	return "KeyRing"
+}
+
+// KeyIDOf 解析密钥环密文使用的密钥 ID
+func KeyIDOf(ciphertext []byte) (uint32, error) {
+	if len(ciphertext) < keyRingHeaderSize || ciphertext[0] != keyRingVersion {
+		return 0, ErrInvalidCiphertext
+	}
+	return binary.BigEndian.Uint32(ciphertext[1:5]), nil
+}
@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRing_Rotation(t *testing.T) {
+	ring := NewKeyRing()
+	_, err := ring.Encrypt([]byte("x"))
+	assert.ErrorIs(t, err, ErrNoActiveKey)
+
+	require.NoError(t, ring.AddKey(1, AEADAESGCM, []byte("1234567890abcdef")))
+	assert.Equal(t, uint32(1), ring.ActiveKeyID())
+
+	plain := []byte("hello, key ring!")
+	oldCiphertext, err := ring.Encrypt(plain)
+	require.NoError(t, err)
+
+	// 轮换到 SM4 密钥，旧密文仍可解密
+	id, err := ring.Rotate(AEADSM4GCM)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), id)
+	assert.Equal(t, []uint32{1, 2}, ring.KeyIDs())
+
+	decrypted, err := ring.Decrypt(oldCiphertext)
+	require.NoError(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	newCiphertext, err := ring.Encrypt(plain)
+	require.NoError(t, err)
+	keyID, err := KeyIDOf(newCiphertext)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), keyID)
+
+	// Rewrap 迁移到激活密钥，已是激活密钥的密文原样返回
+	rewrapped, err := ring.Rewrap(oldCiphertext)
+	require.NoError(t, err)
+	keyID, _ = KeyIDOf(rewrapped)
+	assert.Equal(t, uint32(2), keyID)
+	same, err := ring.Rewrap(newCiphertext)
+	require.NoError(t, err)
+	assert.Equal(t, newCiphertext, same)
+
+	// 移除旧密钥后旧密文不可解密
+	assert.ErrorIs(t, ring.RemoveKey(2), ErrRemoveActiveKey)
+	require.NoError(t, ring.RemoveKey(1))
+	_, err = ring.Decrypt(oldCiphertext)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	decrypted, err = ring.Decrypt(rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, plain, decrypted)
+}
+
+func TestKeyRing_InvalidInput(t *testing.T) {
+	ring := NewKeyRing()
+	assert.Error(t, ring.AddKey(0, AEADAESGCM, make([]byte, 16)))
+	assert.Error(t, ring.AddKey(1, AEADSM4GCM, make([]byte, 32)))
+	require.NoError(t, ring.AddKey(1, AEADAESGCM, make([]byte, 32)))
+	assert.ErrorIs(t, ring.AddKey(1, AEADAESGCM, make([]byte, 32)), ErrKeyExists)
+	assert.ErrorIs(t, ring.SetActive(9), ErrKeyNotFound)
+
+	ciphertext, err := ring.Encrypt([]byte("data"))
+	require.NoError(t, err)
+
+	// 篡改头部中的算法
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[5] = byte(AEADSM4GCM)
+	_, err = ring.Decrypt(tampered)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	// 篡改正文
+	tampered = append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = ring.Decrypt(tampered)
+	assert.Error(t, err)
+
+	_, err = ring.Decrypt([]byte{9, 9})
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
@@ -0,0 +1,862 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+var (
+	ErrUnknownKeyFormat     = errors.New("crypto: unknown key format")
+	ErrUnsupportedKeyType   = errors.New("crypto: unsupported key type")
+	ErrPassphraseRequired   = errors.New("crypto: passphrase required for encrypted key")
+	ErrIncorrectPassphrase  = errors.New("crypto: incorrect passphrase")
+	ErrKeyFormatUnsupported = errors.New("crypto: key format not supported for key type")
+)
+
+// KeyEncoding 密钥编码
+type KeyEncoding int
+
+const (
+	KeyEncodingPEM KeyEncoding = iota // PEM（默认）
+	KeyEncodingDER                    // DER
+)
+
+// KeyFormat 密钥结构
+type KeyFormat int
+
+const (
+	KeyFormatDefault KeyFormat = iota // 私钥 PKCS#8，公钥 PKIX
+	KeyFormatPKCS8                    // 私钥 PKCS#8
+	KeyFormatPKCS1                    // RSA 私钥/公钥 PKCS#1
+	KeyFormatSEC1                     // EC/SM2 私钥 SEC1
+	KeyFormatPKIX                     // 公钥 SubjectPublicKeyInfo
+)
+
+// DefaultPBKDF2Iterations 加密 PKCS#8 使用的 PBKDF2 迭代次数
+const DefaultPBKDF2Iterations = 600000
+
+// KeyOption 密钥导出选项
+type KeyOption func(*keyOptions)
+
+type keyOptions struct {
+	encoding   KeyEncoding
+	format     KeyFormat
+	passphrase []byte
+	iterations int
+}
+
+// WithKeyEncoding 设置导出编码，默认 PEM
+func WithKeyEncoding(encoding KeyEncoding) KeyOption {
+	return func(o *keyOptions) {
+		o.encoding = encoding
+	}
+}
+
+// WithKeyFormat 设置导出结构，默认私钥 PKCS#8、公钥 PKIX
+func WithKeyFormat(format KeyFormat) KeyOption {
+	return func(o *keyOptions) {
+		o.format = format
+	}
+}
+
+// WithPassphrase 使用口令加密导出的私钥（PBES2：PBKDF2-HMAC-SHA256 + AES-256-CBC），仅支持 PKCS#8
+func WithPassphrase(passphrase []byte) KeyOption {
+	return func(o *keyOptions) {
+		o.passphrase = passphrase
+	}
+}
+
+// WithPBKDF2Iterations 设置加密私钥的 PBKDF2 迭代次数
+func WithPBKDF2Iterations(iterations int) KeyOption {
+	return func(o *keyOptions) {
+		o.iterations = iterations
+	}
+}
+
+func newKeyOptions(opts []KeyOption) *keyOptions {
+	o := &keyOptions{iterations: DefaultPBKDF2Iterations}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidCurveSM2       = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidSM4CBC         = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 104, 2}
+)
+
+// ASN.1 结构
+type pkcs8Info struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+type pkixInfo struct {
+	Algo      pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type sec1Key struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// LoadPrivateKey 加载私钥，自动识别 PEM/DER 以及 PKCS#1、PKCS#8、SEC1 与加密的 PKCS#8，
+// 返回 *rsa.PrivateKey、*ecdsa.PrivateKey、*sm2.PrivateKey、*ecdh.PrivateKey 或 ed25519.PrivateKey
+func LoadPrivateKey(data []byte, passphrase []byte) (any, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if _, ok := block.Headers["DEK-Info"]; ok {
+			return nil, fmt.Errorf("%w: legacy encrypted PEM is not supported, use encrypted PKCS#8", ErrUnknownKeyFormat)
+		}
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			return x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY", "SM2 PRIVATE KEY":
+			return parseSEC1PrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			return parsePKCS8PrivateKey(block.Bytes)
+		case "ENCRYPTED PRIVATE KEY":
+			return parseEncryptedPKCS8PrivateKey(block.Bytes, passphrase)
+		default:
+			return nil, fmt.Errorf("%w: PEM type %q", ErrUnknownKeyFormat, block.Type)
+		}
+	}
+
+	if key, err := parsePKCS8PrivateKey(data); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(data); err == nil {
+		return key, nil
+	}
+	if key, err := parseSEC1PrivateKey(data); err == nil {
+		return key, nil
+	}
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(data, &info); err == nil && len(rest) == 0 && info.Algo.Algorithm.Equal(oidPBES2) {
+		return parseEncryptedPKCS8PrivateKey(data, passphrase)
+	}
+	return nil, ErrUnknownKeyFormat
+}
+
+// LoadPublicKey 加载公钥，自动识别 PEM/DER 以及 PKIX、PKCS#1 与证书，
+// 返回 *rsa.PublicKey、*ecdsa.PublicKey、*sm2.PublicKey、*ecdh.PublicKey 或 ed25519.PublicKey
+func LoadPublicKey(data []byte) (any, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		switch block.Type {
+		case "PUBLIC KEY":
+			return parsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			// RSACipher.ExportPublicKey 以该类型输出 PKIX 结构，两种结构都需兼容
+			if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+				return key, nil
+			}
+			return parsePKIXPublicKey(block.Bytes)
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			return cert.PublicKey, nil
+		default:
+			return nil, fmt.Errorf("%w: PEM type %q", ErrUnknownKeyFormat, block.Type)
+		}
+	}
+
+	if key, err := parsePKIXPublicKey(data); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(data); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(data); err == nil {
+		return cert.PublicKey, nil
+	}
+	return nil, ErrUnknownKeyFormat
+}
+
+// ExportPrivateKey 导出私钥，key 可以是标准库私钥、*sm2.PrivateKey 或 RSACipher、ECDSACipher、ECDHCipher、SM2Cipher、Ed25519Cipher、X25519Cipher
+func ExportPrivateKey(key any, opts ...KeyOption) ([]byte, error) {
+	o := newKeyOptions(opts)
+	key = unwrapPrivateKey(key)
+	if key == nil {
+		return nil, errors.New("private key is nil")
+	}
+
+	format := o.format
+	if format == KeyFormatDefault {
+		format = KeyFormatPKCS8
+	}
+	if o.passphrase != nil && format != KeyFormatPKCS8 {
+		return nil, fmt.Errorf("%w: passphrase requires PKCS#8", ErrKeyFormatUnsupported)
+	}
+
+	var (
+		der       []byte
+		blockType string
+		err       error
+	)
+	switch format {
+	case KeyFormatPKCS8:
+		blockType = "PRIVATE KEY"
+		der, err = marshalPKCS8PrivateKey(key)
+	case KeyFormatPKCS1:
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: PKCS#1 requires RSA, got %T", ErrKeyFormatUnsupported, key)
+		}
+		blockType = "RSA PRIVATE KEY"
+		der = x509.MarshalPKCS1PrivateKey(rsaKey)
+	case KeyFormatSEC1:
+		blockType = "EC PRIVATE KEY"
+		switch k := key.(type) {
+		case *ecdsa.PrivateKey:
+			der, err = x509.MarshalECPrivateKey(k)
+		case *sm2.PrivateKey:
+			der, err = marshalSM2SEC1PrivateKey(k)
+		default:
+			return nil, fmt.Errorf("%w: SEC1 requires EC or SM2, got %T", ErrKeyFormatUnsupported, key)
+		}
+	default:
+		return nil, fmt.Errorf("%w: format %d for private key", ErrKeyFormatUnsupported, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if o.passphrase != nil {
+		blockType = "ENCRYPTED PRIVATE KEY"
+		if der, err = encryptPKCS8(der, o.passphrase, o.iterations); err != nil {
+			return nil, err
+		}
+	}
+	return encodeKey(der, blockType, o.encoding), nil
+}
+
+// ExportPublicKey 导出公钥，key 可以是标准库公钥/私钥、SM2 公钥/私钥或各类 Cipher
+func ExportPublicKey(key any, opts ...KeyOption) ([]byte, error) {
+	o := newKeyOptions(opts)
+	key = unwrapPublicKey(key)
+	if key == nil {
+		return nil, errors.New("public key is nil")
+	}
+
+	switch o.format {
+	case KeyFormatDefault, KeyFormatPKIX:
+		der, err := marshalPKIXPublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return encodeKey(der, "PUBLIC KEY", o.encoding), nil
+	case KeyFormatPKCS1:
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: PKCS#1 requires RSA, got %T", ErrKeyFormatUnsupported, key)
+		}
+		return encodeKey(x509.MarshalPKCS1PublicKey(rsaKey), "RSA PUBLIC KEY", o.encoding), nil
+	default:
+		return nil, fmt.Errorf("%w: format %d for public key", ErrKeyFormatUnsupported, o.format)
+	}
+}
+
+// LoadRSACipher 从私钥或公钥创建 RSACipher，只有公钥时仅可加密与验签
+func LoadRSACipher(data []byte, passphrase []byte) (*RSACipher, error) {
+	priv, pub, err := loadKeyPair(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected RSA key, got %T", ErrUnsupportedKeyType, pub)
+	}
+	rsaPriv, _ := priv.(*rsa.PrivateKey)
+	return NewRSACipherFromKey(rsaPriv, rsaPub), nil
+}
+
+// LoadECDSACipher 从私钥或公钥创建 ECDSACipher，只有公钥时仅可验签
+func LoadECDSACipher(data []byte, passphrase []byte) (*ECDSACipher, error) {
+	priv, pub, err := loadKeyPair(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected ECDSA key, got %T", ErrUnsupportedKeyType, pub)
+	}
+	ecPriv, _ := priv.(*ecdsa.PrivateKey)
+	return NewECDSACipherFromKey(ecPriv, ecPub), nil
+}
+
+// LoadECDHCipher 从私钥或公钥创建 ECDHCipher，兼容以 EC 密钥形式保存的 NIST 曲线密钥
+func LoadECDHCipher(data []byte, passphrase []byte) (*ECDHCipher, error) {
+	priv, pub, err := loadKeyPair(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	c := &ECDHCipher{}
+	switch k := priv.(type) {
+	case *ecdh.PrivateKey:
+		c.privateKey = k
+	case *ecdsa.PrivateKey:
+		if c.privateKey, err = k.ECDH(); err != nil {
+			return nil, err
+		}
+	}
+	switch k := pub.(type) {
+	case *ecdh.PublicKey:
+		c.publicKey = k
+	case *ecdsa.PublicKey:
+		if c.publicKey, err = k.ECDH(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: expected ECDH key, got %T", ErrUnsupportedKeyType, pub)
+	}
+	return c, nil
+}
+
+// LoadSM2Cipher 从私钥或公钥创建 SM2Cipher，只有公钥时仅可加密与验签
+func LoadSM2Cipher(data []byte, passphrase []byte) (*SM2Cipher, error) {
+	priv, pub, err := loadKeyPair(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	smPub, ok := pub.(*sm2.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected SM2 key, got %T", ErrUnsupportedKeyType, pub)
+	}
+	smPriv, _ := priv.(*sm2.PrivateKey)
+	return NewSM2CipherFromKey(smPriv, smPub), nil
+}
+
+// LoadEd25519Cipher 从私钥或公钥创建 Ed25519Cipher，只有公钥时仅可验签
+func LoadEd25519Cipher(data []byte, passphrase []byte) (*Ed25519Cipher, error) {
+	priv, pub, err := loadKeyPair(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	edPub, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected Ed25519 key, got %T", ErrUnsupportedKeyType, pub)
+	}
+	edPriv, _ := priv.(ed25519.PrivateKey)
+	return NewEd25519CipherFromKey(edPriv, edPub), nil
+}
+
+// LoadX25519Cipher 从私钥创建 X25519Cipher
+func LoadX25519Cipher(data []byte, passphrase []byte) (*X25519Cipher, error) {
+	key, err := LoadPrivateKey(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := key.(*ecdh.PrivateKey)
+	if !ok || priv.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("%w: expected X25519 key, got %T", ErrUnsupportedKeyType, key)
+	}
+	return NewX25519CipherFromKey(priv)
+}
+
+// ExportAESKey 以 PEM（"AES KEY"）或原始字节导出 AES 密钥，可使用口令加密
+func ExportAESKey(key []byte, opts ...KeyOption) ([]byte, error) {
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("invalid key length: %d, must be 16, 24, or 32 bytes", len(key))
+	}
+	return exportSymmetricKey(key, "AES KEY", opts)
+}
+
+// LoadAESKey 加载 ExportAESKey 导出的 AES 密钥
+func LoadAESKey(data []byte, passphrase []byte) ([]byte, error) {
+	key, err := loadSymmetricKey(data, "AES KEY", passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("invalid key length: %d, must be 16, 24, or 32 bytes", len(key))
+	}
+	return key, nil
+}
+
+// ExportSM4Key 以 PEM（"SM4 KEY"）或原始字节导出 SM4 密钥，可使用口令加密
+func ExportSM4Key(key []byte, opts ...KeyOption) ([]byte, error) {
+	if len(key) != 16 {
+		return nil, errors.New("SM4 key length must be 16 bytes")
+	}
+	return exportSymmetricKey(key, "SM4 KEY", opts)
+}
+
+// LoadSM4Key 加载 ExportSM4Key 导出的 SM4 密钥
+func LoadSM4Key(data []byte, passphrase []byte) ([]byte, error) {
+	key, err := loadSymmetricKey(data, "SM4 KEY", passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 16 {
+		return nil, errors.New("SM4 key length must be 16 bytes")
+	}
+	return key, nil
+}
+
+func loadKeyPair(data []byte, passphrase []byte) (priv, pub any, err error) {
+	priv, err = LoadPrivateKey(data, passphrase)
+	if err == nil {
+		return priv, unwrapPublicKey(priv), nil
+	}
+	if errors.Is(err, ErrPassphraseRequired) || errors.Is(err, ErrIncorrectPassphrase) {
+		return nil, nil, err
+	}
+	pub, pubErr := LoadPublicKey(data)
+	if pubErr != nil {
+		return nil, nil, err
+	}
+	return nil, pub, nil
+}
+
+func unwrapPrivateKey(key any) any {
+	switch k := key.(type) {
+	case *RSACipher:
+		return nilIfNil(k.privateKey)
+	case *ECDSACipher:
+		return nilIfNil(k.privateKey)
+	case *ECDHCipher:
+		return nilIfNil(k.privateKey)
+	case *SM2Cipher:
+		return nilIfNil(k.privateKey)
+	case *Ed25519Cipher:
+		if k.privateKey == nil {
+			return nil
+		}
+		return k.privateKey
+	case *X25519Cipher:
+		return nilIfNil(k.privateKey)
+	}
+	return key
+}
+
+func unwrapPublicKey(key any) any {
+	switch k := key.(type) {
+	case *RSACipher:
+		return nilIfNil(k.publicKey)
+	case *ECDSACipher:
+		return nilIfNil(k.publicKey)
+	case *ECDHCipher:
+		return nilIfNil(k.publicKey)
+	case *SM2Cipher:
+		return nilIfNil(k.publicKey)
+	case *Ed25519Cipher:
+		if k.publicKey == nil {
+			return nil
+		}
+		return k.publicKey
+	case *X25519Cipher:
+		return nilIfNil(k.publicKey)
+	case *rsa.PrivateKey:
+		return &k.PublicKey
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey
+	case *ecdh.PrivateKey:
+		return k.PublicKey()
+	case ed25519.PrivateKey:
+		return k.Public()
+	case *sm2.PrivateKey:
+		return &k.PublicKey
+	}
+	return key
+}
+
+// nilIfNil 避免将 nil 指针包装成非 nil 的接口值
+func nilIfNil[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return p
+}
+
+func encodeKey(der []byte, blockType string, encoding KeyEncoding) []byte {
+	if encoding == KeyEncodingDER {
+		return der
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+// parsePKCS8PrivateKey 在标准库基础上支持 SM2 私钥（id-ecPublicKey + SM2 曲线，部分实现直接使用 SM2 曲线 OID 作为算法标识）
+func parsePKCS8PrivateKey(der []byte) (any, error) {
+	var info pkcs8Info
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) != 0 {
+		return nil, ErrUnknownKeyFormat
+	}
+	if info.Algo.Algorithm.Equal(oidCurveSM2) || isSM2Algorithm(info.Algo) {
+		return x509SM2PrivateKey(info.PrivateKey)
+	}
+	return x509.ParsePKCS8PrivateKey(der)
+}
+
+func parseSEC1PrivateKey(der []byte) (any, error) {
+	var key sec1Key
+	if rest, err := asn1.Unmarshal(der, &key); err != nil || len(rest) != 0 {
+		return nil, ErrUnknownKeyFormat
+	}
+	if key.NamedCurveOID.Equal(oidCurveSM2) {
+		return x509SM2PrivateKey(der)
+	}
+	return x509.ParseECPrivateKey(der)
+}
+
+func parsePKIXPublicKey(der []byte) (any, error) {
+	var info pkixInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) != 0 {
+		return nil, ErrUnknownKeyFormat
+	}
+	if info.Algo.Algorithm.Equal(oidCurveSM2) || isSM2Algorithm(info.Algo) {
+		return unmarshalSM2PublicKey(info.PublicKey.Bytes)
+	}
+	return x509.ParsePKIXPublicKey(der)
+}
+
+// isSM2Algorithm 判断 id-ecPublicKey 的曲线参数是否为 SM2
+func isSM2Algorithm(algo pkix.AlgorithmIdentifier) bool {
+	if !algo.Algorithm.Equal(oidPublicKeyECDSA) {
+		return false
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(algo.Parameters.FullBytes, &curve); err != nil {
+		return false
+	}
+	return curve.Equal(oidCurveSM2)
+}
+
+func sm2AlgorithmIdentifier() pkix.AlgorithmIdentifier {
+	params, _ := asn1.Marshal(oidCurveSM2)
+	return pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: params}}
+}
+
+func marshalPKCS8PrivateKey(key any) ([]byte, error) {
+	if k, ok := key.(*sm2.PrivateKey); ok {
+		sec1, err := marshalSM2SEC1PrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return asn1.Marshal(pkcs8Info{Algo: sm2AlgorithmIdentifier(), PrivateKey: sec1})
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, key)
+	}
+	return der, nil
+}
+
+func marshalPKIXPublicKey(key any) ([]byte, error) {
+	if k, ok := key.(*sm2.PublicKey); ok {
+		return asn1.Marshal(pkixInfo{
+			Algo:      sm2AlgorithmIdentifier(),
+			PublicKey: asn1.BitString{Bytes: marshalSM2Point(k), BitLength: 8 * (1 + 2*32)},
+		})
+	}
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, key)
+	}
+	return der, nil
+}
+
+func marshalSM2SEC1PrivateKey(key *sm2.PrivateKey) ([]byte, error) {
+	if key.D == nil {
+		return nil, errors.New("private key is nil")
+	}
+	d := make([]byte, 32)
+	key.D.FillBytes(d)
+	return asn1.Marshal(sec1Key{
+		Version:       1,
+		PrivateKey:    d,
+		NamedCurveOID: oidCurveSM2,
+		PublicKey:     asn1.BitString{Bytes: marshalSM2Point(&key.PublicKey), BitLength: 8 * (1 + 2*32)},
+	})
+}
+
+func marshalSM2Point(key *sm2.PublicKey) []byte {
+	point := make([]byte, 1+2*32)
+	point[0] = 4
+	key.X.FillBytes(point[1:33])
+	key.Y.FillBytes(point[33:])
+	return point
+}
+
+func unmarshalSM2PublicKey(point []byte) (*sm2.PublicKey, error) {
+	if len(point) != 1+2*32 || point[0] != 4 {
+		return nil, fmt.Errorf("%w: invalid SM2 public key", ErrUnknownKeyFormat)
+	}
+	curve := sm2.P256Sm2()
+	x := new(big.Int).SetBytes(point[1:33])
+	y := new(big.Int).SetBytes(point[33:])
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("%w: SM2 public key is not on curve", ErrUnknownKeyFormat)
+	}
+	return &sm2.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// x509SM2PrivateKey 解析 SEC1 结构的 SM2 私钥
+func x509SM2PrivateKey(der []byte) (*sm2.PrivateKey, error) {
+	var key sec1Key
+	if _, err := asn1.Unmarshal(der, &key); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKeyFormat, err)
+	}
+	curve := sm2.P256Sm2()
+	d := new(big.Int).SetBytes(key.PrivateKey)
+	if d.Sign() <= 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("%w: invalid SM2 private key", ErrUnknownKeyFormat)
+	}
+	priv := &sm2.PrivateKey{D: d}
+	priv.Curve = curve
+	priv.X, priv.Y = curve.ScalarBaseMult(d.FillBytes(make([]byte, 32)))
+	return priv, nil
+}
+
+// encryptPKCS8 使用 PBES2（PBKDF2-HMAC-SHA256 + AES-256-CBC）加密 DER 数据
+func encryptPKCS8(der, passphrase []byte, iterations int) ([]byte, error) {
+	if iterations <= 0 {
+		iterations = DefaultPBKDF2Iterations
+	}
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padded := PKCS5Padding(bytes.Clone(der), aes.BlockSize)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+
+	null := asn1.RawValue{FullBytes: asn1.NullBytes}
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:       salt,
+		Iterations: iterations,
+		PRF:        pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: null},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParams}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo:          pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: padded,
+	})
+}
+
+// decryptPKCS8 解密 PBES2 加密的数据，支持 HMAC-SHA1/SHA256/SHA384/SHA512 与 AES-CBC、SM4-CBC
+func decryptPKCS8(der, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrPassphraseRequired
+	}
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKeyFormat, err)
+	}
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("%w: only PBES2 is supported", ErrUnknownKeyFormat)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKeyFormat, err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("%w: only PBKDF2 is supported", ErrUnknownKeyFormat)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKeyFormat, err)
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0, kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA384):
+		prf = sha512.New384
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA512):
+		prf = sha512.New
+	default:
+		return nil, fmt.Errorf("%w: unsupported PRF %v", ErrUnknownKeyFormat, kdf.PRF.Algorithm)
+	}
+
+	var (
+		keyLen   int
+		newBlock func([]byte) (cipher.Block, error)
+	)
+	switch scheme := params.EncryptionScheme.Algorithm; {
+	case scheme.Equal(oidAES128CBC):
+		keyLen, newBlock = 16, aes.NewCipher
+	case scheme.Equal(oidAES192CBC):
+		keyLen, newBlock = 24, aes.NewCipher
+	case scheme.Equal(oidAES256CBC):
+		keyLen, newBlock = 32, aes.NewCipher
+	case scheme.Equal(oidSM4CBC):
+		keyLen, newBlock = 16, sm4.NewCipher
+	default:
+		return nil, fmt.Errorf("%w: unsupported encryption scheme %v", ErrUnknownKeyFormat, scheme)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKeyFormat, err)
+	}
+
+	key, err := pbkdf2.Key(prf, string(passphrase), kdf.Salt, kdf.Iterations, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(info.EncryptedData) == 0 || len(info.EncryptedData)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("%w: invalid encrypted data", ErrUnknownKeyFormat)
+	}
+	plain := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, info.EncryptedData)
+	plain = PKCS5UnPadding(plain)
+	if len(plain) == 0 {
+		return nil, ErrIncorrectPassphrase
+	}
+	return plain, nil
+}
+
+func parseEncryptedPKCS8PrivateKey(der, passphrase []byte) (any, error) {
+	plain, err := decryptPKCS8(der, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePKCS8PrivateKey(plain)
+	if err != nil {
+		// 口令错误时填充可能恰好合法，解析失败同样视为口令错误
+		return nil, ErrIncorrectPassphrase
+	}
+	return key, nil
+}
+
+func exportSymmetricKey(key []byte, blockType string, opts []KeyOption) ([]byte, error) {
+	o := newKeyOptions(opts)
+	der := bytes.Clone(key)
+	if o.passphrase != nil {
+		var err error
+		if der, err = encryptSymmetricKey(key, o.passphrase, o.iterations); err != nil {
+			return nil, err
+		}
+		blockType = "ENCRYPTED " + blockType
+	}
+	return encodeKey(der, blockType, o.encoding), nil
+}
+
+// encryptSymmetricKey 将对称密钥包装为 ASN.1 OCTET STRING 后加密。
+// 仅靠 PKCS#7 填充无法可靠识别错误口令（约 1/256 的概率填充恰好合法），
+// 解密后需要校验该结构
+func encryptSymmetricKey(key, passphrase []byte, iterations int) ([]byte, error) {
+	wrapped, err := asn1.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	return encryptPKCS8(wrapped, passphrase, iterations)
+}
+
+// decryptSymmetricKey 解密并校验 OCTET STRING 结构，校验失败视为口令错误
+func decryptSymmetricKey(der, passphrase []byte) ([]byte, error) {
+	plaintext, err := decryptPKCS8(der, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var key []byte
+	rest, err := asn1.Unmarshal(plaintext, &key)
+	if err != nil || len(rest) != 0 || len(key) == 0 {
+		return nil, ErrIncorrectPassphrase
+	}
+	return key, nil
+}
+
+func loadSymmetricKey(data []byte, blockType string, passphrase []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		// DER：原始字节或加密结构
+		if passphrase != nil {
+			return decryptSymmetricKey(data, passphrase)
+		}
+		return bytes.Clone(data), nil
+	}
+	switch block.Type {
+	case blockType:
+		return block.Bytes, nil
+	case "ENCRYPTED " + blockType:
+		return decryptSymmetricKey(block.Bytes, passphrase)
+	default:
+		return nil, fmt.Errorf("%w: PEM type %q", ErrUnknownKeyFormat, block.Type)
+	}
+}
@@ -0,0 +1,309 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// 测试中降低迭代次数以加快速度
+var testKDF = WithPBKDF2Iterations(1000)
+
+func TestKeys_PrivateKeyFormats(t *testing.T) {
+	rsaCipher, err := NewRSACipher(2048)
+	require.NoError(t, err)
+	ecdsaCipher, err := NewECDSACipher()
+	require.NoError(t, err)
+	sm2Cipher, err := NewSM2Cipher()
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	x25519Key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		key     any
+		formats []KeyFormat
+	}{
+		{"RSA", rsaCipher, []KeyFormat{KeyFormatPKCS8, KeyFormatPKCS1}},
+		{"ECDSA", ecdsaCipher, []KeyFormat{KeyFormatPKCS8, KeyFormatSEC1}},
+		{"SM2", sm2Cipher, []KeyFormat{KeyFormatPKCS8, KeyFormatSEC1}},
+		{"Ed25519", edKey, []KeyFormat{KeyFormatPKCS8}},
+		{"X25519", x25519Key, []KeyFormat{KeyFormatPKCS8}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := unwrapPrivateKey(tc.key)
+			for _, format := range tc.formats {
+				for _, encoding := range []KeyEncoding{KeyEncodingPEM, KeyEncodingDER} {
+					data, err := ExportPrivateKey(tc.key, WithKeyFormat(format), WithKeyEncoding(encoding))
+					require.NoError(t, err)
+
+					loaded, err := LoadPrivateKey(data, nil)
+					require.NoError(t, err)
+					assert.Equal(t, want, loaded, "format %d encoding %d", format, encoding)
+				}
+			}
+
+			data, err := ExportPrivateKey(tc.key, WithPassphrase([]byte("secret")), testKDF)
+			require.NoError(t, err)
+			block, _ := pem.Decode(data)
+			require.NotNil(t, block)
+			assert.Equal(t, "ENCRYPTED PRIVATE KEY", block.Type)
+
+			loaded, err := LoadPrivateKey(data, []byte("secret"))
+			require.NoError(t, err)
+			assert.Equal(t, want, loaded)
+
+			_, err = LoadPrivateKey(data, nil)
+			assert.ErrorIs(t, err, ErrPassphraseRequired)
+			_, err = LoadPrivateKey(data, []byte("wrong"))
+			assert.ErrorIs(t, err, ErrIncorrectPassphrase)
+
+			// DER 形式的加密私钥同样可以识别
+			loaded, err = LoadPrivateKey(block.Bytes, []byte("secret"))
+			require.NoError(t, err)
+			assert.Equal(t, want, loaded)
+		})
+	}
+}
+
+func TestKeys_UnsupportedFormat(t *testing.T) {
+	ecdsaCipher, err := NewECDSACipher()
+	require.NoError(t, err)
+
+	_, err = ExportPrivateKey(ecdsaCipher, WithKeyFormat(KeyFormatPKCS1))
+	assert.ErrorIs(t, err, ErrKeyFormatUnsupported)
+	_, err = ExportPrivateKey(ecdsaCipher, WithKeyFormat(KeyFormatSEC1), WithPassphrase([]byte("secret")))
+	assert.ErrorIs(t, err, ErrKeyFormatUnsupported)
+	_, err = ExportPublicKey(ecdsaCipher, WithKeyFormat(KeyFormatPKCS1))
+	assert.ErrorIs(t, err, ErrKeyFormatUnsupported)
+
+	_, err = LoadPrivateKey([]byte("not a key"), nil)
+	assert.ErrorIs(t, err, ErrUnknownKeyFormat)
+	_, err = LoadPublicKey([]byte("not a key"))
+	assert.ErrorIs(t, err, ErrUnknownKeyFormat)
+}
+
+func TestKeys_PublicKeyFormats(t *testing.T) {
+	rsaCipher, err := NewRSACipher(2048)
+	require.NoError(t, err)
+	sm2Cipher, err := NewSM2Cipher()
+	require.NoError(t, err)
+	ecdhCipher, err := NewECDHCipher()
+	require.NoError(t, err)
+
+	for _, key := range []any{rsaCipher, sm2Cipher, ecdhCipher} {
+		for _, encoding := range []KeyEncoding{KeyEncodingPEM, KeyEncodingDER} {
+			data, err := ExportPublicKey(key, WithKeyEncoding(encoding))
+			require.NoError(t, err)
+			loaded, err := LoadPublicKey(data)
+			require.NoError(t, err)
+			// NIST 曲线的 ECDH 公钥按标准库约定加载为 *ecdsa.PublicKey，这里比较重新导出的结果
+			again, err := ExportPublicKey(loaded, WithKeyEncoding(encoding))
+			require.NoError(t, err)
+			assert.Equal(t, data, again)
+		}
+	}
+
+	pkcs1, err := ExportPublicKey(rsaCipher, WithKeyFormat(KeyFormatPKCS1))
+	require.NoError(t, err)
+	loaded, err := LoadPublicKey(pkcs1)
+	require.NoError(t, err)
+	assert.Equal(t, rsaCipher.PublicKey(), loaded)
+
+	// 兼容 RSACipher.ExportPublicKey 输出的 "RSA PUBLIC KEY"（PKIX 结构）
+	legacy, err := rsaCipher.ExportPublicKey()
+	require.NoError(t, err)
+	loaded, err = LoadPublicKey([]byte(legacy))
+	require.NoError(t, err)
+	assert.Equal(t, rsaCipher.PublicKey(), loaded)
+}
+
+func TestKeys_LoadCiphers(t *testing.T) {
+	t.Run("RSA", func(t *testing.T) {
+		rsaCipher, err := NewRSACipher(2048)
+		require.NoError(t, err)
+		legacy, err := rsaCipher.ExportPrivateKey()
+		require.NoError(t, err)
+
+		loaded, err := LoadRSACipher([]byte(legacy), nil)
+		require.NoError(t, err)
+		sig, err := loaded.Sign([]byte("data"))
+		require.NoError(t, err)
+		ok, err := rsaCipher.Verify([]byte("data"), sig)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		pub, err := ExportPublicKey(rsaCipher)
+		require.NoError(t, err)
+		pubOnly, err := LoadRSACipher(pub, nil)
+		require.NoError(t, err)
+		assert.Nil(t, pubOnly.PrivateKey())
+		ok, err = pubOnly.Verify([]byte("data"), sig)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("ECDSA", func(t *testing.T) {
+		ecdsaCipher, err := NewECDSACipher()
+		require.NoError(t, err)
+		data, err := ExportPrivateKey(ecdsaCipher, WithPassphrase([]byte("secret")), testKDF)
+		require.NoError(t, err)
+
+		loaded, err := LoadECDSACipher(data, []byte("secret"))
+		require.NoError(t, err)
+		sig, err := loaded.Sign([]byte("data"))
+		require.NoError(t, err)
+		ok, err := ecdsaCipher.Verify([]byte("data"), sig)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		_, err = LoadECDSACipher(data, []byte("wrong"))
+		assert.ErrorIs(t, err, ErrIncorrectPassphrase)
+	})
+
+	t.Run("ECDH", func(t *testing.T) {
+		alice, err := NewECDHCipher()
+		require.NoError(t, err)
+		bob, err := NewECDHCipher()
+		require.NoError(t, err)
+
+		// P-256 的 ECDH 私钥按 EC 密钥保存，加载时转换回 ECDH
+		data, err := ExportPrivateKey(alice, WithKeyFormat(KeyFormatPKCS8))
+		require.NoError(t, err)
+		loaded, err := LoadECDHCipher(data, nil)
+		require.NoError(t, err)
+
+		s1, err := loaded.DeriveSharedSecret(bob.PublicKeyBytes())
+		require.NoError(t, err)
+		s2, err := bob.DeriveSharedSecret(alice.PublicKeyBytes())
+		require.NoError(t, err)
+		assert.Equal(t, s1, s2)
+	})
+
+	t.Run("SM2", func(t *testing.T) {
+		sm2Cipher, err := NewSM2Cipher()
+		require.NoError(t, err)
+		data, err := ExportPrivateKey(sm2Cipher, WithKeyFormat(KeyFormatSEC1))
+		require.NoError(t, err)
+
+		loaded, err := LoadSM2Cipher(data, nil)
+		require.NoError(t, err)
+		ciphertext, err := sm2Cipher.Encrypt([]byte("hello sm2"))
+		require.NoError(t, err)
+		plain, err := loaded.Decrypt(ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello sm2"), plain)
+
+		_, err = LoadRSACipher(data, nil)
+		assert.ErrorIs(t, err, ErrUnsupportedKeyType)
+	})
+}
+
+func TestKeys_GMSMCompat(t *testing.T) {
+	sm2Cipher, err := NewSM2Cipher()
+	require.NoError(t, err)
+
+	// gmsm 导出的加密私钥使用 PBKDF2-HMAC-SHA1
+	der, err := x509.MarshalSm2PrivateKey(sm2Cipher.PrivateKey(), []byte("secret"))
+	require.NoError(t, err)
+	loaded, err := LoadPrivateKey(der, []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, sm2Cipher.PrivateKey(), loaded)
+
+	data, err := ExportPrivateKey(sm2Cipher, WithKeyEncoding(KeyEncodingDER))
+	require.NoError(t, err)
+	parsed, err := x509.ParsePKCS8UnecryptedPrivateKey(data)
+	require.NoError(t, err)
+	assert.Equal(t, sm2Cipher.PrivateKey().D, parsed.D)
+
+	data, err = ExportPublicKey(sm2Cipher, WithKeyEncoding(KeyEncodingDER))
+	require.NoError(t, err)
+	pub, err := x509.ParseSm2PublicKey(data)
+	require.NoError(t, err)
+	assert.Equal(t, sm2Cipher.PublicKey().X, pub.X)
+}
+
+func TestKeys_SymmetricKeys(t *testing.T) {
+	aesKey, err := GenerateAESKey(32)
+	require.NoError(t, err)
+	sm4Key, err := GenerateSM4Key()
+	require.NoError(t, err)
+
+	data, err := ExportAESKey(aesKey)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "AES KEY")
+	loaded, err := LoadAESKey(data, nil)
+	require.NoError(t, err)
+	assert.Equal(t, aesKey, loaded)
+
+	data, err = ExportSM4Key(sm4Key, WithPassphrase([]byte("secret")), testKDF)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "ENCRYPTED SM4 KEY")
+	loaded, err = LoadSM4Key(data, []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, sm4Key, loaded)
+	_, err = LoadSM4Key(data, nil)
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+
+	// PEM 类型不匹配
+	_, err = LoadAESKey(data, []byte("secret"))
+	assert.ErrorIs(t, err, ErrUnknownKeyFormat)
+
+	data, err = ExportAESKey(aesKey, WithKeyEncoding(KeyEncodingDER))
+	require.NoError(t, err)
+	assert.Equal(t, aesKey, data)
+
+	_, err = ExportSM4Key(aesKey)
+	assert.Error(t, err)
+}
+
+func TestKeys_SymmetricKeyWrongPassphrase(t *testing.T) {
+	aesKey, err := GenerateAESKey(16)
+	require.NoError(t, err)
+	pemData, err := ExportAESKey(aesKey, WithPassphrase([]byte("secret")), WithPBKDF2Iterations(1))
+	require.NoError(t, err)
+	derData, err := ExportAESKey(aesKey, WithPassphrase([]byte("secret")), WithPBKDF2Iterations(1), WithKeyEncoding(KeyEncodingDER))
+	require.NoError(t, err)
+
+	// 错误口令约 1/256 的概率得到合法填充，必须始终返回 ErrIncorrectPassphrase
+	for i := range 2000 {
+		passphrase := []byte(fmt.Sprintf("wrong-%d", i))
+		_, err := LoadAESKey(pemData, passphrase)
+		require.ErrorIs(t, err, ErrIncorrectPassphrase, "passphrase %q", passphrase)
+		_, err = LoadAESKey(derData, passphrase)
+		require.ErrorIs(t, err, ErrIncorrectPassphrase, "passphrase %q", passphrase)
+	}
+
+	loaded, err := LoadAESKey(derData, []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, aesKey, loaded)
+}
+
+func TestKeys_UnwrapKeys(t *testing.T) {
+	rsaCipher, err := NewRSACipher(2048)
+	require.NoError(t, err)
+	assert.IsType(t, &rsa.PrivateKey{}, unwrapPrivateKey(rsaCipher))
+	assert.IsType(t, &rsa.PublicKey{}, unwrapPublicKey(rsaCipher))
+
+	pubOnly := NewECDSACipherFromKey(nil, &ecdsa.PublicKey{})
+	assert.Nil(t, unwrapPrivateKey(pubOnly))
+	_, err = ExportPrivateKey(pubOnly)
+	assert.Error(t, err)
+
+	sm2Key, err := sm2.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, &sm2Key.PublicKey, unwrapPublicKey(sm2Key))
+}
@@ -0,0 +1,302 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 自描述密文格式：
+//
+//	magic "GE" | 版本(1) | 算法名长度(1) | 算法名 | 密钥 ID 长度(1) | 密钥 ID | nonce 长度(1) | nonce | 密文
+//
+// 头部不参与认证，篡改后只会导致找不到密钥或解密失败；完整性依赖 AEAD 算法自身
+const cipherEnvelopeVersion = 1
+
+var cipherEnvelopeMagic = []byte("GE")
+
+// CipherEnvelope 带算法与密钥 ID 的自描述密文
+type CipherEnvelope struct {
+	Algorithm string // 算法名称，与 Cipher.Name() 及注册名一致
+	KeyID     string // 密钥 ID
+	Nonce     []byte // 算法自行生成的 nonce/IV，可为空
+	Payload   []byte // 去掉 nonce 后的密文
+}
+
+// MarshalBinary 编码为紧凑的二进制格式
+func (e *CipherEnvelope) MarshalBinary() ([]byte, error) {
+	if e.Algorithm == "" {
+		return nil, errors.New("algorithm is empty")
+	}
+	for _, field := range [][]byte{[]byte(e.Algorithm), []byte(e.KeyID), e.Nonce} {
+		if len(field) > 255 {
+			return nil, errors.New("envelope field exceeds 255 bytes")
+		}
+	}
+
+	out := make([]byte, 0, len(cipherEnvelopeMagic)+4+len(e.Algorithm)+len(e.KeyID)+len(e.Nonce)+len(e.Payload))
+	out = append(out, cipherEnvelopeMagic...)
+	out = append(out, cipherEnvelopeVersion)
+	out = append(out, byte(len(e.Algorithm)))
+	out = append(out, e.Algorithm...)
+	out = append(out, byte(len(e.KeyID)))
+	out = append(out, e.KeyID...)
+	out = append(out, byte(len(e.Nonce)))
+	out = append(out, e.Nonce...)
+	return append(out, e.Payload...), nil
+}
+
+// ParseCipherEnvelope 解析自描述密文
+func ParseCipherEnvelope(data []byte) (*CipherEnvelope, error) {
+	if !bytes.HasPrefix(data, cipherEnvelopeMagic) {
+		return nil, fmt.Errorf("%w: not a cipher envelope", ErrInvalidCiphertext)
+	}
+	data = data[len(cipherEnvelopeMagic):]
+	if len(data) == 0 || data[0] != cipherEnvelopeVersion {
+		return nil, fmt.Errorf("%w: unsupported envelope version", ErrInvalidCiphertext)
+	}
+	data = data[1:]
+
+	var fields [3][]byte
+	for i := range fields {
+		if len(data) == 0 || len(data) < 1+int(data[0]) {
+			return nil, fmt.Errorf("%w: truncated envelope", ErrInvalidCiphertext)
+		}
+		n := int(data[0])
+		fields[i], data = data[1:1+n], data[1+n:]
+	}
+	if len(fields[0]) == 0 {
+		return nil, fmt.Errorf("%w: missing algorithm", ErrInvalidCiphertext)
+	}
+	return &CipherEnvelope{
+		Algorithm: string(fields[0]),
+		KeyID:     string(fields[1]),
+		Nonce:     fields[2],
+		Payload:   data,
+	}, nil
+}
+
+// nonceSizer 由输出格式为 nonce|密文 的 Cipher 实现，信封会单独记录 nonce
+type nonceSizer interface {
+	NonceSize() int
+}
+
+type multiCipherEntry struct {
+	algorithm string
+	cipher    Cipher
+}
+
+// MultiCipher 使用激活的密钥与算法加密并输出 CipherEnvelope，
+// 解密时按信封中的密钥 ID 与算法自动选择 Cipher，可用于逐步迁移算法（如 AES-CBC 到 AES-GCM）与轮换密钥
+type MultiCipher struct {
+	mu     sync.RWMutex
+	keys   map[string]*multiCipherEntry
+	active string
+	legacy Cipher
+}
+
+// MultiCipherOption MultiCipher 选项
+type MultiCipherOption func(*MultiCipher)
+
+// WithLegacyCipher 设置旧数据的 Cipher：无法解析为信封，或信封中的密钥 ID、算法与已注册密钥不匹配的密文交给它解密，
+// 用于迁移前未带信封的存量数据；匹配到密钥但解密失败时直接返回错误
+func WithLegacyCipher(c Cipher) MultiCipherOption {
+	return func(m *MultiCipher) {
+		m.legacy = c
+	}
+}
+
+// NewMultiCipher 创建 MultiCipher
+func NewMultiCipher(opts ...MultiCipherOption) *MultiCipher {
+	m := &MultiCipher{keys: make(map[string]*multiCipherEntry)}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// AddKey 通过注册表按算法名创建 Cipher 并以 keyID 登记；第一个可加密的密钥自动成为激活密钥，
+// 仅解密的算法（如 "AES"）只用于读取旧数据
+func (m *MultiCipher) AddKey(keyID, algorithm string, key []byte) error {
+	factory, err := LookupCipher(algorithm)
+	if err != nil {
+		return err
+	}
+	c, err := factory(key)
+	if err != nil {
+		return err
+	}
+	return m.add(keyID, algorithm, c)
+}
+
+// AddCipher 直接登记已创建的 Cipher，算法名取自 Cipher.Name()
+func (m *MultiCipher) AddCipher(keyID string, c Cipher) error {
+	if c == nil {
+		return errors.New("cipher is nil")
+	}
+	return m.add(keyID, c.Name(), c)
+}
+
+func (m *MultiCipher) add(keyID, algorithm string, c Cipher) error {
+	if keyID == "" {
+		return errors.New("key id is empty")
+	}
+	if len(keyID) > 255 || len(algorithm) > 255 {
+		return errors.New("key id or algorithm exceeds 255 bytes")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.keys[keyID]; ok {
+		return fmt.Errorf("%w: %s", ErrKeyExists, keyID)
+	}
+	m.keys[keyID] = &multiCipherEntry{algorithm: algorithm, cipher: c}
+	if _, ok := c.(decryptOnly); !ok && m.active == "" {
+		m.active = keyID
+	}
+	return nil
+}
+
+// SetActive 设置激活密钥，之后的加密都使用该密钥及其算法
+func (m *MultiCipher) SetActive(keyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.keys[keyID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	if _, ok := entry.cipher.(decryptOnly); ok {
+		return fmt.Errorf("%w: %s (%s)", ErrDecryptOnly, keyID, entry.algorithm)
+	}
+	m.active = keyID
+	return nil
+}
+
+// RemoveKey 移除不再使用的密钥
+func (m *MultiCipher) RemoveKey(keyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.keys[keyID]; !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	if keyID == m.active {
+		return ErrRemoveActiveKey
+	}
+	delete(m.keys, keyID)
+	return nil
+}
+
+// ActiveKeyID 返回激活密钥 ID
+func (m *MultiCipher) ActiveKeyID() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.active
+}
+
+// KeyIDs 按字母序返回所有密钥 ID
+func (m *MultiCipher) KeyIDs() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.keys))
+	for id := range m.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Encrypt 使用激活密钥加密，输出 CipherEnvelope 编码
+func (m *MultiCipher) Encrypt(plain []byte) ([]byte, error) {
+	m.mu.RLock()
+	id, entry := m.active, m.keys[m.active]
+	m.mu.RUnlock()
+
+	if entry == nil {
+		return nil, ErrNoActiveKey
+	}
+	ciphertext, err := entry.cipher.Encrypt(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	env := &CipherEnvelope{Algorithm: entry.algorithm, KeyID: id, Payload: ciphertext}
+	if ns, ok := entry.cipher.(nonceSizer); ok {
+		n := ns.NonceSize()
+		if len(ciphertext) < n {
+			return nil, fmt.Errorf("%s: ciphertext shorter than nonce", entry.algorithm)
+		}
+		env.Nonce, env.Payload = ciphertext[:n], ciphertext[n:]
+	}
+	return env.MarshalBinary()
+}
+
+// Decrypt 按信封中的密钥 ID 与算法解密；设置了 WithLegacyCipher 时，不是信封的密文，
+// 以及信封中的密钥 ID 或算法与已注册密钥不匹配的密文（旧密文恰好以信封魔数开头）交给旧 Cipher。
+// 匹配到密钥后解密失败（如 AEAD 认证失败）不会回退，避免篡改后的密文绕过认证
+func (m *MultiCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	env, err := ParseCipherEnvelope(ciphertext)
+	if err != nil {
+		if m.legacy != nil {
+			return m.legacy.Decrypt(ciphertext)
+		}
+		return nil, err
+	}
+
+	entry, err := m.lookupEntry(env)
+	if err != nil {
+		if m.legacy != nil {
+			if plain, legacyErr := m.legacy.Decrypt(ciphertext); legacyErr == nil {
+				return plain, nil
+			}
+		}
+		return nil, err
+	}
+	return entry.cipher.Decrypt(append(append([]byte(nil), env.Nonce...), env.Payload...))
+}
+
+// lookupEntry 查找信封中的密钥 ID 对应的密钥，并校验算法一致
+func (m *MultiCipher) lookupEntry(env *CipherEnvelope) (*multiCipherEntry, error) {
+	m.mu.RLock()
+	entry := m.keys[env.KeyID]
+	m.mu.RUnlock()
+
+	if entry == nil {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, env.KeyID)
+	}
+	if entry.algorithm != env.Algorithm {
+		return nil, fmt.Errorf("%w: key %s uses %s, envelope says %s", ErrInvalidCiphertext, env.KeyID, entry.algorithm, env.Algorithm)
+	}
+	return entry, nil
+}
+
+// NeedsRewrap 判断密文是否需要迁移：未使用信封，或不是用激活密钥加密的
+func (m *MultiCipher) NeedsRewrap(ciphertext []byte) bool {
+	env, err := ParseCipherEnvelope(ciphertext)
+	if err != nil {
+		return true
+	}
+	return env.KeyID != m.ActiveKeyID()
+}
+
+// Rewrap 将密文解密后用激活密钥重新加密；已是激活密钥加密的密文原样返回
+func (m *MultiCipher) Rewrap(ciphertext []byte) ([]byte, error) {
+	if !m.NeedsRewrap(ciphertext) {
+		return ciphertext, nil
+	}
+	plain, err := m.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return m.Encrypt(plain)
+}
+
+// Name 返回算法名称
+func (m *MultiCipher) Name() string {
+	return "MultiCipher"
+}
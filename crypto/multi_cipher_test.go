@@ -0,0 +1,232 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/subtle"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipherEnvelope_MarshalParse(t *testing.T) {
+	env := &CipherEnvelope{Algorithm: "AES-GCM", KeyID: "2024-01", Nonce: []byte("123456789012"), Payload: []byte("payload")}
+	data, err := env.MarshalBinary()
+	require.NoError(t, err)
+
+	parsed, err := ParseCipherEnvelope(data)
+	require.NoError(t, err)
+	assert.Equal(t, env, parsed)
+
+	for i := 0; i < len(data)-len(env.Payload); i++ {
+		_, err := ParseCipherEnvelope(data[:i])
+		assert.ErrorIs(t, err, ErrInvalidCiphertext, "truncated at %d", i)
+	}
+
+	_, err = (&CipherEnvelope{}).MarshalBinary()
+	assert.Error(t, err)
+}
+
+func TestMultiCipher_Dispatch(t *testing.T) {
+	m := NewMultiCipher()
+	_, err := m.Encrypt([]byte("data"))
+	assert.ErrorIs(t, err, ErrNoActiveKey)
+
+	aesKey, _ := GenerateAESKey(32)
+	sm4Key, _ := GenerateSM4Key()
+	chachaKey, _ := GenerateXChaCha20Key()
+	require.NoError(t, m.AddKey("k1", "AES-GCM", aesKey))
+	require.NoError(t, m.AddKey("k2", "SM4-CBC", sm4Key))
+	require.NoError(t, m.AddKey("k3", "XChaCha20-Poly1305", chachaKey))
+	assert.ErrorIs(t, m.AddKey("k1", "AES-GCM", aesKey), ErrKeyExists)
+	assert.ErrorIs(t, m.AddKey("k4", "ROT13", aesKey), ErrAlgorithmNotFound)
+	assert.Equal(t, "k1", m.ActiveKeyID())
+
+	plain := []byte("hello, multi cipher!")
+	var ciphertexts [][]byte
+	for _, id := range []string{"k1", "k2", "k3"} {
+		require.NoError(t, m.SetActive(id))
+		ct, err := m.Encrypt(plain)
+		require.NoError(t, err)
+
+		env, err := ParseCipherEnvelope(ct)
+		require.NoError(t, err)
+		assert.Equal(t, id, env.KeyID)
+		assert.NotEmpty(t, env.Nonce)
+		ciphertexts = append(ciphertexts, ct)
+	}
+
+	// 任意密钥加密的密文都可以解密
+	for _, ct := range ciphertexts {
+		decrypted, err := m.Decrypt(ct)
+		require.NoError(t, err)
+		assert.Equal(t, plain, decrypted)
+	}
+
+	require.NoError(t, m.RemoveKey("k1"))
+	_, err = m.Decrypt(ciphertexts[0])
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.ErrorIs(t, m.RemoveKey("k3"), ErrRemoveActiveKey)
+	assert.Equal(t, []string{"k2", "k3"}, m.KeyIDs())
+}
+
+func TestMultiCipher_AlgorithmMismatch(t *testing.T) {
+	aesKey, _ := GenerateAESKey(16)
+	m := NewMultiCipher()
+	require.NoError(t, m.AddKey("k1", "AES-GCM", aesKey))
+
+	ct, err := m.Encrypt([]byte("data"))
+	require.NoError(t, err)
+	env, _ := ParseCipherEnvelope(ct)
+	env.Algorithm = "SM4-GCM"
+	forged, _ := env.MarshalBinary()
+
+	_, err = m.Decrypt(forged)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestMultiCipher_MigrateFromAESCBC(t *testing.T) {
+	key, _ := GenerateAESKey(32)
+	legacy := NewAESCipher(key, nil)
+	old, err := legacy.Encrypt([]byte("stored before migration"))
+	require.NoError(t, err)
+
+	m := NewMultiCipher(WithLegacyCipher(legacy))
+	require.NoError(t, m.AddCipher("cbc", legacy))
+	newKey, _ := GenerateAESKey(32)
+	require.NoError(t, m.AddKey("gcm", "AES-GCM", newKey))
+	require.NoError(t, m.SetActive("gcm"))
+
+	// 未带信封的旧数据通过 legacy 解密
+	plain, err := m.Decrypt(old)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("stored before migration"), plain)
+	assert.True(t, m.NeedsRewrap(old))
+
+	// 逐条迁移到激活的 AES-GCM 密钥
+	migrated, err := m.Rewrap(old)
+	require.NoError(t, err)
+	assert.False(t, m.NeedsRewrap(migrated))
+	env, err := ParseCipherEnvelope(migrated)
+	require.NoError(t, err)
+	assert.Equal(t, "AES-GCM", env.Algorithm)
+
+	plain, err = m.Decrypt(migrated)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("stored before migration"), plain)
+
+	same, err := m.Rewrap(migrated)
+	require.NoError(t, err)
+	assert.Equal(t, migrated, same)
+}
+
+// echoCipher 把任何输入都当作合法密文，用于确认不会回退到 legacy
+type echoCipher struct{}
+
+func (echoCipher) Encrypt(plain []byte) ([]byte, error)   { return plain, nil }
+func (echoCipher) Decrypt(crypted []byte) ([]byte, error) { return crypted, nil }
+func (echoCipher) Name() string                           { return "echo" }
+
+// failCipher 拒绝任何密文
+type failCipher struct{}
+
+func (failCipher) Encrypt([]byte) ([]byte, error) { return nil, errors.New("fail") }
+func (failCipher) Decrypt([]byte) ([]byte, error) { return nil, errors.New("fail") }
+func (failCipher) Name() string                   { return "fail" }
+
+func TestMultiCipher_NoLegacyFallbackForEnvelope(t *testing.T) {
+	key, _ := GenerateAESKey(32)
+	m := NewMultiCipher(WithLegacyCipher(echoCipher{}))
+	require.NoError(t, m.AddKey("gcm", "AES-GCM", key))
+
+	ct, err := m.Encrypt([]byte("authenticated"))
+	require.NoError(t, err)
+
+	// 篡改后 AEAD 认证失败，必须返回错误而不是交给 legacy
+	tampered := append([]byte(nil), ct...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, err = m.Decrypt(tampered)
+	assert.Error(t, err)
+
+	// 未知密钥 ID 可能是恰好以信封魔数开头的旧密文，交给 legacy；legacy 也失败时返回原错误
+	env, _ := ParseCipherEnvelope(ct)
+	env.KeyID = "missing"
+	forged, _ := env.MarshalBinary()
+	plain, err := m.Decrypt(forged)
+	require.NoError(t, err)
+	assert.Equal(t, forged, plain)
+
+	_, err = NewMultiCipher(WithLegacyCipher(failCipher{})).Decrypt(forged)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// 不是信封的密文仍交给 legacy
+	plain, err = m.Decrypt([]byte("legacy data"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("legacy data"), plain)
+}
+
+func TestMultiCipher_LegacyCiphertextWithEnvelopeMagic(t *testing.T) {
+	key, _ := GenerateAESKey(32)
+	legacy := NewAESCipher(key, nil)
+	gcmKey, _ := GenerateAESKey(32)
+
+	// 未知密钥 ID，以及已注册密钥 ID 但算法不同
+	for _, head := range []string{"GE\x01\x03CBC\x03old\x00abcd", "GE\x01\x03CBC\x03gcm\x00abcd"} {
+		// 构造首个密文块恰好是合法信封头的 AES-CBC 旧数据（IV 为 key[:16]）
+		block, err := aes.NewCipher(key)
+		require.NoError(t, err)
+		first := make([]byte, aes.BlockSize)
+		block.Decrypt(first, []byte(head))
+		subtle.XORBytes(first, first, key[:aes.BlockSize])
+		plain := append(first, []byte("stored before envelopes")...)
+
+		old, err := legacy.Encrypt(plain)
+		require.NoError(t, err)
+		require.Equal(t, []byte(head), old[:aes.BlockSize])
+		_, err = ParseCipherEnvelope(old)
+		require.NoError(t, err)
+
+		m := NewMultiCipher(WithLegacyCipher(legacy))
+		require.NoError(t, m.AddKey("gcm", "AES-GCM", gcmKey))
+		got, err := m.Decrypt(old)
+		require.NoError(t, err)
+		assert.Equal(t, plain, got)
+
+		// 没有 legacy 时仍按信封报错
+		noLegacy := NewMultiCipher()
+		require.NoError(t, noLegacy.AddKey("gcm", "AES-GCM", gcmKey))
+		_, err = noLegacy.Decrypt(old)
+		assert.Error(t, err)
+	}
+}
+
+func TestMultiCipher_LegacyAESDecryptOnly(t *testing.T) {
+	key, _ := GenerateAESKey(32)
+	legacy, err := NewAESCipher(key, nil).Encrypt([]byte("old"))
+	require.NoError(t, err)
+	env := &CipherEnvelope{Algorithm: "AES", KeyID: "old", Payload: legacy}
+	old, err := env.MarshalBinary()
+	require.NoError(t, err)
+
+	m := NewMultiCipher()
+	require.NoError(t, m.AddKey("old", "AES", key))
+	assert.Empty(t, m.ActiveKeyID())
+	_, err = m.Encrypt([]byte("data"))
+	assert.ErrorIs(t, err, ErrNoActiveKey)
+	assert.ErrorIs(t, m.SetActive("old"), ErrDecryptOnly)
+
+	// 旧密钥仍可解密，并迁移到新算法
+	newKey, _ := GenerateAESKey(32)
+	require.NoError(t, m.AddKey("gcm", "AES-GCM", newKey))
+	assert.Equal(t, "gcm", m.ActiveKeyID())
+	plain, err := m.Decrypt(old)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old"), plain)
+
+	migrated, err := m.Rewrap(old)
+	require.NoError(t, err)
+	env, err = ParseCipherEnvelope(migrated)
+	require.NoError(t, err)
+	assert.Equal(t, "AES-GCM", env.Algorithm)
+}
@@ -0,0 +1,234 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"sync"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+var (
+	ErrAlgorithmNotFound = errors.New("crypto: algorithm not registered")
+	ErrDecryptOnly       = errors.New("crypto: cipher is decrypt-only")
+)
+
+// CipherFactory 根据密钥创建 Cipher
+type CipherFactory func(key []byte) (Cipher, error)
+
+// HasherFactory 创建 Hasher
+type HasherFactory func() Hasher
+
+// SignerFactory 根据私钥（PEM 或 DER）创建 Signer
+type SignerFactory func(privateKey []byte) (Signer, error)
+
+// VerifierFactory 根据公钥（PEM 或 DER）创建 Verifier
+type VerifierFactory func(publicKey []byte) (Verifier, error)
+
+type registry[F any] struct {
+	mu        sync.RWMutex
+	factories map[string]F
+}
+
+func newRegistry[F any]() *registry[F] {
+	return &registry[F]{factories: make(map[string]F)}
+}
+
+func (r *registry[F]) register(name string, factory F, isNil bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if isNil {
+		delete(r.factories, name)
+		return
+	}
+	r.factories[name] = factory
+}
+
+func (r *registry[F]) lookup(name string) (F, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	factory, ok := r.factories[name]
+	if !ok {
+		return factory, fmt.Errorf("%w: %q", ErrAlgorithmNotFound, name)
+	}
+	return factory, nil
+}
+
+func (r *registry[F]) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	cipherRegistry   = newRegistry[CipherFactory]()
+	hasherRegistry   = newRegistry[HasherFactory]()
+	signerRegistry   = newRegistry[SignerFactory]()
+	verifierRegistry = newRegistry[VerifierFactory]()
+)
+
+// RegisterCipher 按名称登记 Cipher 构造函数，名称应与 Cipher.Name() 一致；factory 为 nil 时移除登记
+func RegisterCipher(name string, factory CipherFactory) {
+	cipherRegistry.register(name, factory, factory == nil)
+}
+
+// LookupCipher 按名称查找 Cipher 构造函数
+func LookupCipher(name string) (CipherFactory, error) {
+	return cipherRegistry.lookup(name)
+}
+
+// CipherNames 按字母序返回已登记的 Cipher 名称
+func CipherNames() []string {
+	return cipherRegistry.names()
+}
+
+// RegisterHasher 按名称登记 Hasher 构造函数；factory 为 nil 时移除登记
+func RegisterHasher(name string, factory HasherFactory) {
+	hasherRegistry.register(name, factory, factory == nil)
+}
+
+// LookupHasher 按名称查找 Hasher 构造函数
+func LookupHasher(name string) (HasherFactory, error) {
+	return hasherRegistry.lookup(name)
+}
+
+// HasherNames 按字母序返回已登记的 Hasher 名称
+func HasherNames() []string {
+	return hasherRegistry.names()
+}
+
+// RegisterSigner 按名称登记 Signer 构造函数；factory 为 nil 时移除登记
+func RegisterSigner(name string, factory SignerFactory) {
+	signerRegistry.register(name, factory, factory == nil)
+}
+
+// LookupSigner 按名称查找 Signer 构造函数
+func LookupSigner(name string) (SignerFactory, error) {
+	return signerRegistry.lookup(name)
+}
+
+// SignerNames 按字母序返回已登记的 Signer 名称
+func SignerNames() []string {
+	return signerRegistry.names()
+}
+
+// RegisterVerifier 按名称登记 Verifier 构造函数；factory 为 nil 时移除登记
+func RegisterVerifier(name string, factory VerifierFactory) {
+	verifierRegistry.register(name, factory, factory == nil)
+}
+
+// LookupVerifier 按名称查找 Verifier 构造函数
+func LookupVerifier(name string) (VerifierFactory, error) {
+	return verifierRegistry.lookup(name)
+}
+
+// VerifierNames 按字母序返回已登记的 Verifier 名称
+func VerifierNames() []string {
+	return verifierRegistry.names()
+}
+
+// hashHasher 将 hash.Hash 适配为 Hasher 接口
+type hashHasher struct {
+	name string
+	new  func() hash.Hash
+}
+
+func (h *hashHasher) Sum(data []byte) ([]byte, error) {
+	hasher := h.new()
+	hasher.Write(data)
+	return hasher.Sum(nil), nil
+}
+
+func (h *hashHasher) Name() string {
+	return h.name
+}
+
+// decryptOnly 由只用于解密存量数据的 Cipher 实现，MultiCipher 不会将其作为激活密钥
+type decryptOnly interface {
+	decryptOnly()
+}
+
+// legacyAESCipher 注册表中的 "AES"：AESCipher 未指定 IV 时以密钥作为固定 IV，
+// 相同明文产生相同密文，因此只保留解密能力，用于读取迁移前的数据
+type legacyAESCipher struct {
+	*AESCipher
+}
+
+func (legacyAESCipher) decryptOnly() {}
+
+func (legacyAESCipher) Encrypt([]byte) ([]byte, error) {
+	return nil, fmt.Errorf("%w: AES (CBC with fixed IV) is for legacy data, use AES-GCM", ErrDecryptOnly)
+}
+
+func sm4Factory(mode SM4Mode) CipherFactory {
+	return func(key []byte) (Cipher, error) {
+		return NewSM4Cipher(key, WithSM4Mode(mode))
+	}
+}
+
+func hasherFactory(name string, h func() hash.Hash) HasherFactory {
+	return func() Hasher {
+		return &hashHasher{name: name, new: h}
+	}
+}
+
+func signerFactory[T Signer](load func(data, passphrase []byte) (T, error)) SignerFactory {
+	return func(key []byte) (Signer, error) {
+		signer, err := load(key, nil)
+		if err != nil {
+			return nil, err
+		}
+		if unwrapPrivateKey(signer) == nil {
+			return nil, errors.New("private key is required for signing")
+		}
+		return signer, nil
+	}
+}
+
+func verifierFactory[T Verifier](load func(data, passphrase []byte) (T, error)) VerifierFactory {
+	return func(key []byte) (Verifier, error) {
+		return load(key, nil)
+	}
+}
+
+func init() {
+	RegisterCipher("AES", func(key []byte) (Cipher, error) {
+		// NewAESCipher 在密钥为空时使用默认密钥，这里要求显式提供密钥
+		if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+			return nil, fmt.Errorf("invalid key length: %d, must be 16, 24, or 32 bytes", len(key))
+		}
+		return legacyAESCipher{NewAESCipher(key, nil)}, nil
+	})
+	RegisterCipher("AES-GCM", func(key []byte) (Cipher, error) { return NewAESGCMCipher(key) })
+	RegisterCipher("SM4", sm4Factory(SM4ModeECB))
+	RegisterCipher("SM4-CBC", sm4Factory(SM4ModeCBC))
+	RegisterCipher("SM4-CTR", sm4Factory(SM4ModeCTR))
+	RegisterCipher("SM4-GCM", sm4Factory(SM4ModeGCM))
+	RegisterCipher("XChaCha20-Poly1305", func(key []byte) (Cipher, error) { return NewXChaCha20Poly1305Cipher(key) })
+
+	RegisterHasher("SHA256", hasherFactory("SHA256", sha256.New))
+	RegisterHasher("SHA512", hasherFactory("SHA512", sha512.New))
+	RegisterHasher("SM3", hasherFactory("SM3", sm3.New))
+
+	RegisterSigner("RSA", signerFactory(LoadRSACipher))
+	RegisterSigner("ECDSA", signerFactory(LoadECDSACipher))
+	RegisterSigner("SM2", signerFactory(LoadSM2Cipher))
+	RegisterSigner("Ed25519", signerFactory(LoadEd25519Cipher))
+
+	RegisterVerifier("RSA", verifierFactory(LoadRSACipher))
+	RegisterVerifier("ECDSA", verifierFactory(LoadECDSACipher))
+	RegisterVerifier("SM2", verifierFactory(LoadSM2Cipher))
+	RegisterVerifier("Ed25519", verifierFactory(LoadEd25519Cipher))
+}
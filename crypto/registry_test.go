@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Ciphers(t *testing.T) {
+	for _, name := range CipherNames() {
+		t.Run(name, func(t *testing.T) {
+			factory, err := LookupCipher(name)
+			require.NoError(t, err)
+
+			key := make([]byte, 32)
+			if name == "SM4" || name == "SM4-CBC" || name == "SM4-CTR" || name == "SM4-GCM" {
+				key = key[:16]
+			}
+			c, err := factory(key)
+			require.NoError(t, err)
+			assert.Equal(t, name, c.Name())
+
+			crypted, err := c.Encrypt([]byte("registry"))
+			if name == "AES" {
+				// 固定 IV 的 AES-CBC 只用于解密旧数据
+				assert.ErrorIs(t, err, ErrDecryptOnly)
+				crypted, err = NewAESCipher(key, nil).Encrypt([]byte("registry"))
+			}
+			require.NoError(t, err)
+			plain, err := c.Decrypt(crypted)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("registry"), plain)
+		})
+	}
+
+	_, err := LookupCipher("ROT13")
+	assert.ErrorIs(t, err, ErrAlgorithmNotFound)
+
+	// AES 要求显式提供密钥，不回退到默认密钥
+	factory, _ := LookupCipher("AES")
+	_, err = factory(nil)
+	assert.Error(t, err)
+}
+
+func TestRegistry_Hashers(t *testing.T) {
+	assert.Equal(t, []string{"SHA256", "SHA512", "SM3"}, HasherNames())
+
+	factory, err := LookupHasher("SHA256")
+	require.NoError(t, err)
+	sum, err := factory().Sum([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", hex.EncodeToString(sum))
+
+	factory, err = LookupHasher("SM3")
+	require.NoError(t, err)
+	sum, err = factory().Sum([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, NewSM3Hasher().Sum([]byte("abc")), sum)
+}
+
+func TestRegistry_SignersAndVerifiers(t *testing.T) {
+	rsaCipher, _ := NewRSACipher(2048)
+	ecdsaCipher, _ := NewECDSACipher()
+	sm2Cipher, _ := NewSM2Cipher()
+	edCipher, _ := NewEd25519Cipher()
+
+	for _, key := range []interface{ Name() string }{rsaCipher, ecdsaCipher, sm2Cipher, edCipher} {
+		t.Run(key.Name(), func(t *testing.T) {
+			priv, err := ExportPrivateKey(key)
+			require.NoError(t, err)
+			pub, err := ExportPublicKey(key)
+			require.NoError(t, err)
+
+			newSigner, err := LookupSigner(key.Name())
+			require.NoError(t, err)
+			signer, err := newSigner(priv)
+			require.NoError(t, err)
+			sig, err := signer.Sign([]byte("registry"))
+			require.NoError(t, err)
+
+			newVerifier, err := LookupVerifier(key.Name())
+			require.NoError(t, err)
+			verifier, err := newVerifier(pub)
+			require.NoError(t, err)
+			ok, err := verifier.Verify([]byte("registry"), sig)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			// 只有公钥时不能创建 Signer
+			_, err = newSigner(pub)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	RegisterCipher("AES-GCM-TEST", func(key []byte) (Cipher, error) { return NewAESGCMCipher(key) })
+	assert.Contains(t, CipherNames(), "AES-GCM-TEST")
+
+	RegisterCipher("AES-GCM-TEST", nil)
+	_, err := LookupCipher("AES-GCM-TEST")
+	assert.ErrorIs(t, err, ErrAlgorithmNotFound)
+}
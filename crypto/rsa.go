@@ -1,10 +1,12 @@
 package crypto
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"errors"
 )
@@ -47,6 +49,32 @@ func (r *RSACipher) Decrypt(ciphertext []byte) ([]byte, error) {
 	return rsa.DecryptOAEP(sha256.New(), rand.Reader, r.privateKey, ciphertext, nil)
 }
 
+// Sign 使用RSA私钥签名（RSASSA-PKCS1-v1_5 + SHA-256），返回base64编码的签名
+func (r *RSACipher) Sign(data []byte) (string, error) {
+	if r.privateKey == nil {
+		return "", errors.New("private key is nil")
+	}
+	hash := sha256.Sum256(data)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, r.privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// Verify 使用RSA公钥验签
+func (r *RSACipher) Verify(data []byte, signature string) (bool, error) {
+	if r.publicKey == nil {
+		return false, errors.New("public key is nil")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, err
+	}
+	hash := sha256.Sum256(data)
+	return rsa.VerifyPKCS1v15(r.publicKey, crypto.SHA256, hash[:], sig) == nil, nil
+}
+
 // ExportPrivateKey 导出私钥PEM
 func (r *RSACipher) ExportPrivateKey() (string, error) {
 	if r.privateKey == nil {
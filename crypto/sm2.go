@@ -13,23 +13,61 @@ import (
 type SM2Cipher struct {
 	privateKey *sm2.PrivateKey
 	publicKey  *sm2.PublicKey
+	userID     []byte
+	mode       SM2CipherMode
+}
+
+// SM2CipherMode SM2 密文分量的排列顺序
+type SM2CipherMode int
+
+const (
+	SM2C1C3C2 SM2CipherMode = iota // GM/T 0009 标准顺序（默认）
+	SM2C1C2C3                      // 旧版标准顺序，兼容早期系统
+)
+
+func (m SM2CipherMode) gmsmMode() int {
+	if m == SM2C1C2C3 {
+		return sm2.C1C2C3
+	}
+	return sm2.C1C3C2
+}
+
+// DefaultSM2UserID GM/T 0009 规定的默认用户标识
+var DefaultSM2UserID = []byte("1234567812345678")
+
+// SM2Option SM2Cipher 选项
+type SM2Option func(*SM2Cipher)
+
+// WithSM2UserID 设置签名验签使用的用户标识（参与 ZA 计算），双方必须一致
+func WithSM2UserID(uid []byte) SM2Option {
+	return func(s *SM2Cipher) {
+		s.userID = uid
+	}
+}
+
+// WithSM2CipherMode 设置 Encrypt/Decrypt 的密文排列顺序，默认 C1C3C2
+func WithSM2CipherMode(mode SM2CipherMode) SM2Option {
+	return func(s *SM2Cipher) {
+		s.mode = mode
+	}
 }
 
 // NewSM2Cipher 生成新的SM2密钥对
-func NewSM2Cipher() (*SM2Cipher, error) {
+func NewSM2Cipher(opts ...SM2Option) (*SM2Cipher, error) {
 	priv, err := sm2.GenerateKey(rand.Reader)
 	if err != nil {
 		return nil, err
 	}
-	return &SM2Cipher{
-		privateKey: priv,
-		publicKey:  &priv.PublicKey,
-	}, nil
+	return NewSM2CipherFromKey(priv, &priv.PublicKey, opts...), nil
 }
 
 // NewSM2CipherFromKey 用已有密钥初始化
-func NewSM2CipherFromKey(priv *sm2.PrivateKey, pub *sm2.PublicKey) *SM2Cipher {
-	return &SM2Cipher{privateKey: priv, publicKey: pub}
+func NewSM2CipherFromKey(priv *sm2.PrivateKey, pub *sm2.PublicKey, opts ...SM2Option) *SM2Cipher {
+	s := &SM2Cipher{privateKey: priv, publicKey: pub, mode: SM2C1C3C2}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Encrypt 使用SM2公钥加密
@@ -38,7 +76,7 @@ func (s *SM2Cipher) Encrypt(plain []byte) ([]byte, error) {
 		return nil, errors.New("public key is nil")
 	}
 
-	return sm2.Encrypt(s.publicKey, plain, rand.Reader, sm2.C1C3C2)
+	return sm2.Encrypt(s.publicKey, plain, rand.Reader, s.mode.gmsmMode())
 }
 
 // Decrypt 使用SM2私钥解密
@@ -47,7 +85,7 @@ func (s *SM2Cipher) Decrypt(ciphertext []byte) ([]byte, error) {
 		return nil, errors.New("private key is nil")
 	}
 
-	return sm2.Decrypt(s.privateKey, ciphertext, sm2.C1C3C2)
+	return sm2.Decrypt(s.privateKey, ciphertext, s.mode.gmsmMode())
 }
 
 // EncryptAsn1 加密并输出ASN.1格式（更通用，兼容多数平台）
@@ -87,7 +125,7 @@ func (s *SM2Cipher) Sign(digest []byte) (string, error) {
 		return "", errors.New("private key is nil")
 	}
 	// uid为nil时，gmsm默认使用"1234567812345678"
-	r, sInt, err := sm2.Sm2Sign(s.privateKey, digest, nil, rand.Reader)
+	r, sInt, err := sm2.Sm2Sign(s.privateKey, digest, s.userID, rand.Reader)
 	if err != nil {
 		return "", err
 	}
@@ -107,6 +145,6 @@ func (s *SM2Cipher) Verify(data []byte, signature string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	ok := sm2.Sm2Verify(s.publicKey, data, nil, r, sInt)
+	ok := sm2.Sm2Verify(s.publicKey, data, s.userID, r, sInt)
 	return ok, nil
 }
@@ -0,0 +1,138 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+var (
+	ErrSM2ExchangeNotDone      = errors.New("crypto: sm2 key exchange not completed")
+	ErrSM2ExchangeConfirmation = errors.New("crypto: sm2 key exchange confirmation failed")
+)
+
+// SM2KeyExchange 实现 GM/T 0003.3 SM2 密钥交换协议，满足 KeyExchanger 接口
+//
+// 双方各持有长期密钥对，并为每次协商生成临时密钥对：
+//  1. 双方通过 PublicKeyBytes 交换临时公钥
+//  2. 双方调用 DeriveSharedSecret 得到相同的会话密钥
+//  3. 可选：响应方发送 ConfirmationTag，发起方 VerifyConfirmation 后回送自己的 ConfirmationTag
+type SM2KeyExchange struct {
+	local     *sm2.PrivateKey
+	peer      *sm2.PublicKey
+	ephemeral *sm2.PrivateKey
+	initiator bool
+	localID   []byte
+	peerID    []byte
+	keyLen    int
+
+	s1, s2 []byte
+}
+
+// SM2KeyExchangeOption SM2KeyExchange 选项
+type SM2KeyExchangeOption func(*SM2KeyExchange)
+
+// WithSM2ExchangeIDs 设置本方与对方的用户标识，默认均为 DefaultSM2UserID
+func WithSM2ExchangeIDs(localID, peerID []byte) SM2KeyExchangeOption {
+	return func(x *SM2KeyExchange) {
+		x.localID = localID
+		x.peerID = peerID
+	}
+}
+
+// WithSM2ExchangeKeyLength 设置协商得到的密钥长度（字节），默认 16
+func WithSM2ExchangeKeyLength(n int) SM2KeyExchangeOption {
+	return func(x *SM2KeyExchange) {
+		x.keyLen = n
+	}
+}
+
+// NewSM2KeyExchange 创建一次密钥交换会话，local 必须包含私钥，peer 为对方长期公钥；
+// initiator 为 true 表示本方是发起方（协议中的 A）
+func NewSM2KeyExchange(local *SM2Cipher, peer *sm2.PublicKey, initiator bool, opts ...SM2KeyExchangeOption) (*SM2KeyExchange, error) {
+	if local == nil || local.privateKey == nil {
+		return nil, errors.New("private key is nil")
+	}
+	if peer == nil {
+		return nil, errors.New("peer public key is nil")
+	}
+	ephemeral, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &SM2KeyExchange{
+		local:     local.privateKey,
+		peer:      peer,
+		ephemeral: ephemeral,
+		initiator: initiator,
+		localID:   DefaultSM2UserID,
+		peerID:    DefaultSM2UserID,
+		keyLen:    16,
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+	if x.keyLen <= 0 {
+		return nil, errors.New("key length must be positive")
+	}
+	return x, nil
+}
+
+// PublicKeyBytes 返回本次会话的临时公钥（非压缩格式），发送给对方
+func (x *SM2KeyExchange) PublicKeyBytes() []byte {
+	return marshalSM2Point(&x.ephemeral.PublicKey)
+}
+
+// DeriveSharedSecret 根据对方的临时公钥计算会话密钥
+func (x *SM2KeyExchange) DeriveSharedSecret(peerPubBytes []byte) ([]byte, error) {
+	peerEphemeral, err := unmarshalSM2PublicKey(peerPubBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var k []byte
+	if x.initiator {
+		k, x.s1, x.s2, err = sm2.KeyExchangeA(x.keyLen, x.localID, x.peerID, x.local, x.peer, x.ephemeral, peerEphemeral)
+	} else {
+		k, x.s1, x.s2, err = sm2.KeyExchangeB(x.keyLen, x.peerID, x.localID, x.local, x.peer, x.ephemeral, peerEphemeral)
+	}
+	if err != nil {
+		x.s1, x.s2 = nil, nil
+		return nil, err
+	}
+	return k, nil
+}
+
+// ConfirmationTag 返回发送给对方的确认值：响应方为 S_B，发起方为 S_A
+func (x *SM2KeyExchange) ConfirmationTag() ([]byte, error) {
+	if x.s1 == nil {
+		return nil, ErrSM2ExchangeNotDone
+	}
+	if x.initiator {
+		return x.s2, nil
+	}
+	return x.s1, nil
+}
+
+// VerifyConfirmation 校验对方发来的确认值，确认双方得到了相同的会话密钥
+func (x *SM2KeyExchange) VerifyConfirmation(tag []byte) error {
+	if x.s1 == nil {
+		return ErrSM2ExchangeNotDone
+	}
+	expected := x.s2
+	if x.initiator {
+		expected = x.s1
+	}
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return ErrSM2ExchangeConfirmation
+	}
+	return nil
+}
+
+// Name 返回算法名称
+func (x *SM2KeyExchange) Name() string {
+	return "SM2-KEP"
+}
@@ -0,0 +1,76 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSM2KeyExchange(t *testing.T) {
+	alice, err := NewSM2Cipher()
+	require.NoError(t, err)
+	bob, err := NewSM2Cipher()
+	require.NoError(t, err)
+
+	aliceID, bobID := []byte("alice@example.com"), []byte("bob@example.com")
+	a, err := NewSM2KeyExchange(alice, bob.PublicKey(), true, WithSM2ExchangeIDs(aliceID, bobID), WithSM2ExchangeKeyLength(32))
+	require.NoError(t, err)
+	b, err := NewSM2KeyExchange(bob, alice.PublicKey(), false, WithSM2ExchangeIDs(bobID, aliceID), WithSM2ExchangeKeyLength(32))
+	require.NoError(t, err)
+
+	var _ KeyExchanger = a
+
+	_, err = a.ConfirmationTag()
+	assert.ErrorIs(t, err, ErrSM2ExchangeNotDone)
+
+	kb, err := b.DeriveSharedSecret(a.PublicKeyBytes())
+	require.NoError(t, err)
+	ka, err := a.DeriveSharedSecret(b.PublicKeyBytes())
+	require.NoError(t, err)
+	assert.Len(t, ka, 32)
+	assert.Equal(t, ka, kb)
+
+	// 响应方先发送 S_B，发起方校验后回送 S_A
+	sb, err := b.ConfirmationTag()
+	require.NoError(t, err)
+	require.NoError(t, a.VerifyConfirmation(sb))
+	sa, err := a.ConfirmationTag()
+	require.NoError(t, err)
+	require.NoError(t, b.VerifyConfirmation(sa))
+	assert.NotEqual(t, sa, sb)
+}
+
+func TestSM2KeyExchange_Mismatch(t *testing.T) {
+	alice, _ := NewSM2Cipher()
+	bob, _ := NewSM2Cipher()
+	mallory, _ := NewSM2Cipher()
+
+	// 用户标识不一致
+	a, err := NewSM2KeyExchange(alice, bob.PublicKey(), true, WithSM2ExchangeIDs([]byte("alice"), []byte("bob")))
+	require.NoError(t, err)
+	b, err := NewSM2KeyExchange(bob, alice.PublicKey(), false)
+	require.NoError(t, err)
+	ka, err := a.DeriveSharedSecret(b.PublicKeyBytes())
+	require.NoError(t, err)
+	kb, err := b.DeriveSharedSecret(a.PublicKeyBytes())
+	require.NoError(t, err)
+	assert.NotEqual(t, ka, kb)
+	sb, _ := b.ConfirmationTag()
+	assert.ErrorIs(t, a.VerifyConfirmation(sb), ErrSM2ExchangeConfirmation)
+
+	// 对方长期公钥不符（中间人）
+	a, _ = NewSM2KeyExchange(alice, bob.PublicKey(), true)
+	m, _ := NewSM2KeyExchange(mallory, alice.PublicKey(), false)
+	ka, err = a.DeriveSharedSecret(m.PublicKeyBytes())
+	require.NoError(t, err)
+	km, err := m.DeriveSharedSecret(a.PublicKeyBytes())
+	require.NoError(t, err)
+	assert.NotEqual(t, ka, km)
+
+	_, err = a.DeriveSharedSecret([]byte("invalid"))
+	assert.Error(t, err)
+
+	_, err = NewSM2KeyExchange(NewSM2CipherFromKey(nil, alice.PublicKey()), bob.PublicKey(), true)
+	assert.Error(t, err)
+}
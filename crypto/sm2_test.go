@@ -88,3 +88,62 @@ func TestSM2Cipher_SignVerify(t *testing.T) {
 		t.Fatalf("Verify failed, err: %v, ok: %v", err, ok)
 	}
 }
+
+func TestSM2Cipher_UserID(t *testing.T) {
+	signer, err := NewSM2Cipher(WithSM2UserID([]byte("alice@example.com")))
+	if err != nil {
+		t.Fatalf("Failed to generate SM2 key pair: %v", err)
+	}
+	data := []byte("hello, sm2 user id test!")
+	sig, err := signer.Sign(data)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	same := NewSM2CipherFromKey(nil, signer.PublicKey(), WithSM2UserID([]byte("alice@example.com")))
+	if ok, err := same.Verify(data, sig); err != nil || !ok {
+		t.Fatalf("Verify with same user id failed, err: %v, ok: %v", err, ok)
+	}
+
+	// 用户标识不同（包括默认标识）时验签失败
+	for _, uid := range [][]byte{nil, DefaultSM2UserID, []byte("bob@example.com")} {
+		other := NewSM2CipherFromKey(nil, signer.PublicKey(), WithSM2UserID(uid))
+		if ok, _ := other.Verify(data, sig); ok {
+			t.Fatalf("Verify should fail with user id %q", uid)
+		}
+	}
+
+	// nil 与默认标识等价
+	def, _ := NewSM2Cipher()
+	sig, _ = def.Sign(data)
+	explicit := NewSM2CipherFromKey(nil, def.PublicKey(), WithSM2UserID(DefaultSM2UserID))
+	if ok, err := explicit.Verify(data, sig); err != nil || !ok {
+		t.Fatalf("Verify with default user id failed, err: %v, ok: %v", err, ok)
+	}
+}
+
+func TestSM2Cipher_CipherMode(t *testing.T) {
+	c1c3c2, err := NewSM2Cipher()
+	if err != nil {
+		t.Fatalf("Failed to generate SM2 key pair: %v", err)
+	}
+	c1c2c3 := NewSM2CipherFromKey(c1c3c2.PrivateKey(), c1c3c2.PublicKey(), WithSM2CipherMode(SM2C1C2C3))
+
+	plain := []byte("hello, sm2 cipher mode test!")
+	crypted, err := c1c2c3.Encrypt(plain)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	decrypted, err := c1c2c3.Decrypt(crypted)
+	if err != nil || !bytes.Equal(plain, decrypted) {
+		t.Fatalf("C1C2C3 round trip failed, err: %v", err)
+	}
+
+	// 调整分量顺序后可以按 C1C3C2 解密：0x04 | C1(64) | C2 | C3(32)
+	c1, c2, c3 := crypted[:65], crypted[65:len(crypted)-32], crypted[len(crypted)-32:]
+	reordered := append(append(append([]byte{}, c1...), c3...), c2...)
+	decrypted, err = c1c3c2.Decrypt(reordered)
+	if err != nil || !bytes.Equal(plain, decrypted) {
+		t.Fatalf("C1C3C2 decrypt of reordered ciphertext failed, err: %v", err)
+	}
+}
@@ -1,38 +1,167 @@
 package crypto
 
 import (
+	"crypto/cipher"
 	"crypto/rand"
 	"errors"
+	"fmt"
 
 	"github.com/tjfoc/gmsm/sm4"
 )
 
-// SM4Cipher 实现国密SM4对称加密算法（ECB模式，适合小数据块）
+// SM4Mode SM4 工作模式
+type SM4Mode int
+
+const (
+	SM4ModeECB SM4Mode = iota // ECB（默认，兼容旧数据，不推荐用于新数据）
+	SM4ModeCBC                // CBC，随机 IV，PKCS#7 填充
+	SM4ModeCTR                // CTR，随机 IV，无填充
+	SM4ModeGCM                // GCM，随机 nonce，带认证
+)
+
+// String 返回模式名称
+func (m SM4Mode) String() string {
+	switch m {
+	case SM4ModeECB:
+		return "ECB"
+	case SM4ModeCBC:
+		return "CBC"
+	case SM4ModeCTR:
+		return "CTR"
+	case SM4ModeGCM:
+		return "GCM"
+	default:
+		return fmt.Sprintf("SM4Mode(%d)", int(m))
+	}
+}
+
+// SM4Cipher 实现国密SM4对称加密算法，默认ECB模式；CBC/CTR/GCM 模式下每次加密生成随机 IV，
+// 输出格式为 IV|密文（GCM 为 nonce|密文|tag）
 type SM4Cipher struct {
-	key []byte
+	key  []byte
+	mode SM4Mode
+}
+
+// SM4Option SM4Cipher 选项
+type SM4Option func(*SM4Cipher)
+
+// WithSM4Mode 设置工作模式
+func WithSM4Mode(mode SM4Mode) SM4Option {
+	return func(s *SM4Cipher) {
+		s.mode = mode
+	}
 }
 
 // NewSM4Cipher 创建SM4加密器
-func NewSM4Cipher(key []byte) (*SM4Cipher, error) {
+func NewSM4Cipher(key []byte, opts ...SM4Option) (*SM4Cipher, error) {
 	if len(key) != 16 {
 		return nil, errors.New("SM4 key length must be 16 bytes")
 	}
-	return &SM4Cipher{key: key}, nil
+	s := &SM4Cipher{key: key}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.mode < SM4ModeECB || s.mode > SM4ModeGCM {
+		return nil, fmt.Errorf("unsupported SM4 mode: %s", s.mode)
+	}
+	return s, nil
 }
 
-// Encrypt ECB模式加密
+// Encrypt 按工作模式加密
 func (s *SM4Cipher) Encrypt(plain []byte) ([]byte, error) {
-	padded := PKCS5Padding(plain, 16)
-	return sm4.Sm4Ecb(s.key, padded, true)
+	if s.mode == SM4ModeECB {
+		padded := PKCS5Padding(plain, 16)
+		return sm4.Sm4Ecb(s.key, padded, true)
+	}
+
+	block, err := sm4.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	if s.mode == SM4ModeGCM {
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		return gcm.Seal(nonce, nonce, plain, nil), nil
+	}
+
+	iv := make([]byte, sm4.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	if s.mode == SM4ModeCBC {
+		padded := PKCS5Padding(append([]byte(nil), plain...), sm4.BlockSize)
+		out := append(iv, padded...)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(out[sm4.BlockSize:], padded)
+		return out, nil
+	}
+	out := make([]byte, sm4.BlockSize+len(plain))
+	copy(out, iv)
+	cipher.NewCTR(block, iv).XORKeyStream(out[sm4.BlockSize:], plain)
+	return out, nil
 }
 
-// Decrypt ECB模式解密
+// Decrypt 按工作模式解密
 func (s *SM4Cipher) Decrypt(ciphertext []byte) ([]byte, error) {
-	decrypted, err := sm4.Sm4Ecb(s.key, ciphertext, false)
+	if s.mode == SM4ModeECB {
+		decrypted, err := sm4.Sm4Ecb(s.key, ciphertext, false)
+		if err != nil {
+			return nil, err
+		}
+		return PKCS5UnPadding(decrypted), nil
+	}
+
+	block, err := sm4.NewCipher(s.key)
 	if err != nil {
 		return nil, err
 	}
-	return PKCS5UnPadding(decrypted), nil
+	if s.mode == SM4ModeGCM {
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		if len(ciphertext) < gcm.NonceSize()+gcm.Overhead() {
+			return nil, fmt.Errorf("ciphertext too short")
+		}
+		return gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], nil)
+	}
+
+	if len(ciphertext) < sm4.BlockSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	iv, body := ciphertext[:sm4.BlockSize], ciphertext[sm4.BlockSize:]
+	plain := make([]byte, len(body))
+	if s.mode == SM4ModeCBC {
+		if len(body) == 0 || len(body)%sm4.BlockSize != 0 {
+			return nil, fmt.Errorf("invalid crypted text length: %d, must be multiple of block size %d", len(body), sm4.BlockSize)
+		}
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, body)
+		return PKCS5UnPadding(plain), nil
+	}
+	cipher.NewCTR(block, iv).XORKeyStream(plain, body)
+	return plain, nil
+}
+
+// NonceSize 返回密文前部 IV/nonce 的长度，ECB 模式为 0
+func (s *SM4Cipher) NonceSize() int {
+	switch s.mode {
+	case SM4ModeCBC, SM4ModeCTR:
+		return sm4.BlockSize
+	case SM4ModeGCM:
+		return 12
+	default:
+		return 0
+	}
+}
+
+// Mode 返回工作模式
+func (s *SM4Cipher) Mode() SM4Mode {
+	return s.mode
 }
 
 // GenerateSM4Key 生成随机SM4密钥
@@ -42,6 +171,10 @@ func GenerateSM4Key() ([]byte, error) {
 	return key, err
 }
 
+// Name 返回算法名称，ECB 模式为 "SM4"，其它模式带模式后缀，如 "SM4-GCM"
 func (s *SM4Cipher) Name() string {
-	return "SM4"
+	if s.mode == SM4ModeECB {
+		return "SM4"
+	}
+	return "SM4-" + s.mode.String()
 }
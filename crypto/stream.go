@@ -0,0 +1,406 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+// 分块流式 AEAD 加密
+//
+// 密文格式：
+//
+//	header = magic(4) | version(1) | algorithm(1) | chunkSize(4, 大端) | nonce(12)
+//	chunk  = AEAD(明文分块)，除最后一块外明文长度均为 chunkSize
+//
+// 第 i 块的 nonce 为 header.nonce 的后 8 字节与计数器 i 异或；附加数据为 header 与结尾标记（1 字节），
+// 因此分块的重排、删除、截断以及篡改 header 都会导致认证失败。
+
+var streamMagic = [4]byte{'G', 'U', 'S', 'E'}
+
+const (
+	streamVersion    = 1
+	streamNonceSize  = 12
+	streamHeaderSize = 4 + 1 + 1 + 4 + streamNonceSize
+
+	// DefaultStreamChunkSize 默认明文分块大小
+	DefaultStreamChunkSize = 64 * 1024
+	// MaxStreamChunkSize 允许的最大明文分块大小
+	MaxStreamChunkSize = 16 * 1024 * 1024
+)
+
+var (
+	ErrStreamHeader    = errors.New("crypto: invalid stream header")
+	ErrStreamAlgorithm = errors.New("crypto: stream algorithm mismatch")
+	ErrStreamAuth      = errors.New("crypto: stream chunk authentication failed")
+	ErrStreamTruncated = errors.New("crypto: stream truncated")
+	ErrStreamClosed    = errors.New("crypto: stream writer closed")
+)
+
+// StreamAlgorithm 流式加密算法标识，写入 header
+type StreamAlgorithm byte
+
+const (
+	StreamAESGCM StreamAlgorithm = 1 // AES-GCM
+	StreamSM4GCM StreamAlgorithm = 2 // SM4-GCM
+)
+
+func (a StreamAlgorithm) String() string {
+	switch a {
+	case StreamAESGCM:
+		return "AES-GCM-STREAM"
+	case StreamSM4GCM:
+		return "SM4-GCM-STREAM"
+	default:
+		return fmt.Sprintf("StreamAlgorithm(%d)", byte(a))
+	}
+}
+
+// StreamOption 流式加密选项
+type StreamOption func(*StreamCipher)
+
+// WithChunkSize 设置加密时的明文分块大小，解密时以 header 中记录的为准
+func WithChunkSize(size int) StreamOption {
+	return func(s *StreamCipher) {
+		s.chunkSize = size
+	}
+}
+
+// StreamCipher 基于分块 AEAD 的流式加解密器，可加密任意大小的数据而无需整体载入内存
+type StreamCipher struct {
+	algorithm StreamAlgorithm
+	aead      cipher.AEAD
+	chunkSize int
+}
+
+// NewAESGCMStreamCipher 创建 AES-GCM 流式加解密器，key 长度为 16、24 或 32 字节
+func NewAESGCMStreamCipher(key []byte, opts ...StreamOption) (*StreamCipher, error) {
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("invalid key length: %d, must be 16, 24, or 32 bytes", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamCipher(StreamAESGCM, block, opts)
+}
+
+// NewSM4GCMStreamCipher 创建 SM4-GCM 流式加解密器，key 长度为 16 字节
+func NewSM4GCMStreamCipher(key []byte, opts ...StreamOption) (*StreamCipher, error) {
+	if len(key) != 16 {
+		return nil, errors.New("SM4 key length must be 16 bytes")
+	}
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamCipher(StreamSM4GCM, block, opts)
+}
+
+func newStreamCipher(algorithm StreamAlgorithm, block cipher.Block, opts []StreamOption) (*StreamCipher, error) {
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &StreamCipher{algorithm: algorithm, aead: aead, chunkSize: DefaultStreamChunkSize}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.chunkSize <= 0 || s.chunkSize > MaxStreamChunkSize {
+		return nil, fmt.Errorf("invalid chunk size: %d, must be in 1..%d", s.chunkSize, MaxStreamChunkSize)
+	}
+	return s, nil
+}
+
+// Name 返回算法名称
+func (s *StreamCipher) Name() string {
+	return s.algorithm.String()
+}
+
+// NewEncryptWriter 返回加密写入器，写入的明文加密后写到 w；必须调用 Close 写出最后一块
+func (s *StreamCipher) NewEncryptWriter(w io.Writer) (io.WriteCloser, error) {
+	header := make([]byte, streamHeaderSize)
+	copy(header, streamMagic[:])
+	header[4] = streamVersion
+	header[5] = byte(s.algorithm)
+	binary.BigEndian.PutUint32(header[6:10], uint32(s.chunkSize))
+	if _, err := rand.Read(header[10:]); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:     w,
+		chunk: newChunkSealer(s.aead, header),
+		buf:   make([]byte, 0, s.chunkSize),
+		size:  s.chunkSize,
+	}, nil
+}
+
+// NewDecryptReader 返回解密读取器；仅在读到 io.EOF 时才表明整个流认证通过，
+// 此前已读出的明文在流被截断或篡改时仍可能不完整
+func (s *StreamCipher) NewDecryptReader(r io.Reader) (io.Reader, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStreamHeader, err)
+	}
+	if !bytes.Equal(header[:4], streamMagic[:]) || header[4] != streamVersion {
+		return nil, ErrStreamHeader
+	}
+	if StreamAlgorithm(header[5]) != s.algorithm {
+		return nil, fmt.Errorf("%w: got %s, want %s", ErrStreamAlgorithm, StreamAlgorithm(header[5]), s.algorithm)
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[6:10]))
+	if chunkSize <= 0 || chunkSize > MaxStreamChunkSize {
+		return nil, fmt.Errorf("%w: chunk size %d", ErrStreamHeader, chunkSize)
+	}
+	return &decryptReader{
+		r:     bufio.NewReaderSize(r, chunkSize+s.aead.Overhead()+1),
+		chunk: newChunkSealer(s.aead, header),
+		buf:   make([]byte, chunkSize+s.aead.Overhead()),
+	}, nil
+}
+
+// Encrypt 从 src 读取明文，加密后写入 dst
+func (s *StreamCipher) Encrypt(dst io.Writer, src io.Reader) error {
+	w, err := s.NewEncryptWriter(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, src); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// Decrypt 从 src 读取密文，解密后写入 dst
+func (s *StreamCipher) Decrypt(dst io.Writer, src io.Reader) error {
+	r, err := s.NewDecryptReader(src)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+// EncryptFile 加密文件，先写入同目录的临时文件，成功后再重命名为 dst
+func (s *StreamCipher) EncryptFile(src, dst string) error {
+	return transformFile(src, dst, s.Encrypt)
+}
+
+// DecryptFile 解密文件；整个流认证通过后才会生成 dst，失败时不会留下部分明文
+func (s *StreamCipher) DecryptFile(src, dst string) error {
+	return transformFile(src, dst, s.Decrypt)
+}
+
+func transformFile(src, dst string, fn func(io.Writer, io.Reader) error) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = fn(tmp, bufio.NewReader(in)); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// chunkSealer 负责派生分块 nonce 与附加数据
+type chunkSealer struct {
+	aead    cipher.AEAD
+	nonce   []byte
+	aad     []byte
+	counter uint64
+}
+
+func newChunkSealer(aead cipher.AEAD, header []byte) *chunkSealer {
+	aad := make([]byte, len(header)+1)
+	copy(aad, header)
+	return &chunkSealer{
+		aead:  aead,
+		nonce: make([]byte, streamNonceSize),
+		aad:   aad,
+	}
+}
+
+// prepare 计算第 counter 块的 nonce 与附加数据
+func (c *chunkSealer) prepare(counter uint64, final bool) ([]byte, []byte) {
+	base := c.aad[10 : 10+streamNonceSize]
+	copy(c.nonce, base)
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], counter)
+	for i := range ctr {
+		c.nonce[4+i] ^= ctr[i]
+	}
+	if final {
+		c.aad[len(c.aad)-1] = 1
+	} else {
+		c.aad[len(c.aad)-1] = 0
+	}
+	return c.nonce, c.aad
+}
+
+func (c *chunkSealer) seal(dst, plain []byte, final bool) []byte {
+	nonce, aad := c.prepare(c.counter, final)
+	c.counter++
+	return c.aead.Seal(dst, nonce, plain, aad)
+}
+
+func (c *chunkSealer) open(dst, ciphertext []byte, final bool) ([]byte, error) {
+	nonce, aad := c.prepare(c.counter, final)
+	plain, err := c.aead.Open(dst, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, err
+	}
+	c.counter++
+	return plain, nil
+}
+
+type encryptWriter struct {
+	w      io.Writer
+	chunk  *chunkSealer
+	buf    []byte
+	out    []byte
+	size   int
+	err    error
+	closed bool
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, ErrStreamClosed
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+	n := 0
+	for len(p) > 0 {
+		// 缓冲区满且仍有数据时才写出，保证最后一块总是在 Close 时带结尾标记写出
+		if len(e.buf) == e.size {
+			if e.err = e.flush(false); e.err != nil {
+				return n, e.err
+			}
+		}
+		m := copy(e.buf[len(e.buf):e.size], p)
+		e.buf = e.buf[:len(e.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+func (e *encryptWriter) flush(final bool) error {
+	e.out = e.chunk.seal(e.out[:0], e.buf, final)
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(e.out)
+	return err
+}
+
+// Close 写出带结尾标记的最后一块，不会关闭底层 io.Writer
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return e.err
+	}
+	e.closed = true
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.flush(true)
+	return e.err
+}
+
+type decryptReader struct {
+	r     *bufio.Reader
+	chunk *chunkSealer
+	buf   []byte
+	out   []byte
+	plain []byte
+	err   error
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.next()
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// next 读取并解密下一块；读满一块后仍无后续数据即为最后一块
+func (d *decryptReader) next() error {
+	n, err := io.ReadFull(d.r, d.buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+	final := n < len(d.buf)
+	if !final {
+		if _, err := d.r.Peek(1); errors.Is(err, io.EOF) {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// GCM 认证失败时会清空输出，使用独立的明文缓冲区以便再次尝试
+	ciphertext := d.buf[:n]
+	plain, err := d.chunk.open(d.out[:0], ciphertext, final)
+	if err != nil {
+		if n < d.chunk.aead.Overhead() {
+			return ErrStreamTruncated
+		}
+		if final && n == len(d.buf) {
+			// 能按非结尾块解开，说明流在分块边界处被截断
+			if _, err := d.chunk.open(d.out[:0], ciphertext, false); err == nil {
+				return ErrStreamTruncated
+			}
+		}
+		return ErrStreamAuth
+	}
+	d.out = plain
+	d.plain = plain
+	if final {
+		return io.EOF
+	}
+	return nil
+}
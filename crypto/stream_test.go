@@ -0,0 +1,174 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStreamCiphers(t *testing.T, opts ...StreamOption) []*StreamCipher {
+	aesKey, err := GenerateAESKey(32)
+	require.NoError(t, err)
+	aesStream, err := NewAESGCMStreamCipher(aesKey, opts...)
+	require.NoError(t, err)
+
+	sm4Key, err := GenerateSM4Key()
+	require.NoError(t, err)
+	sm4Stream, err := NewSM4GCMStreamCipher(sm4Key, opts...)
+	require.NoError(t, err)
+
+	return []*StreamCipher{aesStream, sm4Stream}
+}
+
+func encryptStream(t *testing.T, s *StreamCipher, plain []byte) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, s.Encrypt(&buf, bytes.NewReader(plain)))
+	return buf.Bytes()
+}
+
+func TestStreamCipher_RoundTrip(t *testing.T) {
+	for _, s := range newTestStreamCiphers(t, WithChunkSize(1024)) {
+		t.Run(s.Name(), func(t *testing.T) {
+			// 覆盖空数据、不足一块、恰好整块以及多块的情况
+			for _, size := range []int{0, 1, 1023, 1024, 2048, 5000} {
+				plain := make([]byte, size)
+				_, _ = rand.Read(plain)
+
+				ciphertext := encryptStream(t, s, plain)
+				var out bytes.Buffer
+				require.NoError(t, s.Decrypt(&out, bytes.NewReader(ciphertext)), "size %d", size)
+				assert.Equal(t, plain, out.Bytes(), "size %d", size)
+			}
+		})
+	}
+}
+
+func TestStreamCipher_SmallWrites(t *testing.T) {
+	s := newTestStreamCiphers(t, WithChunkSize(16))[0]
+	plain := []byte("the quick brown fox jumps over the lazy dog")
+
+	var buf bytes.Buffer
+	w, err := s.NewEncryptWriter(&buf)
+	require.NoError(t, err)
+	for _, b := range plain {
+		_, err := w.Write([]byte{b})
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	_, err = w.Write([]byte("x"))
+	assert.ErrorIs(t, err, ErrStreamClosed)
+
+	r, err := s.NewDecryptReader(&buf)
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, plain, out)
+}
+
+func TestStreamCipher_Tampering(t *testing.T) {
+	s := newTestStreamCiphers(t, WithChunkSize(64))[0]
+	plain := make([]byte, 64*3+10)
+	_, _ = rand.Read(plain)
+	ciphertext := encryptStream(t, s, plain)
+	chunk := 64 + 16
+
+	decrypt := func(data []byte) error {
+		return s.Decrypt(io.Discard, bytes.NewReader(data))
+	}
+
+	t.Run("截断到分块边界", func(t *testing.T) {
+		err := decrypt(ciphertext[:streamHeaderSize+2*chunk])
+		assert.ErrorIs(t, err, ErrStreamTruncated)
+	})
+
+	t.Run("截断到分块中间", func(t *testing.T) {
+		err := decrypt(ciphertext[:len(ciphertext)-3])
+		assert.ErrorIs(t, err, ErrStreamAuth)
+	})
+
+	t.Run("仅有 header", func(t *testing.T) {
+		err := decrypt(ciphertext[:streamHeaderSize])
+		assert.ErrorIs(t, err, ErrStreamTruncated)
+	})
+
+	t.Run("追加数据", func(t *testing.T) {
+		err := decrypt(append(bytes.Clone(ciphertext), 0))
+		assert.ErrorIs(t, err, ErrStreamAuth)
+	})
+
+	t.Run("交换分块", func(t *testing.T) {
+		data := bytes.Clone(ciphertext)
+		first := data[streamHeaderSize : streamHeaderSize+chunk]
+		second := bytes.Clone(data[streamHeaderSize+chunk : streamHeaderSize+2*chunk])
+		copy(data[streamHeaderSize+chunk:], first)
+		copy(data[streamHeaderSize:], second)
+		assert.ErrorIs(t, decrypt(data), ErrStreamAuth)
+	})
+
+	t.Run("篡改 header 中的分块大小", func(t *testing.T) {
+		data := bytes.Clone(ciphertext)
+		data[9] = 32
+		assert.Error(t, decrypt(data))
+	})
+
+	t.Run("算法不匹配", func(t *testing.T) {
+		sm4Key, _ := GenerateSM4Key()
+		other, err := NewSM4GCMStreamCipher(sm4Key)
+		require.NoError(t, err)
+		err = other.Decrypt(io.Discard, bytes.NewReader(ciphertext))
+		assert.ErrorIs(t, err, ErrStreamAlgorithm)
+	})
+
+	t.Run("非流式密文", func(t *testing.T) {
+		assert.ErrorIs(t, decrypt([]byte("not a stream")), ErrStreamHeader)
+	})
+}
+
+func TestStreamCipher_Files(t *testing.T) {
+	s := newTestStreamCiphers(t, WithChunkSize(4096))[1]
+	dir := t.TempDir()
+
+	plain := make([]byte, 100*1024+7)
+	_, _ = rand.Read(plain)
+	src := filepath.Join(dir, "backup.tar")
+	require.NoError(t, os.WriteFile(src, plain, 0o600))
+
+	enc := filepath.Join(dir, "backup.tar.enc")
+	require.NoError(t, s.EncryptFile(src, enc))
+
+	dec := filepath.Join(dir, "restored.tar")
+	require.NoError(t, s.DecryptFile(enc, dec))
+	restored, err := os.ReadFile(dec)
+	require.NoError(t, err)
+	assert.Equal(t, plain, restored)
+
+	// 认证失败时不生成目标文件，也不残留临时文件
+	data, err := os.ReadFile(enc)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(enc, data[:len(data)-1], 0o600))
+
+	broken := filepath.Join(dir, "broken.tar")
+	assert.Error(t, s.DecryptFile(enc, broken))
+	assert.NoFileExists(t, broken)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func TestNewStreamCipher_InvalidArgs(t *testing.T) {
+	_, err := NewAESGCMStreamCipher([]byte("short"))
+	assert.Error(t, err)
+	_, err = NewSM4GCMStreamCipher(make([]byte, 32))
+	assert.Error(t, err)
+	_, err = NewAESGCMStreamCipher(make([]byte, 16), WithChunkSize(0))
+	assert.Error(t, err)
+	_, err = NewAESGCMStreamCipher(make([]byte, 16), WithChunkSize(MaxStreamChunkSize+1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// X25519Cipher 实现 KeyExchanger 接口，使用 X25519 密钥协商
+// 共享密钥不应直接作为加密密钥使用，请通过 DeriveSessionKey 派生
+type X25519Cipher struct {
+	privateKey *ecdh.PrivateKey
+	publicKey  *ecdh.PublicKey
+}
+
+// NewX25519Cipher 生成新的X25519密钥对
+func NewX25519Cipher() (*X25519Cipher, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &X25519Cipher{privateKey: priv, publicKey: priv.PublicKey()}, nil
+}
+
+// NewX25519CipherFromKey 用已有私钥初始化
+func NewX25519CipherFromKey(priv *ecdh.PrivateKey) (*X25519Cipher, error) {
+	if priv == nil {
+		return nil, errors.New("private key is nil")
+	}
+	if priv.Curve() != ecdh.X25519() {
+		return nil, errors.New("private key is not an X25519 key")
+	}
+	return &X25519Cipher{privateKey: priv, publicKey: priv.PublicKey()}, nil
+}
+
+// PublicKeyBytes 获取公钥字节（32字节）
+func (x *X25519Cipher) PublicKeyBytes() []byte {
+	return x.publicKey.Bytes()
+}
+
+// DeriveSharedSecret 计算共享密钥，对方公钥为低阶点时返回错误
+func (x *X25519Cipher) DeriveSharedSecret(peerPubBytes []byte) ([]byte, error) {
+	if x.privateKey == nil {
+		return nil, errors.New("private key is nil")
+	}
+	peerPub, err := ecdh.X25519().NewPublicKey(peerPubBytes)
+	if err != nil {
+		return nil, fmt.Errorf("x25519 public key: %w", err)
+	}
+	secret, err := x.privateKey.ECDH(peerPub)
+	if err != nil {
+		return nil, fmt.Errorf("x25519 derive: %w", err)
+	}
+	return secret, nil
+}
+
+// PublicKey 获取公钥（用于导出、传输给对方）
+func (x *X25519Cipher) PublicKey() *ecdh.PublicKey { return x.publicKey }
+
+// PrivateKey 获取私钥（仅用于安全存储，禁止对外泄露）
+func (x *X25519Cipher) PrivateKey() *ecdh.PrivateKey { return x.privateKey }
+
+// Name 返回算法名称
+func (x *X25519Cipher) Name() string {
+	return "X25519"
+}
@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestX25519Cipher_DeriveSharedSecret(t *testing.T) {
+	alice, err := NewX25519Cipher()
+	require.NoError(t, err)
+	bob, err := NewX25519Cipher()
+	require.NoError(t, err)
+
+	var _ KeyExchanger = alice
+	assert.Len(t, alice.PublicKeyBytes(), 32)
+
+	s1, err := alice.DeriveSharedSecret(bob.PublicKeyBytes())
+	require.NoError(t, err)
+	s2, err := bob.DeriveSharedSecret(alice.PublicKeyBytes())
+	require.NoError(t, err)
+	assert.Equal(t, s1, s2)
+
+	// 低阶点（全零）得到全零共享密钥，必须拒绝
+	_, err = alice.DeriveSharedSecret(make([]byte, 32))
+	assert.Error(t, err)
+	_, err = alice.DeriveSharedSecret([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestX25519Cipher_RFC7748(t *testing.T) {
+	// RFC 7748 第 6.1 节
+	alicePriv, _ := hex.DecodeString("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	bobPub, _ := hex.DecodeString("de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+
+	priv, err := ecdh.X25519().NewPrivateKey(alicePriv)
+	require.NoError(t, err)
+	alice, err := NewX25519CipherFromKey(priv)
+	require.NoError(t, err)
+	assert.Equal(t, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a", hex.EncodeToString(alice.PublicKeyBytes()))
+
+	shared, err := alice.DeriveSharedSecret(bobPub)
+	require.NoError(t, err)
+	assert.Equal(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(shared))
+}
+
+func TestX25519Cipher_Keys(t *testing.T) {
+	cipher, err := NewX25519Cipher()
+	require.NoError(t, err)
+
+	pem, err := ExportPrivateKey(cipher)
+	require.NoError(t, err)
+	loaded, err := LoadX25519Cipher(pem, nil)
+	require.NoError(t, err)
+	assert.Equal(t, cipher.PublicKeyBytes(), loaded.PublicKeyBytes())
+
+	p256, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = NewX25519CipherFromKey(p256)
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package eventloop
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Future 异步请求的类型化结果句柄
+type Future[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// CallAsync 以 ctx 作为事件上下文提交请求事件，立即返回 Future。
+// ctx 的截止时间会随事件传递：若处理开始前调用方已取消或超时，事件将被跳过而不会进入 EventProcessor。
+func CallAsync[T any](ctx context.Context, el *EventLoop, typ string, payload any, opts ...EventOption) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	reply := make(chan Result, 1)
+	ev := NewEvent(typ, payload, opts...)
+	ev.Ctx = ctx
+	ev.Callback = reply
+
+	if err := el.SubmitBlocking(ctx, ev); err != nil {
+		f.err = err
+		close(f.done)
+		return f
+	}
+
+	go func() {
+		defer close(f.done)
+		select {
+		case res := <-reply:
+			f.val, f.err = resultAs[T](res)
+		case <-ctx.Done():
+			f.err = ctx.Err()
+		case <-el.ctx.Done():
+			f.err = ErrEventLoopStopped
+		}
+	}()
+	return f
+}
+
+// Call 同步提交请求事件并等待类型化结果，等价于 CallAsync(...).Await()
+func Call[T any](ctx context.Context, el *EventLoop, typ string, payload any, opts ...EventOption) (T, error) {
+	return CallAsync[T](ctx, el, typ, payload, opts...).Await()
+}
+
+// Done 返回在结果就绪时关闭的通道，便于在 select 中使用
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await 阻塞等待结果
+func (f *Future[T]) Await() (T, error) {
+	<-f.done
+	return f.val, f.err
+}
+
+// AwaitTimeout 最多等待 timeout，超时返回 context.DeadlineExceeded（不影响后续再次等待）
+func (f *Future[T]) AwaitTimeout(timeout time.Duration) (T, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-timer.C:
+		var zero T
+		return zero, context.DeadlineExceeded
+	}
+}
+
+// resultAs 将 Result 转换为类型化结果
+func resultAs[T any](res Result) (T, error) {
+	var zero T
+	if res.Err != nil {
+		return zero, res.Err
+	}
+	if res.Data == nil {
+		return zero, nil
+	}
+	v, ok := res.Data.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: got %T, want %T", ErrUnexpectedResultType, res.Data, zero)
+	}
+	return v, nil
+}
+
+// contextErr 返回上下文的取消原因；截止时间已过但定时器尚未触发时同样视为超时
+func contextErr(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
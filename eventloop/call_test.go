@@ -0,0 +1,110 @@
+package eventloop
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 回显处理器：按事件类型返回不同结果，可选阻塞直到 release 关闭。
+type echoProcessor struct {
+	release chan struct{}
+	calls   chan string
+}
+
+func (p *echoProcessor) Process(ev Event) Result {
+	if p.calls != nil {
+		p.calls <- ev.Type
+	}
+	if ev.Type == "block" && p.release != nil {
+		<-p.release
+	}
+	switch ev.Type {
+	case "upper":
+		return Result{Data: strings.ToUpper(ev.Data.(string))}
+	case "fail":
+		return Result{Err: errors.New("boom")}
+	default:
+		return Result{Data: ev.Data}
+	}
+}
+
+// TestCallTypedResult 验证 Call 返回类型化结果及错误。
+func TestCallTypedResult(t *testing.T) {
+	el := NewEventLoop(10, &echoProcessor{}, false)
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	s, err := Call[string](ctx, el, "upper", "hello", WithPriority(PriorityHigh))
+	if err != nil || s != "HELLO" {
+		t.Fatalf("expected HELLO, got %q (%v)", s, err)
+	}
+
+	if _, err = Call[string](ctx, el, "fail", "x"); err == nil || err.Error() != "boom" {
+		t.Fatalf("expected boom error, got %v", err)
+	}
+
+	if _, err = Call[int](ctx, el, "echo", "x"); !errors.Is(err, ErrUnexpectedResultType) {
+		t.Fatalf("expected ErrUnexpectedResultType, got %v", err)
+	}
+
+	f := CallAsync[string](ctx, el, "upper", "future")
+	select {
+	case <-f.Done():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for future")
+	}
+	if s, err = f.Await(); err != nil || s != "FUTURE" {
+		t.Fatalf("expected FUTURE, got %q (%v)", s, err)
+	}
+}
+
+// TestCallSkippedAfterDeadline 验证调用方超时后，排队中的事件不会被处理，并计入 Metrics。
+func TestCallSkippedAfterDeadline(t *testing.T) {
+	proc := &echoProcessor{release: make(chan struct{}), calls: make(chan string, 10)}
+	el := NewEventLoop(10, proc, false)
+	metrics := NewSimpleMetrics()
+	el.SetMetrics(metrics)
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el.Stop()
+
+	// 先阻塞事件循环
+	blocker := CallAsync[string](context.Background(), el, "block", "b", WithPriority(PriorityHigh))
+	<-proc.calls
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := Call[string](ctx, el, "upper", "late", WithPriority(PriorityMedium)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	close(proc.release)
+	if _, err := blocker.AwaitTimeout(time.Second); err != nil {
+		t.Fatalf("blocker failed: %v", err)
+	}
+
+	// 等待被跳过的事件出队
+	deadline := time.Now().Add(time.Second)
+	for metrics.Snapshot().CanceledMedium == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for canceled metric")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	select {
+	case typ := <-proc.calls:
+		t.Fatalf("expected canceled event to be skipped, but processed %q", typ)
+	default:
+	}
+}
@@ -0,0 +1,64 @@
+package eventloop
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// Codec 负责 Event.Data 与字节之间的编解码，用于事件日志（Journal）持久化。
+type Codec interface {
+	// Marshal 将事件数据编码为字节，typ 为事件类型
+	Marshal(typ string, data any) ([]byte, error)
+	// Unmarshal 将字节解码为事件数据，typ 为事件类型
+	Unmarshal(typ string, b []byte) (any, error)
+}
+
+// JSONCodec 基于 encoding/json 的默认编解码器。
+// 通过 Register 为事件类型登记原型后，解码时会还原为相同的 Go 类型；
+// 未登记的事件类型按 encoding/json 的默认规则解码（map[string]any、float64 等）。
+type JSONCodec struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// NewJSONCodec 创建 JSONCodec
+func NewJSONCodec() *JSONCodec {
+	return &JSONCodec{types: make(map[string]reflect.Type)}
+}
+
+// Register 为事件类型登记数据原型，例如 Register("move", MoveCmd{})
+func (c *JSONCodec) Register(typ string, prototype any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if prototype == nil {
+		delete(c.types, typ)
+		return
+	}
+	c.types[typ] = reflect.TypeOf(prototype)
+}
+
+func (c *JSONCodec) Marshal(_ string, data any) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+func (c *JSONCodec) Unmarshal(typ string, b []byte) (any, error) {
+	c.mu.RLock()
+	t, ok := c.types[typ]
+	c.mu.RUnlock()
+
+	if !ok {
+		var v any
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	v := reflect.New(t)
+	if err := json.Unmarshal(b, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
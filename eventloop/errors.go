@@ -12,4 +12,10 @@ var (
 	ErrLowQueueFull    = errors.New("low priority queue full")
 
 	ErrUnknownPriority = errors.New("unknown priority")
+
+	ErrUnexpectedResultType = errors.New("unexpected result type")
+
+	ErrJournalClosed           = errors.New("journal closed")
+	ErrJournalCorrupted        = errors.New("journal corrupted")
+	ErrJournalSnapshotMismatch = errors.New("journal snapshot does not match processor")
 )
@@ -14,6 +14,19 @@ const (
 	PriorityLow
 )
 
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityMedium:
+		return "medium"
+	case PriorityLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
 const (
 	FrameInterval = 50 * time.Millisecond // 20Hz 逻辑帧
 	FrameBudget   = 25 * time.Millisecond // 预留50%缓冲
@@ -37,6 +50,8 @@ type Event struct {
 	Ctx      context.Context
 
 	TS time.Time // 事件发送时间
+
+	seq uint64 // 事件日志序号，0 表示未写入日志
 }
 
 type EventOption func(*Event)
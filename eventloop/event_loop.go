@@ -2,6 +2,8 @@ package eventloop
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"sync"
 	"sync/atomic"
@@ -41,6 +43,8 @@ type EventLoop struct {
 	frameBudget   time.Duration // 帧时间预算
 	maxLowTime    time.Duration // 每帧最大低优先级处理时间
 	frameDriven   bool          // 是否启用帧驱动模式
+
+	journal *Journal // 可选的预写式事件日志
 }
 
 // NewEventLoop 创建并返回一个 EventLoop 实例
@@ -90,6 +94,12 @@ func (el *EventLoop) SetMetrics(m Metrics) {
 	el.metrics = m
 }
 
+// SetJournal 注入预写式事件日志，需在 Start 之前调用。
+// Start 时会先从最近一次快照恢复处理器状态并重放其后的事件；Stop 时会保存快照并关闭日志。
+func (el *EventLoop) SetJournal(j *Journal) {
+	el.journal = j
+}
+
 // SetCallbackInline 切换回调投递模式；inline=true 表示在事件循环内同步投递，timeout 控制同步投递的超时（0 表示无限等待）。
 func (el *EventLoop) SetCallbackInline(inline bool, timeout time.Duration) {
 	el.mu.Lock()
@@ -124,6 +134,12 @@ func (el *EventLoop) Start() error {
 		return nil
 	}
 
+	// 从事件日志恢复处理器状态
+	if err := el.replayJournal(); err != nil {
+		el.running.Store(false)
+		return err
+	}
+
 	startedCh := make(chan struct{})
 	el.mu.Lock()
 	el.started.Store(&startedCh)
@@ -194,6 +210,13 @@ func (el *EventLoop) Stop() {
 	el.cancel()
 
 	el.wg.Wait()
+
+	if el.journal != nil {
+		el.takeSnapshot()
+		if err := el.journal.Close(); err != nil {
+			el.logger.Errorf("close journal failed: %v", err)
+		}
+	}
 }
 
 // IsRunning 返回事件循环是否正在运行
@@ -218,6 +241,29 @@ func (el *EventLoop) Submit(event Event) error {
 	if !el.running.Load() {
 		return ErrEventLoopNotRunning
 	}
+	if err := el.journalAppend(&event); err != nil {
+		return err
+	}
+	err := el.trySubmit(event)
+	el.observeSubmit(event, err)
+	if err != nil {
+		el.journalDiscard(event)
+	}
+	return err
+}
+
+// observeSubmit 上报提交结果：成功计为提交，队列满计为丢弃
+func (el *EventLoop) observeSubmit(event Event, err error) {
+	switch {
+	case err == nil:
+		el.metrics.IncSubmitted(event.Priority)
+	case errors.Is(err, ErrHighQueueFull), errors.Is(err, ErrMediumQueueFull), errors.Is(err, ErrLowQueueFull):
+		el.metrics.IncDropped(event.Priority)
+	}
+}
+
+// trySubmit 非阻塞地将事件放入对应优先级队列
+func (el *EventLoop) trySubmit(event Event) error {
 	switch event.Priority {
 	case PriorityHigh:
 		select {
@@ -253,6 +299,19 @@ func (el *EventLoop) SubmitBlocking(ctx context.Context, event Event) error {
 	if !el.running.Load() {
 		return ErrEventLoopNotRunning
 	}
+	if err := el.journalAppend(&event); err != nil {
+		return err
+	}
+	err := el.submitBlocking(ctx, event)
+	el.observeSubmit(event, err)
+	if err != nil {
+		el.journalDiscard(event)
+	}
+	return err
+}
+
+// submitBlocking 阻塞地将事件放入对应优先级队列
+func (el *EventLoop) submitBlocking(ctx context.Context, event Event) error {
 	switch event.Priority {
 	case PriorityHigh:
 		select {
@@ -369,19 +428,23 @@ func (el *EventLoop) handleEvent(event Event) {
 		evCtx = el.ctx
 	}
 
-	// 1. 优先检查上下文是否已取消/超时
-	select {
-	case <-evCtx.Done():
+	// 1. 优先检查上下文是否已取消/超时：调用方已放弃时跳过处理
+	if err := contextErr(evCtx); err != nil {
+		// 未处理的事件在日志中撤销，重放时跳过，保证恢复出的状态与实际执行一致
+		el.journalDiscard(event)
+		if cm, ok := el.metrics.(CancelMetrics); ok && event.Ctx != nil {
+			cm.IncCanceled(event.Priority)
+		}
 		if event.Callback != nil {
-			el.deliverResult(event, Result{Err: evCtx.Err()})
+			el.deliverResult(event, Result{Err: err})
 		}
 		return
-	default:
 	}
 
 	// 2. 调用业务处理器处理事件（保护 processor 为空）
 	if el.processor == nil {
 		// 没有处理器，直接回调错误结果
+		el.journalDiscard(event)
 		if event.Callback != nil {
 			el.deliverResult(event, Result{Err: ErrNoEventProcessor})
 		}
@@ -390,11 +453,21 @@ func (el *EventLoop) handleEvent(event Event) {
 
 	// 3. 执行处理（由业务负责不阻塞太久）
 	start := time.Now()
+	if !event.TS.IsZero() {
+		if wm, ok := el.metrics.(QueueWaitMetrics); ok {
+			wm.ObserveQueueWait(event.Priority, event.Type, start.Sub(event.TS))
+		}
+	}
 	result := el.processor.Process(event)
 	elapsed := time.Since(start)
+	el.journalProcessed(event)
 
 	// 上报耗时（由具体 Metrics 实现决定如何采集）
+	el.metrics.IncProcessed(event.Priority)
 	el.metrics.ObserveProcessingDuration(event.Priority, elapsed)
+	if tm, ok := el.metrics.(EventTypeMetrics); ok {
+		tm.ObserveEventTypeProcessing(event.Type, elapsed)
+	}
 
 	// 4. 统一回调投递（根据模式选择 inline 或异步）
 	if event.Callback != nil {
@@ -402,6 +475,13 @@ func (el *EventLoop) handleEvent(event Event) {
 	}
 }
 
+// incFrameOverrun 上报帧超预算，Metrics 未实现 FrameMetrics 时忽略
+func (el *EventLoop) incFrameOverrun() {
+	if fm, ok := el.metrics.(FrameMetrics); ok {
+		fm.IncFrameOverrun()
+	}
+}
+
 // processFrame 处理单帧事件，按优先级顺序处理，遵守时间预算。
 func (el *EventLoop) processFrame() {
 	frameStart := time.Now()
@@ -411,6 +491,7 @@ func (el *EventLoop) processFrame() {
 		ev := <-el.highChan
 		el.handleEvent(ev)
 		if time.Since(frameStart) >= el.frameBudget {
+			el.incFrameOverrun()
 			log.Println("Frame budget exceeded during high-priority processing")
 			return
 		}
@@ -421,6 +502,7 @@ func (el *EventLoop) processFrame() {
 		ev := <-el.mediumChan
 		el.handleEvent(ev)
 		if time.Since(frameStart) >= el.frameBudget {
+			el.incFrameOverrun()
 			log.Println("Frame budget exceeded during medium-priority processing")
 			return
 		}
@@ -463,6 +545,7 @@ func (el *EventLoop) enqueueCallback(item callbackItem) {
 				return
 			case <-timer.C:
 				// 超时后放弃并记录
+				el.metrics.IncCallbackDiscarded()
 				el.logger.Warnf("enqueue callback timeout, discard result")
 				return
 			}
@@ -494,6 +577,7 @@ func (el *EventLoop) callbackDispatcher() {
 					goto next
 				case <-deadline:
 					// 超时后放弃并记录
+					el.metrics.IncCallbackDiscarded()
 					el.logger.Warnf("callback deliver timeout, discard result")
 					goto next
 				}
@@ -547,16 +631,21 @@ func (el *EventLoop) deliverResult(event Event, result Result) {
 		case <-el.ctx.Done():
 			// loop stopped
 		case <-timer.C:
+			el.metrics.IncInlineTimeout()
 			el.logger.Warnf("inline callback deliver timeout, discard result for event priority: %v", event.Priority)
 		}
 		return
 	}
 
 	// 异步模式：使用现有的 enqueueCallback 来可靠投递（可能重试直到超时）
+	itemCtx := event.Ctx
+	if itemCtx == nil {
+		itemCtx = el.ctx
+	}
 	item := callbackItem{
 		cb:  event.Callback,
 		res: result,
-		ctx: event.Ctx,
+		ctx: itemCtx,
 	}
 	el.enqueueCallback(item)
 }
@@ -567,3 +656,96 @@ type callbackItem struct {
 	res Result
 	ctx context.Context
 }
+
+// replayJournal 从事件日志恢复：先用快照恢复处理器状态，再按实际处理顺序重放快照之后的事件（不投递回调）
+func (el *EventLoop) replayJournal() error {
+	if el.journal == nil {
+		return nil
+	}
+
+	// 快照与处理器不匹配时拒绝启动，否则处理器会从零开始只重放快照之后的事件
+	if state, stateless, found := el.journal.takeState(); found {
+		sn, ok := el.processor.(Snapshotter)
+		switch {
+		case !stateless && !ok:
+			return fmt.Errorf("%w: found a state snapshot but the processor does not implement Snapshotter", ErrJournalSnapshotMismatch)
+		case stateless && ok:
+			return fmt.Errorf("%w: found a checkpoint without processor state", ErrJournalSnapshotMismatch)
+		case ok:
+			if err := sn.Restore(state); err != nil {
+				return err
+			}
+		}
+	}
+
+	events, err := el.journal.takeReplay()
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	if el.processor == nil {
+		return ErrNoEventProcessor
+	}
+
+	el.logger.Infof("replaying %d journal events", len(events))
+	for _, ev := range events {
+		el.processor.Process(ev)
+	}
+	return nil
+}
+
+// journalAppend 将事件写入事件日志，并记录分配的序号
+func (el *EventLoop) journalAppend(event *Event) error {
+	if el.journal == nil {
+		return nil
+	}
+	seq, err := el.journal.append(*event)
+	if err != nil {
+		return err
+	}
+	event.seq = seq
+	return nil
+}
+
+// journalDiscard 撤销未能入队或被跳过处理的事件
+func (el *EventLoop) journalDiscard(event Event) {
+	if el.journal == nil || event.seq == 0 {
+		return
+	}
+	if err := el.journal.discard(event.seq); err != nil {
+		el.logger.Errorf("discard journal event %d failed: %v", event.seq, err)
+	}
+}
+
+// journalProcessed 记录事件的处理顺序，并在达到间隔时保存快照
+func (el *EventLoop) journalProcessed(event Event) {
+	if el.journal == nil || event.seq == 0 {
+		return
+	}
+	snapshot, err := el.journal.markProcessed(event.seq)
+	if err != nil {
+		el.logger.Errorf("mark journal event %d processed failed: %v", event.seq, err)
+	}
+	if snapshot {
+		el.takeSnapshot()
+	}
+}
+
+// takeSnapshot 导出处理器状态并写入快照，需在事件循环 goroutine 内（或循环停止后）调用；
+// 处理器未实现 Snapshotter 时写入不含状态的检查点，使已处理的日志段同样可以被清理
+func (el *EventLoop) takeSnapshot() {
+	var state []byte
+	sn, ok := el.processor.(Snapshotter)
+	if ok {
+		var err error
+		if state, err = sn.Snapshot(); err != nil {
+			el.logger.Errorf("take snapshot failed: %v", err)
+			return
+		}
+	}
+	if err := el.journal.writeSnapshot(state, !ok); err != nil {
+		el.logger.Errorf("write snapshot failed: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package eventloop
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -101,3 +102,62 @@ func TestFrameDrivenMetricsRecording(t *testing.T) {
 		t.Fatalf("expected AvgProcessingNsHigh > 0, got 0; snapshot: %+v", snap)
 	}
 }
+
+// baseMetrics 只实现 Metrics 基础方法，不实现任何可选扩展接口。
+type baseMetrics struct {
+	processed atomic.Uint64
+}
+
+func (m *baseMetrics) IncSubmitted(Priority)                             {}
+func (m *baseMetrics) IncDropped(Priority)                               {}
+func (m *baseMetrics) IncProcessed(Priority)                             { m.processed.Add(1) }
+func (m *baseMetrics) IncCallbackDiscarded()                             {}
+func (m *baseMetrics) IncInlineTimeout()                                 {}
+func (m *baseMetrics) ObserveProcessingDuration(Priority, time.Duration) {}
+func (m *baseMetrics) Snapshot() MetricsSnapshot                         { return MetricsSnapshot{} }
+
+// TestMetricsWithoutExtensions 验证只实现 Metrics 基础方法的自定义实现可正常使用，
+// 帧超预算、排队等待、按类型耗时与取消计数等扩展统计被忽略。
+func TestMetricsWithoutExtensions(t *testing.T) {
+	ch := make(chan string, 2)
+	proc := &frameTestProcessor{ch: ch, workTime: 2 * time.Millisecond}
+
+	el := NewEventLoop(10, proc, true)
+	el.frameInterval = 10 * time.Millisecond
+	el.frameBudget = time.Millisecond // 每个事件都会超出预算
+	el.maxLowTime = 10 * time.Millisecond
+
+	metrics := &baseMetrics{}
+	el.SetMetrics(metrics)
+
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := el.Submit(NewEvent("c", "canceled", WithPriority(PriorityHigh), WithContext(ctx))); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if err := el.Submit(NewEvent("h", "h", WithPriority(PriorityHigh))); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+
+	select {
+	case got := <-ch:
+		if got != "h" {
+			t.Fatalf("expected h, got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for processed event")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for metrics.processed.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for processed metric")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,116 @@
+package eventloop
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyBuckets 默认延迟直方图桶上界（50µs ~ 1s）
+var DefaultLatencyBuckets = []time.Duration{
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// Histogram 固定桶的并发安全延迟直方图，基于原子计数。
+type Histogram struct {
+	bounds []time.Duration // 桶上界（升序），最后隐含 +Inf 桶
+	counts []atomic.Uint64 // 各桶计数（非累积），长度为 len(bounds)+1
+	sumNs  atomic.Uint64   // 观测值总和（纳秒）
+}
+
+// NewHistogram 创建直方图；buckets 为空时使用 DefaultLatencyBuckets
+func NewHistogram(buckets []time.Duration) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	bounds := append([]time.Duration(nil), buckets...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+// Observe 记录一次观测，负值按 0 处理
+func (h *Histogram) Observe(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	idx := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] })
+	h.counts[idx].Add(1)
+	h.sumNs.Add(uint64(d.Nanoseconds()))
+}
+
+// Snapshot 返回直方图的只读快照
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	snap := HistogramSnapshot{
+		Bounds: h.bounds,
+		Counts: make([]uint64, len(h.counts)),
+	}
+	for i := range h.counts {
+		snap.Counts[i] = h.counts[i].Load()
+		snap.Count += snap.Counts[i]
+	}
+	snap.Sum = time.Duration(h.sumNs.Load())
+	return snap
+}
+
+// HistogramSnapshot 直方图快照
+type HistogramSnapshot struct {
+	Bounds []time.Duration // 桶上界（升序）
+	Counts []uint64        // 各桶计数（非累积），最后一项为 +Inf 桶
+	Count  uint64          // 总观测次数
+	Sum    time.Duration   // 观测值总和
+}
+
+// Mean 返回平均值，无数据时返回 0
+func (s HistogramSnapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// Quantile 按桶内线性插值估算分位数（q 取值 0~1），落入 +Inf 桶时返回最大的有限上界
+func (s HistogramSnapshot) Quantile(q float64) time.Duration {
+	if s.Count == 0 || len(s.Bounds) == 0 {
+		return 0
+	}
+	q = min(max(q, 0), 1)
+
+	rank := q * float64(s.Count)
+	var cumulative float64
+	for i, c := range s.Counts {
+		if c == 0 {
+			continue
+		}
+		if cumulative+float64(c) < rank {
+			cumulative += float64(c)
+			continue
+		}
+		if i >= len(s.Bounds) {
+			return s.Bounds[len(s.Bounds)-1]
+		}
+		var lower time.Duration
+		if i > 0 {
+			lower = s.Bounds[i-1]
+		}
+		frac := (rank - cumulative) / float64(c)
+		return lower + time.Duration(frac*float64(s.Bounds[i]-lower))
+	}
+	return s.Bounds[len(s.Bounds)-1]
+}
@@ -0,0 +1,550 @@
+package eventloop
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSegmentSize = 64 << 20 // 单个段文件默认 64MB
+
+	segmentExt       = ".wal"
+	snapshotFileName = "snapshot.snap"
+
+	recordHeaderSize = 8 // 4 字节长度 + 4 字节 CRC32
+	recordFixedSize  = 1 + 8 + 1 + 8 + 2
+
+	recordKindEvent     byte = 1 // 事件记录
+	recordKindDiscard   byte = 2 // 撤销记录：事件已写入日志但未能入队或被跳过处理
+	recordKindProcessed byte = 3 // 处理记录：按实际处理顺序写入，重放时依此顺序执行
+)
+
+// Snapshotter 可选接口，由 EventProcessor 实现，用于保存与恢复处理器状态。
+// 两个方法均在事件循环 goroutine 内调用，无需额外加锁。
+type Snapshotter interface {
+	// Snapshot 导出当前处理器状态
+	Snapshot() ([]byte, error)
+	// Restore 从快照恢复处理器状态，在重放日志之前调用
+	Restore(state []byte) error
+}
+
+type JournalOption func(*Journal)
+
+// WithJournalCodec 设置 Event.Data 的编解码器，默认 JSONCodec
+func WithJournalCodec(c Codec) JournalOption {
+	return func(j *Journal) {
+		if c != nil {
+			j.codec = c
+		}
+	}
+}
+
+// WithSegmentSize 设置单个段文件的大小上限，超过后滚动到新段
+func WithSegmentSize(size int64) JournalOption {
+	return func(j *Journal) {
+		if size > 0 {
+			j.segmentSize = size
+		}
+	}
+}
+
+// WithSyncWrites 设置每次写入后是否 fsync；默认关闭，仅保证进程崩溃不丢数据
+func WithSyncWrites(sync bool) JournalOption {
+	return func(j *Journal) { j.syncWrites = sync }
+}
+
+// WithSnapshotEvery 设置每处理 n 个日志事件自动保存一次快照，0 表示仅在 Stop 时保存。
+// 处理器未实现 Snapshotter 时保存不含状态的检查点，仅用于清理已处理的日志段
+func WithSnapshotEvery(n int) JournalOption {
+	return func(j *Journal) {
+		if n >= 0 {
+			j.snapshotEvery = n
+		}
+	}
+}
+
+// Journal 预写式事件日志：提交的事件被追加到本地段文件，
+// 配合 Snapshotter 快照，可在 Start 时从最近一次快照起重放事件以重建处理器状态。
+// 处理器未实现 Snapshotter 时只写入检查点：已处理的事件在检查点后被清理，重启时只重放检查点之后的事件。
+type Journal struct {
+	dir           string
+	codec         Codec
+	segmentSize   int64
+	syncWrites    bool
+	snapshotEvery int
+
+	mu       sync.Mutex
+	closed   bool
+	seq      uint64              // 最后分配的序号
+	pending  map[uint64]struct{} // 已写入日志但尚未处理的序号
+	segments []uint64            // 段文件起始序号（升序）
+	file     *os.File            // 当前写入的段文件
+	fileSize int64
+
+	processed uint64 // 已处理的日志事件数，仅事件循环 goroutine 访问
+
+	state     []byte          // 加载时读取的快照状态
+	stateless bool            // 加载的是不含处理器状态的检查点
+	hasSnap   bool            // 加载时是否存在快照
+	replay    []journalRecord // 加载时读取的待重放事件（按实际处理顺序，未处理的按序号排在最后）
+}
+
+// journalRecord 单条日志记录
+type journalRecord struct {
+	kind     byte
+	seq      uint64
+	priority Priority
+	ts       time.Time
+	typ      string
+	data     []byte
+}
+
+// journalSnapshot 快照文件内容
+type journalSnapshot struct {
+	Seq       uint64   `json:"seq"`                 // 快照时已分配的最大序号
+	Pending   []uint64 `json:"pending"`             // 快照时仍未处理的序号
+	State     []byte   `json:"state"`               // 处理器状态
+	Stateless bool     `json:"stateless,omitempty"` // 处理器未实现 Snapshotter 时写入的检查点，不含状态
+}
+
+// NewJournal 打开（或创建）dir 下的事件日志，并加载快照与待重放事件
+func NewJournal(dir string, opts ...JournalOption) (*Journal, error) {
+	j := &Journal{
+		dir:         dir,
+		codec:       NewJSONCodec(),
+		segmentSize: defaultSegmentSize,
+		pending:     make(map[uint64]struct{}),
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Close 关闭日志文件
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return nil
+	}
+	j.closed = true
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// load 读取快照与全部段文件，计算需要重放的事件
+func (j *Journal) load() error {
+	snap, found, err := j.readSnapshot()
+	if err != nil {
+		return err
+	}
+
+	segments, err := j.listSegments()
+	if err != nil {
+		return err
+	}
+
+	needed := make(map[uint64]struct{}, len(snap.Pending))
+	for _, seq := range snap.Pending {
+		needed[seq] = struct{}{}
+	}
+
+	maxSeq := snap.Seq
+	discarded := make(map[uint64]struct{})
+	events := make(map[uint64]journalRecord)
+	var order []uint64 // 快照之后实际处理的顺序
+
+	for i, start := range segments {
+		path := j.segmentPath(start)
+		records, valid, err := readSegment(path)
+		if err != nil {
+			// 仅允许最后一个段存在写入中断导致的残缺尾部，截断后继续
+			if !errors.Is(err, errTornRecord) || i != len(segments)-1 {
+				return fmt.Errorf("%w: %s: %v", ErrJournalCorrupted, filepath.Base(path), err)
+			}
+			if err = os.Truncate(path, valid); err != nil {
+				return err
+			}
+		}
+
+		for _, rec := range records {
+			if rec.seq > maxSeq {
+				maxSeq = rec.seq
+			}
+			// 快照时仍未处理或快照之后提交的事件才需要重放
+			if _, ok := needed[rec.seq]; !ok && rec.seq <= snap.Seq {
+				continue
+			}
+			switch rec.kind {
+			case recordKindDiscard:
+				discarded[rec.seq] = struct{}{}
+			case recordKindEvent:
+				events[rec.seq] = rec
+			case recordKindProcessed:
+				order = append(order, rec.seq)
+			}
+		}
+	}
+
+	// 已处理的事件按实际处理顺序重放（优先级调度与并发提交都会使其不同于序号顺序），
+	// 崩溃时尚未处理的事件按序号排在最后
+	replay := make([]journalRecord, 0, len(events))
+	for _, seq := range order {
+		if rec, ok := events[seq]; ok {
+			replay = append(replay, rec)
+			delete(events, seq)
+		}
+	}
+	tail := make([]journalRecord, 0, len(events))
+	for seq, rec := range events {
+		if _, ok := discarded[seq]; !ok {
+			tail = append(tail, rec)
+		}
+	}
+	sort.Slice(tail, func(a, b int) bool { return tail[a].seq < tail[b].seq })
+	replay = append(replay, tail...)
+
+	j.seq = maxSeq
+	j.segments = segments
+	j.state = snap.State
+	j.stateless = snap.Stateless
+	j.hasSnap = found
+	j.replay = replay
+	return nil
+}
+
+// takeReplay 取出待重放事件并解码，只能调用一次
+func (j *Journal) takeReplay() ([]Event, error) {
+	records := j.replay
+	j.replay = nil
+
+	events := make([]Event, 0, len(records))
+	for _, rec := range records {
+		data, err := j.codec.Unmarshal(rec.typ, rec.data)
+		if err != nil {
+			return nil, fmt.Errorf("decode journal event %d (%s): %w", rec.seq, rec.typ, err)
+		}
+		events = append(events, Event{
+			Priority: rec.priority,
+			Type:     rec.typ,
+			Data:     data,
+			TS:       rec.ts,
+			seq:      rec.seq,
+		})
+	}
+	return events, nil
+}
+
+// takeState 取出加载时读取的快照状态，只能调用一次；
+// found 表示存在快照，stateless 表示该快照是不含处理器状态的检查点
+func (j *Journal) takeState() (state []byte, stateless, found bool) {
+	state, stateless, found = j.state, j.stateless, j.hasSnap
+	j.state = nil
+	return state, stateless, found
+}
+
+// append 将事件写入日志并返回分配的序号
+func (j *Journal) append(event Event) (uint64, error) {
+	data, err := j.codec.Marshal(event.Type, event.Data)
+	if err != nil {
+		return 0, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return 0, ErrJournalClosed
+	}
+
+	rec := journalRecord{
+		kind:     recordKindEvent,
+		seq:      j.seq + 1,
+		priority: event.Priority,
+		ts:       event.TS,
+		typ:      event.Type,
+		data:     data,
+	}
+	if err = j.write(rec); err != nil {
+		return 0, err
+	}
+
+	j.seq = rec.seq
+	j.pending[rec.seq] = struct{}{}
+	return rec.seq, nil
+}
+
+// discard 撤销已写入日志但未能入队或被跳过处理的事件，重放时将跳过该事件
+func (j *Journal) discard(seq uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	delete(j.pending, seq)
+	if j.closed {
+		return ErrJournalClosed
+	}
+	return j.write(journalRecord{kind: recordKindDiscard, seq: seq})
+}
+
+// markProcessed 写入处理记录并标记事件已处理，返回是否需要保存快照
+func (j *Journal) markProcessed(seq uint64) (bool, error) {
+	j.mu.Lock()
+	delete(j.pending, seq)
+	err := ErrJournalClosed
+	if !j.closed {
+		err = j.write(journalRecord{kind: recordKindProcessed, seq: seq})
+	}
+	j.mu.Unlock()
+
+	j.processed++
+	return j.snapshotEvery > 0 && j.processed%uint64(j.snapshotEvery) == 0, err
+}
+
+// writeSnapshot 原子写入快照文件，并删除快照已覆盖的段文件；stateless 表示写入不含状态的检查点
+func (j *Journal) writeSnapshot(state []byte, stateless bool) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	snap := journalSnapshot{
+		Seq:       j.seq,
+		Pending:   make([]uint64, 0, len(j.pending)),
+		State:     state,
+		Stateless: stateless,
+	}
+	for seq := range j.pending {
+		snap.Pending = append(snap.Pending, seq)
+	}
+	sort.Slice(snap.Pending, func(a, b int) bool { return snap.Pending[a] < snap.Pending[b] })
+
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(j.dir, snapshotFileName)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	return j.compact(snap)
+}
+
+// compact 删除所有事件均已被快照覆盖的段文件（当前写入段除外）
+func (j *Journal) compact(snap journalSnapshot) error {
+	minNeeded := snap.Seq + 1
+	if len(snap.Pending) > 0 {
+		minNeeded = snap.Pending[0]
+	}
+
+	keep := j.segments[:0]
+	for i, start := range j.segments {
+		// 下一个段的起始序号不大于 minNeeded，说明本段事件均已不再需要
+		if i+1 < len(j.segments) && j.segments[i+1] <= minNeeded {
+			if err := os.Remove(j.segmentPath(start)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		keep = append(keep, start)
+	}
+	j.segments = keep
+	return nil
+}
+
+// write 写入单条记录，必要时滚动段文件；调用方需持有 j.mu
+func (j *Journal) write(rec journalRecord) error {
+	if j.file == nil || j.fileSize >= j.segmentSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+
+	b := encodeRecord(rec)
+	n, err := j.file.Write(b)
+	j.fileSize += int64(n)
+	if err != nil {
+		return err
+	}
+	if j.syncWrites {
+		return j.file.Sync()
+	}
+	return nil
+}
+
+// rotate 关闭当前段并以下一个待分配序号为名打开新段
+func (j *Journal) rotate() error {
+	if j.file != nil {
+		if err := j.file.Close(); err != nil {
+			return err
+		}
+		j.file = nil
+	}
+
+	start := j.seq + 1
+	f, err := os.OpenFile(j.segmentPath(start), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	j.file = f
+	j.fileSize = info.Size()
+	if n := len(j.segments); n == 0 || j.segments[n-1] != start {
+		j.segments = append(j.segments, start)
+	}
+	return nil
+}
+
+func (j *Journal) segmentPath(start uint64) string {
+	return filepath.Join(j.dir, fmt.Sprintf("%020d%s", start, segmentExt))
+}
+
+// listSegments 列出目录中的段文件起始序号（升序）
+func (j *Journal) listSegments() ([]uint64, error) {
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		start, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, start)
+	}
+	sort.Slice(segments, func(a, b int) bool { return segments[a] < segments[b] })
+	return segments, nil
+}
+
+// readSnapshot 读取快照文件，不存在时返回空快照且 found 为 false
+func (j *Journal) readSnapshot() (snap journalSnapshot, found bool, err error) {
+	b, err := os.ReadFile(filepath.Join(j.dir, snapshotFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return snap, false, nil
+		}
+		return snap, false, err
+	}
+	if err = json.Unmarshal(b, &snap); err != nil {
+		return snap, false, fmt.Errorf("%w: %s: %v", ErrJournalCorrupted, snapshotFileName, err)
+	}
+	return snap, true, nil
+}
+
+var errTornRecord = errors.New("torn record")
+
+// encodeRecord 记录格式：长度(4) | CRC32(4) | kind(1) seq(8) priority(1) ts(8) typeLen(2) type data
+func encodeRecord(rec journalRecord) []byte {
+	bodyLen := recordFixedSize + len(rec.typ) + len(rec.data)
+	b := make([]byte, recordHeaderSize+bodyLen)
+
+	body := b[recordHeaderSize:]
+	body[0] = rec.kind
+	binary.LittleEndian.PutUint64(body[1:], rec.seq)
+	body[9] = byte(rec.priority)
+	var ts int64
+	if !rec.ts.IsZero() {
+		ts = rec.ts.UnixNano()
+	}
+	binary.LittleEndian.PutUint64(body[10:], uint64(ts))
+	binary.LittleEndian.PutUint16(body[18:], uint16(len(rec.typ)))
+	copy(body[recordFixedSize:], rec.typ)
+	copy(body[recordFixedSize+len(rec.typ):], rec.data)
+
+	binary.LittleEndian.PutUint32(b[0:], uint32(bodyLen))
+	binary.LittleEndian.PutUint32(b[4:], crc32.ChecksumIEEE(body))
+	return b
+}
+
+// readSegment 读取段文件中的全部记录，遇到残缺或校验失败的记录时返回 errTornRecord 及有效长度
+func readSegment(path string) ([]journalRecord, int64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var records []journalRecord
+	var off int64
+	for int(off) < len(b) {
+		rest := b[off:]
+		if len(rest) < recordHeaderSize {
+			return records, off, errTornRecord
+		}
+		bodyLen := int(binary.LittleEndian.Uint32(rest[0:]))
+		if bodyLen < recordFixedSize || len(rest)-recordHeaderSize < bodyLen {
+			return records, off, errTornRecord
+		}
+		body := rest[recordHeaderSize : recordHeaderSize+bodyLen]
+		if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(rest[4:]) {
+			return records, off, errTornRecord
+		}
+
+		typLen := int(binary.LittleEndian.Uint16(body[18:]))
+		if recordFixedSize+typLen > bodyLen {
+			return records, off, errTornRecord
+		}
+
+		rec := journalRecord{
+			kind:     body[0],
+			seq:      binary.LittleEndian.Uint64(body[1:]),
+			priority: Priority(body[9]),
+			typ:      string(body[recordFixedSize : recordFixedSize+typLen]),
+			data:     append([]byte(nil), body[recordFixedSize+typLen:]...),
+		}
+		if ts := int64(binary.LittleEndian.Uint64(body[10:])); ts != 0 {
+			rec.ts = time.Unix(0, ts)
+		}
+		records = append(records, rec)
+		off += int64(recordHeaderSize + bodyLen)
+	}
+	return records, off, nil
+}
@@ -0,0 +1,401 @@
+package eventloop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type addCmd struct {
+	N int `json:"n"`
+}
+
+// 累加处理器：状态为累加和，实现 Snapshotter。
+type sumProcessor struct {
+	mu       sync.Mutex
+	sum      int
+	restored bool
+	done     chan struct{}
+}
+
+func (p *sumProcessor) Process(ev Event) Result {
+	p.mu.Lock()
+	p.sum += ev.Data.(addCmd).N
+	p.mu.Unlock()
+	if p.done != nil {
+		p.done <- struct{}{}
+	}
+	return Result{}
+}
+
+func (p *sumProcessor) Sum() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sum
+}
+
+func (p *sumProcessor) Snapshot() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return json.Marshal(p.sum)
+}
+
+func (p *sumProcessor) Restore(state []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.restored = true
+	return json.Unmarshal(state, &p.sum)
+}
+
+func newTestJournal(t *testing.T, dir string, opts ...JournalOption) *Journal {
+	t.Helper()
+	codec := NewJSONCodec()
+	codec.Register("add", addCmd{})
+	j, err := NewJournal(dir, append([]JournalOption{WithJournalCodec(codec)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewJournal failed: %v", err)
+	}
+	return j
+}
+
+func submitAdds(t *testing.T, el *EventLoop, proc *sumProcessor, ns ...int) {
+	t.Helper()
+	for _, n := range ns {
+		if err := el.Submit(NewEvent("add", addCmd{N: n})); err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+	for range ns {
+		select {
+		case <-proc.done:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for processed events")
+		}
+	}
+}
+
+// TestJournalSnapshotRecovery 验证 Stop 时保存快照，重启后从快照恢复且不重复处理事件。
+func TestJournalSnapshotRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	proc := &sumProcessor{done: make(chan struct{}, 10)}
+	el := NewEventLoop(10, proc, false)
+	el.SetJournal(newTestJournal(t, dir, WithSnapshotEvery(2)))
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	submitAdds(t, el, proc, 1, 2, 3)
+	el.Stop()
+
+	proc2 := &sumProcessor{done: make(chan struct{}, 10)}
+	el2 := NewEventLoop(10, proc2, false)
+	el2.SetJournal(newTestJournal(t, dir))
+	if err := el2.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el2.Stop()
+
+	if !proc2.restored || proc2.Sum() != 6 {
+		t.Fatalf("expected restored sum 6, got %d (restored=%v)", proc2.Sum(), proc2.restored)
+	}
+
+	submitAdds(t, el2, proc2, 4)
+	if proc2.Sum() != 10 {
+		t.Fatalf("expected sum 10, got %d", proc2.Sum())
+	}
+}
+
+// TestJournalReplayAfterCrash 验证未正常 Stop（模拟崩溃）时，从最近快照起重放日志事件重建状态。
+func TestJournalReplayAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+
+	proc := &sumProcessor{done: make(chan struct{}, 10)}
+	el := NewEventLoop(10, proc, false)
+	j := newTestJournal(t, dir, WithSnapshotEvery(2))
+	el.SetJournal(j)
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	submitAdds(t, el, proc, 1, 2, 3)
+
+	// 模拟崩溃：停止循环但不经过 Stop 的快照流程，并在日志尾部写入残缺记录
+	el.cancel()
+	el.wg.Wait()
+	_ = j.Close()
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if len(segments) == 0 {
+		t.Fatal("expected journal segments")
+	}
+	f, err := os.OpenFile(segments[len(segments)-1], os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("open segment failed: %v", err)
+	}
+	_, _ = f.Write([]byte{0x10, 0x00})
+	_ = f.Close()
+
+	proc2 := &sumProcessor{}
+	el2 := NewEventLoop(10, proc2, false)
+	el2.SetJournal(newTestJournal(t, dir))
+	if err := el2.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el2.Stop()
+
+	if !proc2.restored || proc2.Sum() != 6 {
+		t.Fatalf("expected replayed sum 6, got %d (restored=%v)", proc2.Sum(), proc2.restored)
+	}
+}
+
+// TestJournalDiscardOnQueueFull 验证入队失败的事件不会被重放。
+func TestJournalDiscardOnQueueFull(t *testing.T) {
+	dir := t.TempDir()
+
+	j := newTestJournal(t, dir)
+	el := NewEventLoop(1, nil, false)
+	el.SetJournal(j)
+	el.running.Store(true) // 不启动循环，使队列保持占满
+
+	if err := el.Submit(NewEvent("add", addCmd{N: 1})); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if err := el.Submit(NewEvent("add", addCmd{N: 2})); err != ErrLowQueueFull {
+		t.Fatalf("expected ErrLowQueueFull, got %v", err)
+	}
+	_ = j.Close()
+
+	proc := &sumProcessor{}
+	el2 := NewEventLoop(10, proc, false)
+	el2.SetJournal(newTestJournal(t, dir))
+	if err := el2.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el2.Stop()
+
+	if proc.Sum() != 1 {
+		t.Fatalf("expected replayed sum 1, got %d", proc.Sum())
+	}
+}
+
+// TestJournalReplaySkipsCanceledEvents 验证调用方已取消、未被处理的事件在崩溃恢复时不会被重放。
+func TestJournalReplaySkipsCanceledEvents(t *testing.T) {
+	dir := t.TempDir()
+
+	proc := &sumProcessor{done: make(chan struct{}, 10)}
+	el := NewEventLoop(10, proc, false)
+	j := newTestJournal(t, dir)
+	el.SetJournal(j)
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// 同一优先级按提交顺序处理，等到前后两个事件处理完成时，已取消的事件也已被跳过
+	if err := el.Submit(NewEvent("add", addCmd{N: 1})); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if err := el.Submit(NewEvent("add", addCmd{N: 10}, WithContext(ctx))); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if err := el.Submit(NewEvent("add", addCmd{N: 2})); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	for range 2 {
+		select {
+		case <-proc.done:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for processed events")
+		}
+	}
+	if proc.Sum() != 3 {
+		t.Fatalf("expected live sum 3, got %d", proc.Sum())
+	}
+
+	// 模拟崩溃：没有任何快照，全部依赖日志重放
+	el.cancel()
+	el.wg.Wait()
+	_ = j.Close()
+
+	proc2 := &sumProcessor{}
+	el2 := NewEventLoop(10, proc2, false)
+	el2.SetJournal(newTestJournal(t, dir))
+	if err := el2.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el2.Stop()
+
+	if proc2.Sum() != proc.Sum() {
+		t.Fatalf("expected replayed sum %d, got %d", proc.Sum(), proc2.Sum())
+	}
+}
+
+// 顺序处理器：记录事件的处理顺序，第一个事件阻塞到 release 关闭，用于让后续事件在队列中排队。
+type orderProcessor struct {
+	mu      sync.Mutex
+	order   []int
+	release chan struct{}
+	done    chan struct{}
+}
+
+func (p *orderProcessor) Process(ev Event) Result {
+	if p.release != nil {
+		<-p.release
+	}
+	p.mu.Lock()
+	p.order = append(p.order, ev.Data.(addCmd).N)
+	p.mu.Unlock()
+	if p.done != nil {
+		p.done <- struct{}{}
+	}
+	return Result{}
+}
+
+func (p *orderProcessor) Order() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int(nil), p.order...)
+}
+
+// TestJournalReplayFollowsProcessingOrder 验证混合优先级时，崩溃恢复按实际处理顺序而不是提交顺序重放。
+func TestJournalReplayFollowsProcessingOrder(t *testing.T) {
+	dir := t.TempDir()
+
+	proc := &orderProcessor{release: make(chan struct{}), done: make(chan struct{}, 10)}
+	el := NewEventLoop(10, proc, false)
+	j := newTestJournal(t, dir)
+	el.SetJournal(j)
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	submit := func(n int, p Priority) {
+		t.Helper()
+		if err := el.Submit(NewEvent("add", addCmd{N: n}, WithPriority(p))); err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+	// 第一个事件阻塞处理期间，按低、中、高的顺序提交，实际处理顺序为高、中、低
+	submit(1, PriorityLow)
+	time.Sleep(20 * time.Millisecond)
+	submit(2, PriorityLow)
+	submit(3, PriorityMedium)
+	submit(4, PriorityHigh)
+	close(proc.release)
+	for range 4 {
+		select {
+		case <-proc.done:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for processed events")
+		}
+	}
+	live := proc.Order()
+	if want := []int{1, 4, 3, 2}; !slices.Equal(live, want) {
+		t.Fatalf("expected live order %v, got %v", want, live)
+	}
+
+	// 模拟崩溃后重放
+	el.cancel()
+	el.wg.Wait()
+	_ = j.Close()
+
+	proc2 := &orderProcessor{}
+	el2 := NewEventLoop(10, proc2, false)
+	el2.SetJournal(newTestJournal(t, dir))
+	if err := el2.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el2.Stop()
+
+	if replayed := proc2.Order(); !slices.Equal(replayed, live) {
+		t.Fatalf("expected replayed order %v, got %v", live, replayed)
+	}
+}
+
+// TestJournalCompactWithoutSnapshotter 验证处理器未实现 Snapshotter 时，Stop 写入检查点并清理已处理的日志段，重启后不重复处理。
+func TestJournalCompactWithoutSnapshotter(t *testing.T) {
+	dir := t.TempDir()
+
+	proc := &orderProcessor{done: make(chan struct{}, 10)}
+	el := NewEventLoop(10, proc, false)
+	el.SetJournal(newTestJournal(t, dir, WithSegmentSize(1)))
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	for n := 1; n <= 5; n++ {
+		if err := el.Submit(NewEvent("add", addCmd{N: n})); err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+	for range 5 {
+		select {
+		case <-proc.done:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for processed events")
+		}
+	}
+	before, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	el.Stop()
+
+	after, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if len(before) < 5 || len(after) != 1 {
+		t.Fatalf("expected segments to be compacted to 1, got %d -> %d", len(before), len(after))
+	}
+
+	proc2 := &orderProcessor{}
+	el2 := NewEventLoop(10, proc2, false)
+	el2.SetJournal(newTestJournal(t, dir))
+	if err := el2.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el2.Stop()
+
+	if replayed := proc2.Order(); len(replayed) != 0 {
+		t.Fatalf("expected no replayed events after checkpoint, got %v", replayed)
+	}
+}
+
+// TestJournalSnapshotMismatch 验证快照与处理器不匹配时 Start 返回错误，而不是从零开始只重放快照之后的事件。
+func TestJournalSnapshotMismatch(t *testing.T) {
+	dir := t.TempDir()
+
+	proc := &sumProcessor{done: make(chan struct{}, 10)}
+	el := NewEventLoop(10, proc, false)
+	el.SetJournal(newTestJournal(t, dir))
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	submitAdds(t, el, proc, 1, 2)
+	el.Stop()
+
+	// 带状态的快照 + 未实现 Snapshotter 的处理器
+	el2 := NewEventLoop(10, &orderProcessor{}, false)
+	el2.SetJournal(newTestJournal(t, dir))
+	if err := el2.Start(); !errors.Is(err, ErrJournalSnapshotMismatch) {
+		t.Fatalf("expected ErrJournalSnapshotMismatch, got %v", err)
+	}
+	if el2.IsRunning() {
+		t.Fatal("expected event loop not running")
+	}
+
+	// 不含状态的检查点 + 实现了 Snapshotter 的处理器
+	dir = t.TempDir()
+	el3 := NewEventLoop(10, &orderProcessor{}, false)
+	el3.SetJournal(newTestJournal(t, dir))
+	if err := el3.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	el3.Stop()
+
+	el4 := NewEventLoop(10, &sumProcessor{}, false)
+	el4.SetJournal(newTestJournal(t, dir))
+	if err := el4.Start(); !errors.Is(err, ErrJournalSnapshotMismatch) {
+		t.Fatalf("expected ErrJournalSnapshotMismatch, got %v", err)
+	}
+}
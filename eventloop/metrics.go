@@ -29,13 +29,23 @@ type MetricsSnapshot struct {
 	ProcessedMedium uint64
 	ProcessedLow    uint64
 
+	CanceledHigh   uint64
+	CanceledMedium uint64
+	CanceledLow    uint64
+
 	CallbackDiscarded uint64
 	InlineTimeout     uint64
+	FrameOverrun      uint64
 
 	// 平均处理耗时（纳秒），0 表示无数据
 	AvgProcessingNsHigh   uint64
 	AvgProcessingNsMedium uint64
 	AvgProcessingNsLow    uint64
+
+	// 平均排队等待耗时（纳秒），0 表示无数据
+	AvgQueueWaitNsHigh   uint64
+	AvgQueueWaitNsMedium uint64
+	AvgQueueWaitNsLow    uint64
 }
 
 // Metrics 定义需要的计数与时长记录操作。
@@ -50,15 +60,39 @@ type Metrics interface {
 	Snapshot() MetricsSnapshot
 }
 
+// CancelMetrics 可选扩展接口：Metrics 实现该接口时，统计处理前因调用方取消或超时而被跳过的事件。
+type CancelMetrics interface {
+	IncCanceled(priority Priority)
+}
+
+// FrameMetrics 可选扩展接口：统计帧驱动模式下单帧处理超出时间预算的次数。
+type FrameMetrics interface {
+	IncFrameOverrun()
+}
+
+// QueueWaitMetrics 可选扩展接口：记录排队等待耗时（开始处理时间 - Event.TS）。
+type QueueWaitMetrics interface {
+	ObserveQueueWait(priority Priority, eventType string, d time.Duration)
+}
+
+// EventTypeMetrics 可选扩展接口：按事件类型记录处理耗时，
+// 在 ObserveProcessingDuration 之外额外调用，按优先级的统计仍由 ObserveProcessingDuration 完成。
+type EventTypeMetrics interface {
+	ObserveEventTypeProcessing(eventType string, d time.Duration)
+}
+
 // NoopMetrics 不做任何统计，适合默认或测试。
 type NoopMetrics struct{}
 
 func (NoopMetrics) IncSubmitted(priority Priority)                               {}
 func (NoopMetrics) IncDropped(priority Priority)                                 {}
 func (NoopMetrics) IncProcessed(priority Priority)                               {}
+func (NoopMetrics) IncCanceled(priority Priority)                                {}
 func (NoopMetrics) IncCallbackDiscarded()                                        {}
 func (NoopMetrics) IncInlineTimeout()                                            {}
+func (NoopMetrics) IncFrameOverrun()                                             {}
 func (NoopMetrics) ObserveProcessingDuration(priority Priority, d time.Duration) {}
+func (NoopMetrics) ObserveQueueWait(Priority, string, time.Duration)             {}
 func (NoopMetrics) Snapshot() MetricsSnapshot                                    { return MetricsSnapshot{Timestamp: time.Now()} }
 
 // SimpleMetrics 基于原子计数，适合内存中统计与测试。
@@ -76,8 +110,13 @@ type SimpleMetrics struct {
 	processedMedium atomic.Uint64
 	processedLow    atomic.Uint64
 
+	canceledHigh   atomic.Uint64
+	canceledMedium atomic.Uint64
+	canceledLow    atomic.Uint64
+
 	callbackDiscarded atomic.Uint64
 	inlineTimeout     atomic.Uint64
+	frameOverrun      atomic.Uint64
 
 	// 记录处理耗时：总纳秒与计数（按优先级分别统计）
 	processNsHigh    atomic.Uint64
@@ -86,6 +125,14 @@ type SimpleMetrics struct {
 	processCntMedium atomic.Uint64
 	processNsLow     atomic.Uint64
 	processCntLow    atomic.Uint64
+
+	// 记录排队等待耗时：总纳秒与计数（按优先级分别统计）
+	waitNsHigh    atomic.Uint64
+	waitCntHigh   atomic.Uint64
+	waitNsMedium  atomic.Uint64
+	waitCntMedium atomic.Uint64
+	waitNsLow     atomic.Uint64
+	waitCntLow    atomic.Uint64
 }
 
 func NewSimpleMetrics() *SimpleMetrics { return &SimpleMetrics{} }
@@ -123,6 +170,17 @@ func (s *SimpleMetrics) IncProcessed(priority Priority) {
 	}
 }
 
+func (s *SimpleMetrics) IncCanceled(priority Priority) {
+	switch priority {
+	case PriorityHigh:
+		s.canceledHigh.Add(1)
+	case PriorityMedium:
+		s.canceledMedium.Add(1)
+	case PriorityLow:
+		s.canceledLow.Add(1)
+	}
+}
+
 func (s *SimpleMetrics) IncCallbackDiscarded() {
 	s.callbackDiscarded.Add(1)
 }
@@ -131,6 +189,10 @@ func (s *SimpleMetrics) IncInlineTimeout() {
 	s.inlineTimeout.Add(1)
 }
 
+func (s *SimpleMetrics) IncFrameOverrun() {
+	s.frameOverrun.Add(1)
+}
+
 func (s *SimpleMetrics) ObserveProcessingDuration(priority Priority, d time.Duration) {
 	nanos := uint64(d.Nanoseconds())
 	switch priority {
@@ -146,20 +208,28 @@ func (s *SimpleMetrics) ObserveProcessingDuration(priority Priority, d time.Dura
 	}
 }
 
+func (s *SimpleMetrics) ObserveQueueWait(priority Priority, _ string, d time.Duration) {
+	nanos := uint64(max(d, 0).Nanoseconds())
+	switch priority {
+	case PriorityHigh:
+		s.waitNsHigh.Add(nanos)
+		s.waitCntHigh.Add(1)
+	case PriorityMedium:
+		s.waitNsMedium.Add(nanos)
+		s.waitCntMedium.Add(1)
+	case PriorityLow:
+		s.waitNsLow.Add(nanos)
+		s.waitCntLow.Add(1)
+	}
+}
+
 func (s *SimpleMetrics) Snapshot() MetricsSnapshot {
 	// 计算平均纳秒（避免除以 0）
-	var avgHigh, avgMed, avgLow uint64
-	cntH := s.processCntHigh.Load()
-	if cntH > 0 {
-		avgHigh = s.processNsHigh.Load() / cntH
-	}
-	cntM := s.processCntMedium.Load()
-	if cntM > 0 {
-		avgMed = s.processNsMedium.Load() / cntM
-	}
-	cntL := s.processCntLow.Load()
-	if cntL > 0 {
-		avgLow = s.processNsLow.Load() / cntL
+	avg := func(ns, cnt *atomic.Uint64) uint64 {
+		if c := cnt.Load(); c > 0 {
+			return ns.Load() / c
+		}
+		return 0
 	}
 
 	return MetricsSnapshot{
@@ -173,10 +243,17 @@ func (s *SimpleMetrics) Snapshot() MetricsSnapshot {
 		ProcessedHigh:         s.processedHigh.Load(),
 		ProcessedMedium:       s.processedMedium.Load(),
 		ProcessedLow:          s.processedLow.Load(),
+		CanceledHigh:          s.canceledHigh.Load(),
+		CanceledMedium:        s.canceledMedium.Load(),
+		CanceledLow:           s.canceledLow.Load(),
 		CallbackDiscarded:     s.callbackDiscarded.Load(),
 		InlineTimeout:         s.inlineTimeout.Load(),
-		AvgProcessingNsHigh:   avgHigh,
-		AvgProcessingNsMedium: avgMed,
-		AvgProcessingNsLow:    avgLow,
+		FrameOverrun:          s.frameOverrun.Load(),
+		AvgProcessingNsHigh:   avg(&s.processNsHigh, &s.processCntHigh),
+		AvgProcessingNsMedium: avg(&s.processNsMedium, &s.processCntMedium),
+		AvgProcessingNsLow:    avg(&s.processNsLow, &s.processCntLow),
+		AvgQueueWaitNsHigh:    avg(&s.waitNsHigh, &s.waitCntHigh),
+		AvgQueueWaitNsMedium:  avg(&s.waitNsMedium, &s.waitCntMedium),
+		AvgQueueWaitNsLow:     avg(&s.waitNsLow, &s.waitCntLow),
 	}
 }
@@ -0,0 +1,142 @@
+package eventloop
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// HistogramMetrics 基于直方图的 Metrics 实现：
+// 计数沿用 SimpleMetrics，另按优先级与事件类型分别记录排队等待与处理耗时的直方图，
+// 并可通过 WriteOpenMetrics 以 OpenMetrics 文本格式导出。
+type HistogramMetrics struct {
+	*SimpleMetrics
+
+	buckets []time.Duration
+
+	waitByPriority    [3]*Histogram // 按优先级的排队等待耗时
+	processByPriority [3]*Histogram // 按优先级的处理耗时
+
+	mu     sync.RWMutex
+	byType map[string]*typeHistograms // 按事件类型的直方图
+}
+
+// typeHistograms 单个事件类型的直方图
+type typeHistograms struct {
+	wait    *Histogram
+	process *Histogram
+}
+
+// NewHistogramMetrics 创建 HistogramMetrics；buckets 为直方图桶上界，为空时使用 DefaultLatencyBuckets
+func NewHistogramMetrics(buckets ...time.Duration) *HistogramMetrics {
+	m := &HistogramMetrics{
+		SimpleMetrics: NewSimpleMetrics(),
+		buckets:       buckets,
+		byType:        make(map[string]*typeHistograms),
+	}
+	for i := range m.waitByPriority {
+		m.waitByPriority[i] = NewHistogram(buckets)
+		m.processByPriority[i] = NewHistogram(buckets)
+	}
+	return m
+}
+
+func (m *HistogramMetrics) ObserveProcessingDuration(priority Priority, d time.Duration) {
+	m.SimpleMetrics.ObserveProcessingDuration(priority, d)
+	if h := m.priorityHistogram(&m.processByPriority, priority); h != nil {
+		h.Observe(d)
+	}
+}
+
+func (m *HistogramMetrics) ObserveEventTypeProcessing(eventType string, d time.Duration) {
+	m.typeHistograms(eventType).process.Observe(d)
+}
+
+func (m *HistogramMetrics) ObserveQueueWait(priority Priority, eventType string, d time.Duration) {
+	m.SimpleMetrics.ObserveQueueWait(priority, eventType, d)
+	if h := m.priorityHistogram(&m.waitByPriority, priority); h != nil {
+		h.Observe(d)
+	}
+	m.typeHistograms(eventType).wait.Observe(d)
+}
+
+// QueueWait 返回指定优先级的排队等待耗时直方图快照
+func (m *HistogramMetrics) QueueWait(priority Priority) HistogramSnapshot {
+	if h := m.priorityHistogram(&m.waitByPriority, priority); h != nil {
+		return h.Snapshot()
+	}
+	return HistogramSnapshot{}
+}
+
+// Processing 返回指定优先级的处理耗时直方图快照
+func (m *HistogramMetrics) Processing(priority Priority) HistogramSnapshot {
+	if h := m.priorityHistogram(&m.processByPriority, priority); h != nil {
+		return h.Snapshot()
+	}
+	return HistogramSnapshot{}
+}
+
+// QueueWaitByType 返回指定事件类型的排队等待耗时直方图快照
+func (m *HistogramMetrics) QueueWaitByType(eventType string) HistogramSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if th, ok := m.byType[eventType]; ok {
+		return th.wait.Snapshot()
+	}
+	return HistogramSnapshot{}
+}
+
+// ProcessingByType 返回指定事件类型的处理耗时直方图快照
+func (m *HistogramMetrics) ProcessingByType(eventType string) HistogramSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if th, ok := m.byType[eventType]; ok {
+		return th.process.Snapshot()
+	}
+	return HistogramSnapshot{}
+}
+
+// EventTypes 返回已观测到的事件类型（升序）
+func (m *HistogramMetrics) EventTypes() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	types := make([]string, 0, len(m.byType))
+	for typ := range m.byType {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (m *HistogramMetrics) priorityHistogram(hs *[3]*Histogram, priority Priority) *Histogram {
+	if priority < PriorityHigh || priority > PriorityLow {
+		return nil
+	}
+	return hs[priority]
+}
+
+// typeHistograms 获取（必要时创建）事件类型对应的直方图
+func (m *HistogramMetrics) typeHistograms(eventType string) *typeHistograms {
+	m.mu.RLock()
+	th, ok := m.byType[eventType]
+	m.mu.RUnlock()
+	if ok {
+		return th
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if th, ok = m.byType[eventType]; ok {
+		return th
+	}
+	th = &typeHistograms{
+		wait:    NewHistogram(m.buckets),
+		process: NewHistogram(m.buckets),
+	}
+	m.byType[eventType] = th
+	return th
+}
@@ -0,0 +1,97 @@
+package eventloop
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHistogramQuantile 验证直方图计数与分位数估算。
+func TestHistogramQuantile(t *testing.T) {
+	h := NewHistogram([]time.Duration{time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond})
+	for i := 0; i < 90; i++ {
+		h.Observe(500 * time.Microsecond)
+	}
+	for i := 0; i < 10; i++ {
+		h.Observe(50 * time.Millisecond)
+	}
+
+	snap := h.Snapshot()
+	if snap.Count != 100 {
+		t.Fatalf("expected count 100, got %d", snap.Count)
+	}
+	if p50 := snap.Quantile(0.5); p50 > time.Millisecond {
+		t.Fatalf("expected p50 <= 1ms, got %v", p50)
+	}
+	if p99 := snap.Quantile(0.99); p99 <= 10*time.Millisecond || p99 > 100*time.Millisecond {
+		t.Fatalf("expected p99 in (10ms, 100ms], got %v", p99)
+	}
+}
+
+// TestHistogramMetricsOpenMetrics 验证事件循环上报的直方图指标与 OpenMetrics 导出格式。
+func TestHistogramMetricsOpenMetrics(t *testing.T) {
+	ch := make(chan string, 10)
+	el := NewEventLoop(10, &testProcessor{ch: ch}, false)
+	metrics := NewHistogramMetrics()
+	el.SetMetrics(metrics)
+	if err := el.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer el.Stop()
+
+	if err := el.Submit(NewEvent("login", "a", WithPriority(PriorityHigh), WithContext(context.Background()))); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if err := el.Submit(NewEvent(`say "hi"`, "b", WithContext(context.Background()))); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for processed events")
+		}
+	}
+
+	// 处理完成后指标在 Process 返回后才上报，稍作等待
+	deadline := time.Now().Add(time.Second)
+	for metrics.Processing(PriorityLow).Count == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for processing histogram")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if c := metrics.QueueWait(PriorityHigh).Count; c != 1 {
+		t.Fatalf("expected 1 high queue wait observation, got %d", c)
+	}
+	if c := metrics.ProcessingByType("login").Count; c != 1 {
+		t.Fatalf("expected 1 login processing observation, got %d", c)
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.WriteOpenMetrics(&buf); err != nil {
+		t.Fatalf("WriteOpenMetrics failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE eventloop_events_submitted counter\n",
+		`eventloop_events_submitted_total{priority="high"} 1`,
+		`eventloop_events_processed_total{priority="low"} 1`,
+		"# TYPE eventloop_queue_wait_seconds histogram\n",
+		`eventloop_queue_wait_seconds_count{priority="high"} 1`,
+		`eventloop_event_processing_seconds_bucket{type="login",le="+Inf"} 1`,
+		`eventloop_event_processing_seconds_count{type="say \"hi\""} 1`,
+		"eventloop_frame_overruns_total 0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Fatalf("expected output to end with # EOF, got:\n%s", out)
+	}
+}
@@ -0,0 +1,125 @@
+package eventloop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenMetricsContentType OpenMetrics 文本格式的 Content-Type
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+const metricsNamespace = "eventloop"
+
+var priorities = [...]Priority{PriorityHigh, PriorityMedium, PriorityLow}
+
+// WriteOpenMetrics 以 OpenMetrics 文本格式写出全部指标（以 "# EOF" 结尾），无需依赖 Prometheus 客户端库
+func (m *HistogramMetrics) WriteOpenMetrics(w io.Writer) error {
+	ow := &openMetricsWriter{w: bufio.NewWriter(w)}
+	snap := m.Snapshot()
+
+	ow.counterByPriority("events_submitted", "Events accepted into the priority queues.",
+		[3]uint64{snap.SubmittedHigh, snap.SubmittedMedium, snap.SubmittedLow})
+	ow.counterByPriority("events_dropped", "Events rejected because the priority queue was full.",
+		[3]uint64{snap.DroppedHigh, snap.DroppedMedium, snap.DroppedLow})
+	ow.counterByPriority("events_processed", "Events handled by the event processor.",
+		[3]uint64{snap.ProcessedHigh, snap.ProcessedMedium, snap.ProcessedLow})
+	ow.counterByPriority("events_canceled", "Events skipped because the caller canceled or timed out before processing.",
+		[3]uint64{snap.CanceledHigh, snap.CanceledMedium, snap.CanceledLow})
+
+	ow.counter("callbacks_discarded", "Async callback results discarded after the delivery timeout.", snap.CallbackDiscarded)
+	ow.counter("inline_callback_timeouts", "Inline callback deliveries that timed out.", snap.InlineTimeout)
+	ow.counter("frame_overruns", "Frames that exceeded the frame budget in frame-driven mode.", snap.FrameOverrun)
+
+	ow.header("queue_wait_seconds", "histogram", "Time events spent queued before processing, by priority.")
+	for _, p := range priorities {
+		ow.histogram("queue_wait_seconds", "priority", p.String(), m.QueueWait(p))
+	}
+	ow.header("processing_seconds", "histogram", "Time spent in the event processor, by priority.")
+	for _, p := range priorities {
+		ow.histogram("processing_seconds", "priority", p.String(), m.Processing(p))
+	}
+
+	types := m.EventTypes()
+	ow.header("event_queue_wait_seconds", "histogram", "Time events spent queued before processing, by event type.")
+	for _, typ := range types {
+		ow.histogram("event_queue_wait_seconds", "type", typ, m.QueueWaitByType(typ))
+	}
+	ow.header("event_processing_seconds", "histogram", "Time spent in the event processor, by event type.")
+	for _, typ := range types {
+		ow.histogram("event_processing_seconds", "type", typ, m.ProcessingByType(typ))
+	}
+
+	ow.printf("# EOF\n")
+	return ow.flush()
+}
+
+// ServeHTTP 以 OpenMetrics 文本格式响应，可直接挂载为 /metrics
+func (m *HistogramMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", OpenMetricsContentType)
+	_ = m.WriteOpenMetrics(w)
+}
+
+// openMetricsWriter 带粘滞错误的 OpenMetrics 文本写出器
+type openMetricsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (ow *openMetricsWriter) printf(format string, args ...any) {
+	if ow.err != nil {
+		return
+	}
+	_, ow.err = fmt.Fprintf(ow.w, format, args...)
+}
+
+func (ow *openMetricsWriter) flush() error {
+	if ow.err != nil {
+		return ow.err
+	}
+	return ow.w.Flush()
+}
+
+func (ow *openMetricsWriter) header(name, typ, help string) {
+	ow.printf("# TYPE %s_%s %s\n", metricsNamespace, name, typ)
+	ow.printf("# HELP %s_%s %s\n", metricsNamespace, name, help)
+}
+
+func (ow *openMetricsWriter) counter(name, help string, v uint64) {
+	ow.header(name, "counter", help)
+	ow.printf("%s_%s_total %d\n", metricsNamespace, name, v)
+}
+
+func (ow *openMetricsWriter) counterByPriority(name, help string, values [3]uint64) {
+	ow.header(name, "counter", help)
+	for i, p := range priorities {
+		ow.printf("%s_%s_total{priority=\"%s\"} %d\n", metricsNamespace, name, p.String(), values[i])
+	}
+}
+
+func (ow *openMetricsWriter) histogram(name, labelName, labelValue string, s HistogramSnapshot) {
+	label := fmt.Sprintf("%s=\"%s\"", labelName, escapeLabelValue(labelValue))
+
+	var cumulative uint64
+	for i, bound := range s.Bounds {
+		cumulative += s.Counts[i]
+		ow.printf("%s_%s_bucket{%s,le=\"%s\"} %d\n", metricsNamespace, name, label, formatSeconds(bound), cumulative)
+	}
+	ow.printf("%s_%s_bucket{%s,le=\"+Inf\"} %d\n", metricsNamespace, name, label, s.Count)
+	ow.printf("%s_%s_sum{%s} %s\n", metricsNamespace, name, label, formatSeconds(s.Sum))
+	ow.printf("%s_%s_count{%s} %d\n", metricsNamespace, name, label, s.Count)
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
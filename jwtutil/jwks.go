@@ -0,0 +1,366 @@
+package jwtutil
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrKeyNotFound    = errors.New("key not found")
+	ErrUnsupportedKey = errors.New("unsupported key type")
+	ErrNoKeyLoader    = errors.New("key set has no loader")
+)
+
+// Key 验证密钥
+type Key struct {
+	ID        string // kid
+	Algorithm string // JWK 中的 alg，为空时不限制
+	Key       any    // *rsa.PublicKey、*ecdsa.PublicKey、ed25519.PublicKey 或 HMAC 密钥 []byte
+}
+
+// KeyLoader 读取 JWKS 文档，如读取文件或请求身份提供方的 jwks_uri
+type KeyLoader func() ([]byte, error)
+
+// KeySet 按 kid 管理验证密钥，可从 JWKS 文档加载并刷新，用于签名密钥轮换
+type KeySet struct {
+	mu     sync.RWMutex
+	keys   map[string]*Key
+	loader KeyLoader
+
+	refreshMu   sync.Mutex // 串行化加载，并发的刷新请求只会触发一次加载
+	lastAttempt time.Time  // 最近一次加载的开始时间，无论成功与否
+}
+
+// NewKeySet 创建空的 KeySet，可通过 Add 手动添加密钥
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]*Key)}
+}
+
+// NewKeySetFromLoader 使用 loader 加载 JWKS 文档，之后调用 Refresh 会重新加载
+func NewKeySetFromLoader(loader KeyLoader) (*KeySet, error) {
+	s := NewKeySet()
+	s.loader = loader
+	if err := s.Refresh(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewKeySetFromFile 从 JWKS 文件加载，Refresh 时重新读取文件
+func NewKeySetFromFile(path string) (*KeySet, error) {
+	return NewKeySetFromLoader(func() ([]byte, error) {
+		return os.ReadFile(path)
+	})
+}
+
+// ParseJWKS 解析 JWKS 文档
+func ParseJWKS(data []byte) (*KeySet, error) {
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	s := NewKeySet()
+	s.keys = keys
+	return s, nil
+}
+
+// Refresh 重新加载 JWKS 文档并整体替换密钥；加载失败时保留原有密钥
+func (s *KeySet) Refresh() error {
+	if s.loader == nil {
+		return ErrNoKeyLoader
+	}
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	return s.load()
+}
+
+// refreshIfStale 距离上次加载超过 minInterval 时刷新，避免未知 kid 的请求频繁触发加载。
+// 加载失败同样计入间隔，身份提供方不可用时也不会被反复请求；
+// 并发调用时只有一个会执行加载，其余等待其完成后直接返回
+func (s *KeySet) refreshIfStale(minInterval time.Duration) error {
+	if s.loader == nil {
+		return nil
+	}
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	if time.Since(s.lastAttempt) < minInterval {
+		return nil
+	}
+	return s.load()
+}
+
+// load 调用 loader 并替换密钥，调用方需持有 refreshMu
+func (s *KeySet) load() error {
+	s.lastAttempt = time.Now()
+	data, err := s.loader()
+	if err != nil {
+		return fmt.Errorf("failed to load jwks: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+	return nil
+}
+
+// Add 添加或替换密钥
+func (s *KeySet) Add(key *Key) error {
+	if key == nil {
+		return errors.New("key is nil")
+	}
+	switch key.Key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, []byte:
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedKey, key.Key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key.ID] = key
+	return nil
+}
+
+// Remove 移除密钥
+func (s *KeySet) Remove(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, kid)
+}
+
+// Lookup 按 kid 查找密钥；kid 为空且只有一个密钥时返回该密钥
+func (s *KeySet) Lookup(kid string) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+}
+
+// KeyIDs 按字母序返回所有 kid
+func (s *KeySet) KeyIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedIDs()
+}
+
+// MarshalJSON 导出公开的 JWKS 文档，HMAC 密钥不会导出
+func (s *KeySet) MarshalJSON() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	doc := jwkSet{Keys: make([]jwk, 0, len(s.keys))}
+	for _, id := range s.sortedIDs() {
+		key := s.keys[id]
+		if _, ok := key.Key.([]byte); ok {
+			continue
+		}
+		k, err := marshalJWK(key)
+		if err != nil {
+			return nil, err
+		}
+		doc.Keys = append(doc.Keys, k)
+	}
+	return json.Marshal(doc)
+}
+
+func (s *KeySet) sortedIDs() []string {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// jwk RFC 7517 JSON Web Key 中用于验证签名的字段
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC / OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// parseJWKS 解析 JWKS 文档，跳过用于加密（use=enc）以及不支持的 kty
+func parseJWKS(data []byte) (map[string]*Key, error) {
+	var doc jwkSet
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+
+	keys := make(map[string]*Key, len(doc.Keys))
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := parseJWK(&k)
+		if errors.Is(err, ErrUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk #%d (kid %q): %w", i, k.Kid, err)
+		}
+		if _, dup := keys[k.Kid]; dup {
+			// 未设置 kid 的密钥无法区分，只保留第一个，不让整个 JWKS 失效
+			if k.Kid == "" {
+				continue
+			}
+			return nil, fmt.Errorf("duplicate kid %q in jwks", k.Kid)
+		}
+		keys[k.Kid] = &Key{ID: k.Kid, Algorithm: k.Alg, Key: pub}
+	}
+	return keys, nil
+}
+
+func parseJWK(k *jwk) (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		curve, ecdhCurve, err := jwkCurve(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid ec coordinate length")
+		}
+		// 借助 crypto/ecdh 校验点在曲线上
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid ec point: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("%w: OKP curve %s", ErrUnsupportedKey, k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key length")
+		}
+		return ed25519.PublicKey(x), nil
+
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, err
+		}
+		if len(secret) == 0 {
+			return nil, errors.New("empty hmac key")
+		}
+		return secret, nil
+
+	default:
+		return nil, fmt.Errorf("%w: kty %q", ErrUnsupportedKey, k.Kty)
+	}
+}
+
+func marshalJWK(key *Key) (jwk, error) {
+	k := jwk{Kid: key.ID, Alg: key.Algorithm, Use: "sig"}
+	switch pub := key.Key.(type) {
+	case *rsa.PublicKey:
+		k.Kty = "RSA"
+		k.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		k.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		k.Kty = "EC"
+		k.Crv = pub.Curve.Params().Name
+		k.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		k.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		k.Kty = "OKP"
+		k.Crv = "Ed25519"
+		k.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return jwk{}, fmt.Errorf("%w: %T", ErrUnsupportedKey, key.Key)
+	}
+	return k, nil
+}
+
+func jwkCurve(crv string) (elliptic.Curve, ecdh.Curve, error) {
+	switch crv {
+	case "P-256":
+		return elliptic.P256(), ecdh.P256(), nil
+	case "P-384":
+		return elliptic.P384(), ecdh.P384(), nil
+	case "P-521":
+		return elliptic.P521(), ecdh.P521(), nil
+	default:
+		return nil, nil, fmt.Errorf("%w: EC curve %s", ErrUnsupportedKey, crv)
+	}
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
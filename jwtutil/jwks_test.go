@@ -0,0 +1,142 @@
+package jwtutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySet_MarshalAndParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	s := NewKeySet()
+	assert.NoError(t, s.Add(&Key{ID: "rsa", Algorithm: "RS256", Key: &rsaKey.PublicKey}))
+	assert.NoError(t, s.Add(&Key{ID: "ec", Key: &ecKey.PublicKey}))
+	assert.NoError(t, s.Add(&Key{ID: "ed", Key: edPub}))
+	assert.NoError(t, s.Add(&Key{ID: "hmac", Key: []byte("secret")}))
+	assert.ErrorIs(t, s.Add(&Key{ID: "bad", Key: "secret"}), ErrUnsupportedKey)
+
+	data, err := json.Marshal(s)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), `"oct"`)
+
+	parsed, err := ParseJWKS(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ec", "ed", "rsa"}, parsed.KeyIDs())
+
+	key, err := parsed.Lookup("rsa")
+	assert.NoError(t, err)
+	assert.Equal(t, "RS256", key.Algorithm)
+	assert.True(t, rsaKey.PublicKey.Equal(key.Key))
+
+	key, err = parsed.Lookup("ec")
+	assert.NoError(t, err)
+	assert.True(t, ecKey.PublicKey.Equal(key.Key))
+
+	key, err = parsed.Lookup("ed")
+	assert.NoError(t, err)
+	assert.True(t, edPub.Equal(key.Key))
+
+	_, err = parsed.Lookup("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestParseJWKS(t *testing.T) {
+	// 跳过加密用途与不支持的密钥类型
+	s, err := ParseJWKS([]byte(`{"keys":[
+		{"kty":"oct","kid":"hs","k":"c2VjcmV0"},
+		{"kty":"oct","kid":"enc","use":"enc","k":"c2VjcmV0"},
+		{"kty":"OKP","kid":"x","crv":"X25519","x":"AAAA"},
+		{"kty":"unknown","kid":"u"}
+	]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hs"}, s.KeyIDs())
+
+	// kid 为空且只有一个密钥时直接使用
+	key, err := s.Lookup("")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret"), key.Key)
+
+	invalid := []string{
+		`not json`,
+		`{"keys":[{"kty":"oct","kid":"a","k":""}]}`,
+		`{"keys":[{"kty":"oct","kid":"a","k":"c2VjcmV0"},{"kty":"oct","kid":"a","k":"c2VjcmV0"}]}`,
+		`{"keys":[{"kty":"RSA","kid":"a","n":"","e":"AQAB"}]}`,
+		`{"keys":[{"kty":"EC","kid":"a","crv":"P-256","x":"AAAA","y":"AAAA"}]}`,
+		`{"keys":[{"kty":"OKP","kid":"a","crv":"Ed25519","x":"AAAA"}]}`,
+	}
+	for _, doc := range invalid {
+		_, err := ParseJWKS([]byte(doc))
+		assert.Error(t, err, doc)
+	}
+}
+
+func TestParseJWKS_KidlessDuplicates(t *testing.T) {
+	// 多个未设置 kid 的密钥只保留第一个，带 kid 的密钥不受影响
+	s, err := ParseJWKS([]byte(`{"keys":[
+		{"kty":"oct","k":"Zmlyc3Q"},
+		{"kty":"oct","kid":"hs","k":"c2VjcmV0"},
+		{"kty":"oct","k":"c2Vjb25k"}
+	]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "hs"}, s.KeyIDs())
+
+	key, err := s.Lookup("")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("first"), key.Key)
+
+	key, err = s.Lookup("hs")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret"), key.Key)
+}
+
+func TestParseJWKS_PointNotOnCurve(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	k, err := marshalJWK(&Key{ID: "ec", Key: &ecKey.PublicKey})
+	assert.NoError(t, err)
+
+	// 交换坐标后的点不在曲线上
+	k.X, k.Y = k.Y, k.X
+	data, err := json.Marshal(jwkSet{Keys: []jwk{k}})
+	assert.NoError(t, err)
+
+	_, err = ParseJWKS(data)
+	assert.Error(t, err)
+}
+
+func TestKeySet_RefreshFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kty":"oct","kid":"v1","k":"c2VjcmV0"}]}`), 0o600))
+
+	s, err := NewKeySetFromFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v1"}, s.KeyIDs())
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kty":"oct","kid":"v2","k":"c2VjcmV0"}]}`), 0o600))
+	assert.NoError(t, s.Refresh())
+	assert.Equal(t, []string{"v2"}, s.KeyIDs())
+
+	// 加载失败时保留原有密钥
+	assert.NoError(t, os.WriteFile(path, []byte(`broken`), 0o600))
+	assert.Error(t, s.Refresh())
+	assert.Equal(t, []string{"v2"}, s.KeyIDs())
+
+	_, err = NewKeySetFromFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	assert.ErrorIs(t, NewKeySet().Refresh(), ErrNoKeyLoader)
+}
@@ -0,0 +1,160 @@
+package jwtutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrAlgorithmNotAllowed = errors.New("signing algorithm not allowed")
+
+// DefaultAllowedAlgorithms 默认允许的非对称签名算法；HMAC 需要通过 WithAllowedAlgorithms 显式开启
+var DefaultAllowedAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// Verifier 使用 KeySet 验证 JWT：按头部 kid 选择密钥，并限制允许的签名算法
+type Verifier struct {
+	keys            *KeySet
+	algorithms      []string
+	parserOptions   []jwt.ParserOption
+	refreshInterval time.Duration
+}
+
+// VerifierOption Verifier 选项
+type VerifierOption func(*Verifier)
+
+// WithAllowedAlgorithms 设置允许的签名算法（如 RS256、ES256、EdDSA、HS256），替换默认列表
+func WithAllowedAlgorithms(algorithms ...string) VerifierOption {
+	return func(v *Verifier) {
+		v.algorithms = algorithms
+	}
+}
+
+// WithParserOptions 追加 jwt 解析选项，如 jwt.WithIssuer、jwt.WithAudience、jwt.WithLeeway
+func WithParserOptions(opts ...jwt.ParserOption) VerifierOption {
+	return func(v *Verifier) {
+		v.parserOptions = append(v.parserOptions, opts...)
+	}
+}
+
+// WithRefreshOnUnknownKID 遇到未知 kid 时刷新 KeySet，两次加载间隔不小于 minInterval（加载失败也计入），
+// 用于身份提供方轮换密钥后立即识别新密钥；KeySet 需要通过 loader 或文件创建
+func WithRefreshOnUnknownKID(minInterval time.Duration) VerifierOption {
+	return func(v *Verifier) {
+		v.refreshInterval = minInterval
+	}
+}
+
+// NewVerifier 创建 Verifier
+func NewVerifier(keys *KeySet, opts ...VerifierOption) (*Verifier, error) {
+	if keys == nil {
+		return nil, fmt.Errorf("key set cannot be nil")
+	}
+
+	v := &Verifier{
+		keys:            keys,
+		algorithms:      DefaultAllowedAlgorithms,
+		refreshInterval: -1,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if len(v.algorithms) == 0 {
+		return nil, fmt.Errorf("allowed algorithms cannot be empty")
+	}
+	for _, alg := range v.algorithms {
+		if jwt.GetSigningMethod(alg) == nil || alg == jwt.SigningMethodNone.Alg() {
+			return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
+		}
+	}
+	v.algorithms = slices.Clone(v.algorithms)
+
+	return v, nil
+}
+
+// Keys 返回使用的 KeySet
+func (v *Verifier) Keys() *KeySet {
+	return v.keys
+}
+
+// Verify 验证 JWT 的签名与声明，返回 claims
+func (v *Verifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.VerifyWithClaims(tokenString, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// VerifyWithClaims 验证 JWT 并将声明解析到 claims 中
+func (v *Verifier) VerifyWithClaims(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	opts := append([]jwt.ParserOption{jwt.WithValidMethods(v.algorithms)}, v.parserOptions...)
+	token, err := jwt.ParseWithClaims(tokenString, claims, v.keyFunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return token, nil
+}
+
+// keyFunc 按 kid 查找密钥，并确认密钥类型与 JWK 声明的 alg 和 token 的签名算法一致
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+	if !slices.Contains(v.algorithms, alg) {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithmNotAllowed, alg)
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, err := v.keys.Lookup(kid)
+	if errors.Is(err, ErrKeyNotFound) && v.refreshInterval >= 0 {
+		// 未刷新时其他请求可能刚完成加载，重新查找一次
+		if refreshErr := v.keys.refreshIfStale(v.refreshInterval); refreshErr != nil {
+			return nil, refreshErr
+		}
+		key, err = v.keys.Lookup(kid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Algorithm != "" && key.Algorithm != alg {
+		return nil, fmt.Errorf("%w: key %q is for %s, token uses %s", ErrAlgorithmNotAllowed, key.ID, key.Algorithm, alg)
+	}
+	if !keyMatchesMethod(key.Key, token.Method) {
+		return nil, fmt.Errorf("%w: key %q (%T) cannot verify %s", ErrAlgorithmNotAllowed, key.ID, key.Key, alg)
+	}
+	return key.Key, nil
+}
+
+// keyMatchesMethod 防止算法混淆，如用 RSA 公钥作为 HMAC 密钥
+func keyMatchesMethod(key any, method jwt.SigningMethod) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		pub, ok := key.(*ecdsa.PublicKey)
+		return ok && pub.Curve.Params().BitSize == method.(*jwt.SigningMethodECDSA).CurveBits
+	case *jwt.SigningMethodEd25519:
+		_, ok := key.(ed25519.PublicKey)
+		return ok
+	case *jwt.SigningMethodHMAC:
+		_, ok := key.([]byte)
+		return ok
+	default:
+		return false
+	}
+}
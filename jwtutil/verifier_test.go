@@ -0,0 +1,249 @@
+package jwtutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	secret := []byte("hmac-secret")
+
+	s := NewKeySet()
+	assert.NoError(t, s.Add(&Key{ID: "rsa", Key: &rsaKey.PublicKey}))
+	assert.NoError(t, s.Add(&Key{ID: "ec", Key: &ecKey.PublicKey}))
+	assert.NoError(t, s.Add(&Key{ID: "ed", Key: edPub}))
+	assert.NoError(t, s.Add(&Key{ID: "hs", Key: secret}))
+
+	v, err := NewVerifier(s, WithAllowedAlgorithms("RS256", "PS256", "ES256", "EdDSA", "HS256"))
+	assert.NoError(t, err)
+
+	claims := jwt.MapClaims{"sub": "user", "exp": time.Now().Add(time.Hour).Unix()}
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		kid    string
+		key    crypto.PrivateKey
+	}{
+		{"RS256", jwt.SigningMethodRS256, "rsa", rsaKey},
+		{"PS256", jwt.SigningMethodPS256, "rsa", rsaKey},
+		{"ES256", jwt.SigningMethodES256, "ec", ecKey},
+		{"EdDSA", jwt.SigningMethodEdDSA, "ed", edPriv},
+		{"HS256", jwt.SigningMethodHS256, "hs", secret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Verify(signTestToken(t, tt.method, tt.kid, tt.key, claims))
+			assert.NoError(t, err)
+			assert.Equal(t, "user", got["sub"])
+		})
+	}
+
+	// 未知 kid
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodRS256, "other", rsaKey, claims))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// kid 指向其他类型的密钥
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodRS256, "ec", rsaKey, claims))
+	assert.ErrorIs(t, err, ErrAlgorithmNotAllowed)
+
+	// 已过期
+	expired := jwt.MapClaims{"sub": "user", "exp": time.Now().Add(-time.Hour).Unix()}
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodES256, "ec", ecKey, expired))
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestVerifier_AlgorithmAllowList(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	s := NewKeySet()
+	assert.NoError(t, s.Add(&Key{ID: "rsa", Algorithm: "RS256", Key: &rsaKey.PublicKey}))
+	assert.NoError(t, s.Add(&Key{ID: "hs", Key: []byte("secret")}))
+
+	v, err := NewVerifier(s)
+	assert.NoError(t, err)
+
+	claims := jwt.MapClaims{"sub": "user"}
+
+	// 默认不允许 HMAC
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodHS256, "hs", []byte("secret"), claims))
+	assert.Error(t, err)
+
+	// 密钥声明了 alg 时只能用于该算法
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodRS512, "rsa", rsaKey, claims))
+	assert.ErrorIs(t, err, ErrAlgorithmNotAllowed)
+
+	// 用 RSA 公钥作为 HMAC 密钥伪造的 token
+	pubJWK, err := json.Marshal(s)
+	assert.NoError(t, err)
+	forged := signTestToken(t, jwt.SigningMethodHS256, "rsa", pubJWK, claims)
+	v, err = NewVerifier(s, WithAllowedAlgorithms("RS256", "HS256"))
+	assert.NoError(t, err)
+	_, err = v.Verify(forged)
+	assert.ErrorIs(t, err, ErrAlgorithmNotAllowed)
+
+	_, err = NewVerifier(s, WithAllowedAlgorithms())
+	assert.Error(t, err)
+	_, err = NewVerifier(s, WithAllowedAlgorithms("none"))
+	assert.Error(t, err)
+	_, err = NewVerifier(s, WithAllowedAlgorithms("XS256"))
+	assert.Error(t, err)
+	_, err = NewVerifier(nil)
+	assert.Error(t, err)
+}
+
+func TestVerifier_ParserOptions(t *testing.T) {
+	secret := []byte("secret")
+	s := NewKeySet()
+	assert.NoError(t, s.Add(&Key{ID: "hs", Key: secret}))
+
+	v, err := NewVerifier(s,
+		WithAllowedAlgorithms("HS256"),
+		WithParserOptions(jwt.WithIssuer("https://idp.example.com"), jwt.WithAudience("api")),
+	)
+	assert.NoError(t, err)
+
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodHS256, "hs", secret,
+		jwt.MapClaims{"iss": "https://idp.example.com", "aud": "api"}))
+	assert.NoError(t, err)
+
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodHS256, "hs", secret,
+		jwt.MapClaims{"iss": "https://evil.example.com", "aud": "api"}))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+}
+
+func TestVerifier_KeyRotation(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	published := NewKeySet()
+	assert.NoError(t, published.Add(&Key{ID: "2024", Key: &oldKey.PublicKey}))
+
+	loads := 0
+	s, err := NewKeySetFromLoader(func() ([]byte, error) {
+		loads++
+		return json.Marshal(published)
+	})
+	assert.NoError(t, err)
+
+	v, err := NewVerifier(s, WithRefreshOnUnknownKID(0))
+	assert.NoError(t, err)
+
+	claims := jwt.MapClaims{"sub": "user"}
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodES256, "2024", oldKey, claims))
+	assert.NoError(t, err)
+
+	// 身份提供方发布新密钥后，遇到未知 kid 自动刷新
+	assert.NoError(t, published.Add(&Key{ID: "2025", Key: &newKey.PublicKey}))
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodES256, "2025", newKey, claims))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, loads)
+
+	// 旧密钥下线后不再接受
+	published.Remove("2024")
+	assert.NoError(t, s.Refresh())
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodES256, "2024", oldKey, claims))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// 刷新间隔内不重复加载
+	v, err = NewVerifier(s, WithRefreshOnUnknownKID(time.Hour))
+	assert.NoError(t, err)
+	before := loads
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodES256, "unknown", newKey, claims))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, before, loads)
+}
+
+func TestVerifier_RefreshThrottle(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	published := NewKeySet()
+	assert.NoError(t, published.Add(&Key{ID: "2024", Key: &key.PublicKey}))
+	doc, err := json.Marshal(published)
+	assert.NoError(t, err)
+
+	var loads atomic.Int32
+	var failing atomic.Bool
+	s, err := NewKeySetFromLoader(func() ([]byte, error) {
+		loads.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		if failing.Load() {
+			return nil, errors.New("idp unavailable")
+		}
+		return doc, nil
+	})
+	assert.NoError(t, err)
+
+	v, err := NewVerifier(s, WithRefreshOnUnknownKID(time.Hour))
+	assert.NoError(t, err)
+	claims := jwt.MapClaims{"sub": "user"}
+
+	verifyConcurrently := func(n int) {
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := v.Verify(signTestToken(t, jwt.SigningMethodES256, fmt.Sprintf("random-%d", i), key, claims))
+				assert.Error(t, err)
+			}()
+		}
+		wg.Wait()
+	}
+
+	// 并发的未知 kid 只触发一次加载
+	s.lastAttempt = time.Time{}
+	loads.Store(0)
+	verifyConcurrently(20)
+	assert.Equal(t, int32(1), loads.Load())
+
+	// 加载失败同样计入间隔，不会反复请求身份提供方
+	failing.Store(true)
+	s.lastAttempt = time.Time{}
+	loads.Store(0)
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodES256, "random", key, claims))
+	assert.ErrorContains(t, err, "idp unavailable")
+	for i := range 20 {
+		_, err = v.Verify(signTestToken(t, jwt.SigningMethodES256, fmt.Sprintf("random-%d", i), key, claims))
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	}
+	verifyConcurrently(20)
+	assert.Equal(t, int32(1), loads.Load())
+
+	// 已知 kid 不受影响
+	_, err = v.Verify(signTestToken(t, jwt.SigningMethodES256, "2024", key, claims))
+	assert.NoError(t, err)
+}
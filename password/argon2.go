@@ -3,10 +3,9 @@ package password
 import (
 	"crypto/rand"
 	"crypto/subtle"
-	"encoding/base64"
 	"errors"
-	"fmt"
-	"strings"
+	"math"
+	"strconv"
 
 	"golang.org/x/crypto/argon2"
 )
@@ -50,62 +49,85 @@ func (a *Argon2Crypto) Encrypt(password string) (string, error) {
 		a.KeyLength,
 	)
 
-	// 格式化输出（兼容标准格式）
-	return fmt.Sprintf(
-		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
-		argon2.Version,
-		a.Memory,
-		a.Iterations,
-		a.Parallelism,
-		base64.RawStdEncoding.EncodeToString(salt),
-		base64.RawStdEncoding.EncodeToString(hash),
-	), nil
+	// 输出 PHC 字符串格式
+	return (&PHC{
+		ID:      "argon2id",
+		Version: argon2.Version,
+		Params: []PHCParam{
+			{Name: "m", Value: strconv.FormatUint(uint64(a.Memory), 10)},
+			{Name: "t", Value: strconv.FormatUint(uint64(a.Iterations), 10)},
+			{Name: "p", Value: strconv.FormatUint(uint64(a.Parallelism), 10)},
+		},
+		Salt: salt,
+		Hash: hash,
+	}).String(), nil
 }
 
 // Verify 验证密码
 func (a *Argon2Crypto) Verify(password, encrypted string) (bool, error) {
-	// 解析哈希字符串
-	parts := strings.Split(encrypted, "$")
-	if len(parts) != 6 {
-		return false, errors.New("无效的 Argon2 哈希格式")
-	}
-
-	// 解析参数
-	var version int
-	var memory, iterations uint32
-	var parallelism uint8
-	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
-	if err != nil || version != argon2.Version {
-		return false, errors.New("不支持的 Argon2 版本")
-	}
-
-	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism)
-	if err != nil {
-		return false, errors.New("无效的 Argon2 参数")
-	}
-
-	// 解码盐值和哈希
-	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
-	if err != nil {
-		return false, err
-	}
-
-	decodedHash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	h, err := parseArgon2(encrypted)
 	if err != nil {
 		return false, err
 	}
-	keyLength := uint32(len(decodedHash))
 
 	// 使用相同参数生成新哈希
 	newHash := argon2.IDKey(
 		[]byte(password),
-		salt,
-		iterations,
-		memory,
-		parallelism,
-		keyLength,
+		h.salt,
+		h.iterations,
+		h.memory,
+		h.parallelism,
+		uint32(len(h.hash)),
 	)
 
 	// 安全比较
-	return subtle.ConstantTimeCompare(newHash, decodedHash) == 1, nil
+	return subtle.ConstantTimeCompare(newHash, h.hash) == 1, nil
+}
+
+// NeedsRehash 判断哈希是否低于当前参数，需要在登录成功后重新哈希
+func (a *Argon2Crypto) NeedsRehash(encrypted string) bool {
+	h, err := parseArgon2(encrypted)
+	if err != nil {
+		return true
+	}
+	return h.memory < a.Memory ||
+		h.iterations < a.Iterations ||
+		h.parallelism < a.Parallelism ||
+		uint32(len(h.salt)) < a.SaltLength ||
+		uint32(len(h.hash)) < a.KeyLength
+}
+
+type argon2Hash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	hash        []byte
+}
+
+// parseArgon2 解析 $argon2id$v=19$m=..,t=..,p=..$salt$hash
+func parseArgon2(encrypted string) (*argon2Hash, error) {
+	p, err := ParsePHC(encrypted)
+	if err != nil || p.ID != "argon2id" || len(p.Hash) == 0 {
+		return nil, errors.New("无效的 Argon2 哈希格式")
+	}
+	if p.Version != argon2.Version {
+		return nil, errors.New("不支持的 Argon2 版本")
+	}
+
+	m, errM := p.IntParam("m")
+	t, errT := p.IntParam("t")
+	par, errP := p.IntParam("p")
+	if errM != nil || errT != nil || errP != nil ||
+		uint64(m) > math.MaxUint32 || t == 0 || uint64(t) > math.MaxUint32 || par == 0 || par > math.MaxUint8 {
+		return nil, errors.New("无效的 Argon2 参数")
+	}
+
+	return &argon2Hash{
+		memory:      uint32(m),
+		iterations:  uint32(t),
+		parallelism: uint8(par),
+		salt:        p.Salt,
+		hash:        p.Hash,
+	}, nil
 }
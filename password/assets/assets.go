@@ -0,0 +1,8 @@
+package assets
+
+import _ "embed"
+
+// CommonPasswords 常见弱密码列表，每行一个，按出现频率从高到低排列
+//
+//go:embed common_passwords.txt
+var CommonPasswords []byte
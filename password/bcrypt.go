@@ -41,3 +41,12 @@ func (b *BCryptCrypto) Verify(password, encrypted string) (bool, error) {
 	}
 	return true, nil
 }
+
+// NeedsRehash 判断哈希是否不是 bcrypt 或 cost 低于当前设置
+func (b *BCryptCrypto) NeedsRehash(encrypted string) bool {
+	cost, err := bcrypt.Cost([]byte(encrypted))
+	if err != nil {
+		return true
+	}
+	return cost < b.cost
+}
@@ -0,0 +1,68 @@
+package password
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptSHA256Prefix 与 Django BCryptSHA256PasswordHasher 的格式一致
+const bcryptSHA256Prefix = "bcrypt_sha256$"
+
+// BCryptSHA256Crypto 先对密码做 SHA-256 再交给 bcrypt，
+// 绕过 bcrypt 只使用前 72 字节的限制；输出格式为 bcrypt_sha256$<bcrypt 哈希>，可与 Django 互通
+type BCryptSHA256Crypto struct {
+	cost int
+}
+
+// NewBCryptSHA256Crypto 创建 bcrypt-sha256 加密器，cost 的取值与 NewBCryptCrypto 相同
+func NewBCryptSHA256Crypto(cost ...int) *BCryptSHA256Crypto {
+	return &BCryptSHA256Crypto{cost: NewBCryptCrypto(cost...).cost}
+}
+
+// Encrypt 实现密码加密
+func (b *BCryptSHA256Crypto) Encrypt(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(bcryptSHA256PreHash(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+	return bcryptSHA256Prefix + string(hash), nil
+}
+
+// Verify 验证密码
+func (b *BCryptSHA256Crypto) Verify(password, encrypted string) (bool, error) {
+	hash, ok := strings.CutPrefix(encrypted, bcryptSHA256Prefix)
+	if !ok {
+		return false, errors.New("无效的 bcrypt-sha256 哈希格式")
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(hash), bcryptSHA256PreHash(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// NeedsRehash 判断哈希是否不是 bcrypt-sha256 或 cost 低于当前设置
+func (b *BCryptSHA256Crypto) NeedsRehash(encrypted string) bool {
+	hash, ok := strings.CutPrefix(encrypted, bcryptSHA256Prefix)
+	if !ok {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true
+	}
+	return cost < b.cost
+}
+
+// bcryptSHA256PreHash 预哈希为 64 个十六进制字符，不超过 bcrypt 的 72 字节限制
+func bcryptSHA256PreHash(password string) []byte {
+	sum := sha256.Sum256([]byte(password))
+	return []byte(hex.EncodeToString(sum[:]))
+}
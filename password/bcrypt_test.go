@@ -1,6 +1,7 @@
 package password
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -39,3 +40,37 @@ func TestBCryptCrypto_EncryptAndVerify(t *testing.T) {
 		t.Fatal("验证通过，但密码不应匹配")
 	}
 }
+
+func TestBCryptSHA256Crypto_LongPassword(t *testing.T) {
+	crypto := NewBCryptSHA256Crypto(4)
+
+	// bcrypt 只使用前 72 字节，预哈希后超出部分同样参与计算
+	password := strings.Repeat("a", 80)
+	encrypted, err := crypto.Encrypt(password)
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if !strings.HasPrefix(encrypted, "bcrypt_sha256$$2a$04$") {
+		t.Fatalf("格式错误: %s", encrypted)
+	}
+
+	isValid, err := Verify(password, encrypted)
+	if err != nil || !isValid {
+		t.Fatalf("验证失败: %v", err)
+	}
+
+	isValid, err = Verify(strings.Repeat("a", 72)+"bbbbbbbb", encrypted)
+	if err != nil {
+		t.Fatalf("验证失败: %v", err)
+	}
+	if isValid {
+		t.Fatal("前 72 字节相同的不同密码不应匹配")
+	}
+
+	if crypto.NeedsRehash(encrypted) || !NewBCryptSHA256Crypto(5).NeedsRehash(encrypted) {
+		t.Fatal("cost 判断错误")
+	}
+	if !crypto.NeedsRehash("$2a$04$abcdefghijklmnopqrstuu5Lr2M7ZH8N3eXJ7sGE1T1zY2rQvDq6") {
+		t.Fatal("普通 bcrypt 应需要重新哈希")
+	}
+}
@@ -0,0 +1,66 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BreachChecker 检查密码是否出现在泄露数据中
+type BreachChecker interface {
+	// BreachCount 返回密码在泄露数据中出现的次数，0 表示未发现
+	BreachCount(password string) (int, error)
+}
+
+// RangeBreachChecker 基于本地 k-匿名 SHA-1 前缀文件的泄露检查，不需要访问网络。
+//
+// 文件布局与 Pwned Passwords 的 range 接口一致：每个 5 位十六进制前缀一个文件 <PREFIX>.txt，
+// 每行为 <35 位 SHA-1 后缀>:<次数>，可直接使用官方下载工具导出的数据
+type RangeBreachChecker struct {
+	fsys fs.FS
+}
+
+// NewRangeBreachChecker 使用 fsys 中的前缀文件创建泄露检查器
+func NewRangeBreachChecker(fsys fs.FS) *RangeBreachChecker {
+	return &RangeBreachChecker{fsys: fsys}
+}
+
+// NewRangeBreachCheckerDir 使用目录 dir 中的前缀文件创建泄露检查器
+func NewRangeBreachCheckerDir(dir string) *RangeBreachChecker {
+	return NewRangeBreachChecker(os.DirFS(dir))
+}
+
+// BreachCount 计算密码的 SHA-1，只读取对应前缀的文件查找后缀
+func (c *RangeBreachChecker) BreachCount(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	f, err := c.fsys.Open(prefix + ".txt")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hashSuffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(hashSuffix, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+	return 0, scanner.Err()
+}
@@ -0,0 +1,40 @@
+package password
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestRangeBreachChecker(t *testing.T) {
+	// SHA1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	fsys := fstest.MapFS{
+		"5BAA6.txt": &fstest.MapFile{Data: []byte(
+			"003D68EB55068C33ACE09247EE4C639306B:3\r\n" +
+				"1E4C9B93F3F0682250B6CF8331B7EE68FD8:9659365\r\n",
+		)},
+	}
+	checker := NewRangeBreachChecker(fsys)
+
+	count, err := checker.BreachCount("password")
+	if err != nil {
+		t.Fatalf("检查失败: %v", err)
+	}
+	if count != 9659365 {
+		t.Fatalf("泄露次数错误: %d", count)
+	}
+
+	// 前缀文件不存在
+	count, err = checker.BreachCount("xK9#mQ2$vL7!")
+	if err != nil || count != 0 {
+		t.Fatalf("未泄露的密码结果错误: %d, %v", count, err)
+	}
+}
+
+func TestRangeBreachChecker_Malformed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"5BAA6.txt": &fstest.MapFile{Data: []byte("1E4C9B93F3F0682250B6CF8331B7EE68FD8:many\n")},
+	}
+	if _, err := NewRangeBreachChecker(fsys).BreachCount("password"); err == nil {
+		t.Fatal("次数格式错误时应返回错误")
+	}
+}
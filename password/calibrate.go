@@ -0,0 +1,106 @@
+package password
+
+import (
+	"crypto/sha256"
+	"errors"
+	"runtime"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	calibrateMinArgon2Memory  = 8 * 1024 // 8MB，低于此值不再降低内存
+	calibrateMaxParallelism   = 4
+	calibrateMinPBKDF2Rounds  = 1000
+	calibratePBKDF2ProbeFloor = 20 * time.Millisecond // 探测耗时低于此值时增加迭代次数重新测量，减少计时误差
+)
+
+var errCalibrateTarget = errors.New("目标耗时必须大于 0")
+
+// CalibrationResult 基准测试得到的参数
+type CalibrationResult struct {
+	Argon2 *Argon2Crypto
+	PBKDF2 *PBKDF2Crypto
+}
+
+// Calibrate 在当前机器上测量，分别给出单次哈希耗时接近 target 的 Argon2 与 PBKDF2-SHA256 参数；
+// Argon2 内存上限使用 NewArgon2Crypto 的默认值
+func Calibrate(target time.Duration) (*CalibrationResult, error) {
+	a, err := CalibrateArgon2(target, 0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := CalibratePBKDF2(target, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CalibrationResult{Argon2: a, PBKDF2: p}, nil
+}
+
+// CalibrateArgon2 按 RFC 9106 的建议优先使用内存：从 maxMemory（KB，0 表示默认值）开始，
+// 单次迭代已超过 target 时减半内存，否则增加迭代次数直到接近 target；并行度取 CPU 核数，最多 4
+func CalibrateArgon2(target time.Duration, maxMemory uint32) (*Argon2Crypto, error) {
+	if target <= 0 {
+		return nil, errCalibrateTarget
+	}
+
+	a := NewArgon2Crypto()
+	if maxMemory > 0 {
+		a.Memory = max(maxMemory, calibrateMinArgon2Memory)
+	}
+	a.Parallelism = uint8(min(runtime.NumCPU(), calibrateMaxParallelism))
+	a.Iterations = 1
+
+	elapsed := measureArgon2(a)
+	for elapsed > target && a.Memory/2 >= calibrateMinArgon2Memory {
+		a.Memory /= 2
+		elapsed = measureArgon2(a)
+	}
+	if elapsed < target {
+		// 耗时与迭代次数近似成正比
+		a.Iterations = max(uint32(target/max(elapsed, 1)), 1)
+	}
+	return a, nil
+}
+
+// CalibratePBKDF2 在 base（nil 表示 NewPBKDF2Crypto）的基础上计算耗时接近 target 的迭代次数，
+// 结果按 1000 取整，不低于 1000
+func CalibratePBKDF2(target time.Duration, base *PBKDF2Crypto) (*PBKDF2Crypto, error) {
+	if target <= 0 {
+		return nil, errCalibrateTarget
+	}
+	if base == nil {
+		base = NewPBKDF2Crypto()
+	}
+	p := *base
+
+	probe := 10000
+	elapsed := measurePBKDF2(&p, probe)
+	for elapsed < calibratePBKDF2ProbeFloor && probe < 1<<24 {
+		probe *= 4
+		elapsed = measurePBKDF2(&p, probe)
+	}
+
+	iterations := int(float64(probe) * float64(target) / float64(max(elapsed, 1)))
+	p.Iterations = max((iterations+500)/1000*1000, calibrateMinPBKDF2Rounds)
+	return &p, nil
+}
+
+func measureArgon2(a *Argon2Crypto) time.Duration {
+	salt := make([]byte, a.SaltLength)
+	start := time.Now()
+	argon2.IDKey([]byte("calibrate"), salt, a.Iterations, a.Memory, a.Parallelism, a.KeyLength)
+	return time.Since(start)
+}
+
+func measurePBKDF2(p *PBKDF2Crypto, iterations int) time.Duration {
+	hashFunc := p.Hash
+	if hashFunc == nil {
+		hashFunc = sha256.New
+	}
+	salt := make([]byte, 16)
+	start := time.Now()
+	pbkdf2Key([]byte("calibrate"), salt, iterations, p.KeyLength, hashFunc)
+	return time.Since(start)
+}
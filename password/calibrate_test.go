@@ -0,0 +1,78 @@
+package password
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalibrateArgon2(t *testing.T) {
+	a, err := CalibrateArgon2(50*time.Millisecond, 16*1024)
+	if err != nil {
+		t.Fatalf("校准失败: %v", err)
+	}
+	t.Logf("m=%d t=%d p=%d", a.Memory, a.Iterations, a.Parallelism)
+
+	if a.Memory < calibrateMinArgon2Memory || a.Memory > 16*1024 {
+		t.Fatalf("内存超出范围: %d", a.Memory)
+	}
+	if a.Iterations < 1 || a.Parallelism < 1 || a.Parallelism > calibrateMaxParallelism {
+		t.Fatalf("参数错误: t=%d p=%d", a.Iterations, a.Parallelism)
+	}
+
+	encrypted, err := a.Encrypt("securepassword")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if ok, err := Verify("securepassword", encrypted); err != nil || !ok {
+		t.Fatalf("验证失败: %v", err)
+	}
+}
+
+func TestCalibratePBKDF2(t *testing.T) {
+	p, err := CalibratePBKDF2(20*time.Millisecond, NewPBKDF2WithSHA512())
+	if err != nil {
+		t.Fatalf("校准失败: %v", err)
+	}
+	t.Logf("i=%d", p.Iterations)
+
+	if p.Iterations < calibrateMinPBKDF2Rounds || p.Iterations%1000 != 0 {
+		t.Fatalf("迭代次数错误: %d", p.Iterations)
+	}
+	if p.HashName != "sha512" || p.KeyLength != 64 {
+		t.Fatal("应保留 base 的哈希函数与密钥长度")
+	}
+}
+
+func TestCalibrate_InvalidTarget(t *testing.T) {
+	if _, err := Calibrate(0); err == nil {
+		t.Fatal("目标耗时为 0 时应返回错误")
+	}
+}
+
+func BenchmarkArgon2Crypto_Encrypt(b *testing.B) {
+	crypto := NewArgon2Crypto()
+	for b.Loop() {
+		_, _ = crypto.Encrypt("securepassword")
+	}
+}
+
+func BenchmarkPBKDF2Crypto_Encrypt(b *testing.B) {
+	crypto := NewPBKDF2Crypto()
+	for b.Loop() {
+		_, _ = crypto.Encrypt("securepassword")
+	}
+}
+
+func BenchmarkScryptCrypto_Encrypt(b *testing.B) {
+	crypto := NewScryptCrypto()
+	for b.Loop() {
+		_, _ = crypto.Encrypt("securepassword")
+	}
+}
+
+func BenchmarkBCryptCrypto_Encrypt(b *testing.B) {
+	crypto := NewBCryptCrypto()
+	for b.Loop() {
+		_, _ = crypto.Encrypt("securepassword")
+	}
+}
@@ -0,0 +1,39 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/tx7do/go-utils/password/assets"
+)
+
+var (
+	commonOnce  sync.Once
+	commonRanks map[string]int
+)
+
+// loadCommonPasswords 加载内置的常见密码列表，值为从 1 开始的排名
+func loadCommonPasswords() map[string]int {
+	commonOnce.Do(func() {
+		commonRanks = make(map[string]int)
+		scanner := bufio.NewScanner(bytes.NewReader(assets.CommonPasswords))
+		for scanner.Scan() {
+			word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+			if word == "" {
+				continue
+			}
+			if _, ok := commonRanks[word]; !ok {
+				commonRanks[word] = len(commonRanks) + 1
+			}
+		}
+	})
+	return commonRanks
+}
+
+// IsCommonPassword 判断密码是否在内置的常见密码列表中（不区分大小写）
+func IsCommonPassword(password string) bool {
+	_, ok := loadCommonPasswords()[strings.ToLower(password)]
+	return ok
+}
@@ -0,0 +1,220 @@
+package password
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// crypt(3) 使用的 base64 字母表
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// cryptEncode 将 3 个字节按低位优先编码为 n 个字符
+func cryptEncode(b *strings.Builder, b2, b1, b0 byte, n int) {
+	w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+	for ; n > 0; n-- {
+		b.WriteByte(cryptAlphabet[w&0x3f])
+		w >>= 6
+	}
+}
+
+// md5Crypt 实现 MD5-crypt（$1$），返回完整的哈希字符串
+func md5Crypt(password, salt []byte) string {
+	const magic = "$1$"
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+
+	alt := md5.New()
+	alt.Write(password)
+	alt.Write(salt)
+	alt.Write(password)
+	altSum := alt.Sum(nil)
+
+	ctx := md5.New()
+	ctx.Write(password)
+	ctx.Write([]byte(magic))
+	ctx.Write(salt)
+	for i := len(password); i > 0; i -= 16 {
+		ctx.Write(altSum[:min(i, 16)])
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			ctx.Write([]byte{0})
+		} else {
+			ctx.Write(password[:1])
+		}
+	}
+	final := ctx.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		c := md5.New()
+		if i&1 != 0 {
+			c.Write(password)
+		} else {
+			c.Write(final)
+		}
+		if i%3 != 0 {
+			c.Write(salt)
+		}
+		if i%7 != 0 {
+			c.Write(password)
+		}
+		if i&1 != 0 {
+			c.Write(final)
+		} else {
+			c.Write(password)
+		}
+		final = c.Sum(nil)
+	}
+
+	var b strings.Builder
+	b.WriteString(magic)
+	b.Write(salt)
+	b.WriteByte('$')
+	for _, g := range [][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		cryptEncode(&b, final[g[0]], final[g[1]], final[g[2]], 4)
+	}
+	cryptEncode(&b, 0, 0, final[11], 2)
+	return b.String()
+}
+
+const (
+	shaCryptDefaultRounds = 5000
+	shaCryptMinRounds     = 1000
+	shaCryptMaxRounds     = 999999999
+)
+
+// sha256CryptOrder、sha512CryptOrder 为 SHA-crypt 输出编码时的字节顺序
+var (
+	sha256CryptOrder = [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+	}
+	sha512CryptOrder = [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41},
+	}
+)
+
+// shaCrypt 实现 SHA-256-crypt（$5$）与 SHA-512-crypt（$6$），setting 为哈希字符串中盐值及之前的部分，
+// 如 $6$rounds=5000$salt，返回完整的哈希字符串
+func shaCrypt(password []byte, setting string) (string, error) {
+	var (
+		magic   string
+		newHash func() hash.Hash
+		order   [][3]int
+	)
+	switch {
+	case strings.HasPrefix(setting, "$5$"):
+		magic, newHash, order = "$5$", sha256.New, sha256CryptOrder
+	case strings.HasPrefix(setting, "$6$"):
+		magic, newHash, order = "$6$", sha512.New, sha512CryptOrder
+	default:
+		return "", errors.New("无效的 SHA-crypt 哈希格式")
+	}
+	rest := setting[len(magic):]
+
+	rounds, customRounds := shaCryptDefaultRounds, false
+	if value, ok := strings.CutPrefix(rest, "rounds="); ok {
+		n, after, found := strings.Cut(value, "$")
+		r, err := strconv.Atoi(n)
+		if !found || err != nil || r < 0 {
+			return "", errors.New("无效的 SHA-crypt 迭代次数")
+		}
+		rounds, customRounds, rest = min(max(r, shaCryptMinRounds), shaCryptMaxRounds), true, after
+	}
+	salt, _, _ := strings.Cut(rest, "$")
+	if len(salt) > 16 {
+		salt = salt[:16]
+	}
+	saltBytes := []byte(salt)
+
+	b := newHash()
+	b.Write(password)
+	b.Write(saltBytes)
+	b.Write(password)
+	bSum := b.Sum(nil)
+	size := len(bSum)
+
+	a := newHash()
+	a.Write(password)
+	a.Write(saltBytes)
+	for i := len(password); i > 0; i -= size {
+		a.Write(bSum[:min(i, size)])
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			a.Write(bSum)
+		} else {
+			a.Write(password)
+		}
+	}
+	aSum := a.Sum(nil)
+
+	dp := newHash()
+	for range password {
+		dp.Write(password)
+	}
+	p := repeatBytes(dp.Sum(nil), len(password))
+
+	ds := newHash()
+	for i := 0; i < 16+int(aSum[0]); i++ {
+		ds.Write(saltBytes)
+	}
+	s := repeatBytes(ds.Sum(nil), len(saltBytes))
+
+	for i := 0; i < rounds; i++ {
+		c := newHash()
+		if i&1 != 0 {
+			c.Write(p)
+		} else {
+			c.Write(aSum)
+		}
+		if i%3 != 0 {
+			c.Write(s)
+		}
+		if i%7 != 0 {
+			c.Write(p)
+		}
+		if i&1 != 0 {
+			c.Write(aSum)
+		} else {
+			c.Write(p)
+		}
+		aSum = c.Sum(nil)
+	}
+
+	var out strings.Builder
+	out.WriteString(magic)
+	if customRounds {
+		out.WriteString("rounds=" + strconv.Itoa(rounds) + "$")
+	}
+	out.WriteString(salt)
+	out.WriteByte('$')
+	for _, g := range order {
+		cryptEncode(&out, aSum[g[0]], aSum[g[1]], aSum[g[2]], 4)
+	}
+	if size == sha256.Size {
+		cryptEncode(&out, 0, aSum[31], aSum[30], 3)
+	} else {
+		cryptEncode(&out, 0, 0, aSum[63], 2)
+	}
+	return out.String(), nil
+}
+
+// repeatBytes 将 digest 重复拼接后截取 n 个字节
+func repeatBytes(digest []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		out = append(out, digest[:min(len(digest), n-len(out))]...)
+	}
+	return out
+}
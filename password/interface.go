@@ -14,6 +14,13 @@ type Crypto interface {
 	Verify(plainPassword, encrypted string) (bool, error)
 }
 
+// Rehasher 由可以判断哈希是否需要升级的 Crypto 实现
+type Rehasher interface {
+	// NeedsRehash 判断哈希是否使用了其他算法、旧格式或低于当前参数，
+	// 返回 true 时应在验证成功后用当前 Crypto 重新哈希
+	NeedsRehash(encrypted string) bool
+}
+
 func CreateCrypto(algorithm string) (Crypto, error) {
 	algorithm = strings.ToLower(algorithm)
 	switch algorithm {
@@ -23,6 +30,10 @@ func CreateCrypto(algorithm string) (Crypto, error) {
 		return NewPBKDF2Crypto(), nil
 	case "argon2":
 		return NewArgon2Crypto(), nil
+	case "scrypt":
+		return NewScryptCrypto(), nil
+	case "bcrypt-sha256", "bcrypt_sha256":
+		return NewBCryptSHA256Crypto(), nil
 	default:
 		return nil, errors.New("不支持的加密算法")
 	}
@@ -0,0 +1,129 @@
+package password
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrLegacyEncrypt = errors.New("旧系统的哈希格式仅支持验证")
+
+// 旧系统导入的哈希格式，验证成功后应始终重新哈希
+const (
+	legacyMD5          = "md5"
+	legacySHA1         = "sha1"
+	legacyMD5Crypt     = "md5-crypt"
+	legacySHA256Crypt  = "sha256-crypt"
+	legacySHA512Crypt  = "sha512-crypt"
+	legacyDjangoPBKDF2 = "django-pbkdf2-"
+)
+
+// isLegacyID 判断算法标识是否为旧系统导入的格式
+func isLegacyID(id string) bool {
+	switch id {
+	case legacyMD5, legacySHA1, legacyMD5Crypt, legacySHA256Crypt, legacySHA512Crypt:
+		return true
+	}
+	return strings.HasPrefix(id, legacyDjangoPBKDF2)
+}
+
+// LegacyCrypto 验证从旧系统迁移的密码哈希，只支持验证，不支持生成：
+//   - 无盐 MD5、SHA1（十六进制）
+//   - Django pbkdf2_sha256$<iterations>$<salt>$<hash>、pbkdf2_sha1
+//   - PHP crypt() 的 MD5-crypt（$1$）、SHA-256-crypt（$5$）、SHA-512-crypt（$6$），bcrypt（$2y$）由 BCryptCrypto 处理
+type LegacyCrypto struct{}
+
+// NewLegacyCrypto 创建旧格式验证器
+func NewLegacyCrypto() *LegacyCrypto {
+	return &LegacyCrypto{}
+}
+
+// Encrypt 不支持生成旧格式，始终返回 ErrLegacyEncrypt
+func (l *LegacyCrypto) Encrypt(string) (string, error) {
+	return "", ErrLegacyEncrypt
+}
+
+// Verify 根据哈希格式验证密码
+func (l *LegacyCrypto) Verify(password, encrypted string) (bool, error) {
+	id, err := Identify(encrypted)
+	if err != nil {
+		return false, err
+	}
+
+	switch id {
+	case legacyMD5:
+		sum := md5.Sum([]byte(password))
+		return compareHex(sum[:], encrypted), nil
+	case legacySHA1:
+		sum := sha1.Sum([]byte(password))
+		return compareHex(sum[:], encrypted), nil
+	case legacyMD5Crypt:
+		rest := strings.TrimPrefix(encrypted, "$1$")
+		salt, _, ok := strings.Cut(rest, "$")
+		if !ok {
+			return false, errors.New("无效的 MD5-crypt 哈希格式")
+		}
+		return subtle.ConstantTimeCompare([]byte(md5Crypt([]byte(password), []byte(salt))), []byte(encrypted)) == 1, nil
+	case legacySHA256Crypt, legacySHA512Crypt:
+		setting := encrypted[:strings.LastIndex(encrypted, "$")]
+		computed, err := shaCrypt([]byte(password), setting)
+		if err != nil {
+			return false, err
+		}
+		return subtle.ConstantTimeCompare([]byte(computed), []byte(encrypted)) == 1, nil
+	}
+
+	if strings.HasPrefix(id, legacyDjangoPBKDF2) {
+		return verifyDjangoPBKDF2(password, encrypted)
+	}
+	return false, fmt.Errorf("%w: %s", ErrUnknownHashFormat, id)
+}
+
+// NeedsRehash 旧格式始终需要重新哈希
+func (l *LegacyCrypto) NeedsRehash(string) bool {
+	return true
+}
+
+func compareHex(sum []byte, encrypted string) bool {
+	expected, err := hex.DecodeString(encrypted)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(sum, expected) == 1
+}
+
+// verifyDjangoPBKDF2 验证 Django 的 <algorithm>$<iterations>$<salt>$<base64-hash>，盐值为原始字符串，哈希使用带填充的 base64
+func verifyDjangoPBKDF2(password, encrypted string) (bool, error) {
+	parts := strings.Split(encrypted, "$")
+	if len(parts) != 4 {
+		return false, errors.New("无效的 Django PBKDF2 哈希格式")
+	}
+
+	var hashFunc = sha256.New
+	switch parts[0] {
+	case "pbkdf2_sha256":
+	case "pbkdf2_sha1":
+		hashFunc = sha1.New
+	default:
+		return false, fmt.Errorf("不支持的哈希算法: %s", parts[0])
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false, errors.New("无效的迭代次数")
+	}
+	expected, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(expected) == 0 {
+		return false, errors.New("无效的 Django PBKDF2 哈希格式")
+	}
+
+	key := pbkdf2Key([]byte(password), []byte(parts[2]), iterations, len(expected), hashFunc)
+	return subtle.ConstantTimeCompare(key, expected) == 1, nil
+}
@@ -0,0 +1,77 @@
+package password
+
+import (
+	"errors"
+	"testing"
+)
+
+// 以下哈希由 Python hashlib 与 crypt(3) 生成，密码均为 secret
+var legacyHashes = map[string]string{
+	"md5":                  "5ebe2294ecd0e0f08eab7690d2a6ee69",
+	"sha1":                 "e5e9fa1ba31ecd1ae84f75caaa474f3a663f05f4",
+	"django-pbkdf2-sha256": "pbkdf2_sha256$1000$NaClSalt$w6fjEpdFgTH2looEINE5y8oFnvAyvR0yKDVvkx7td7A=",
+	"django-pbkdf2-sha1":   "pbkdf2_sha1$1000$NaClSalt$YkefOC/f3TXxP1DVWBRn+9FhoZM=",
+	"md5-crypt":            "$1$PHPsalt$6znA1kGEIMnjwdo/0HYzy/",
+	"sha256-crypt":         "$5$rounds=1000$PHPsaltPHPsalt$PsBfTRWo1eckxirWCWP0xxQJddztX7JPrhRhDJE7Yr4",
+	"sha512-crypt":         "$6$PHPsalt$R9aGRcHusXwYf.FEX2MKkKjyB8pVgKL5acakz58h3OZy14zhyoNN0661XT1Rp.EHvkRiVGMDKytwH3LHho8Hf1",
+}
+
+func TestLegacyCrypto_Verify(t *testing.T) {
+	for want, encrypted := range legacyHashes {
+		id, err := Identify(encrypted)
+		if err != nil || id != want {
+			t.Fatalf("识别算法错误: 期望 %s, 实际 %s, %v", want, id, err)
+		}
+
+		ok, err := Verify("secret", encrypted)
+		if err != nil || !ok {
+			t.Fatalf("%s 验证失败: %v", want, err)
+		}
+
+		ok, err = Verify("wrongpassword", encrypted)
+		if err != nil || ok {
+			t.Fatalf("%s 错误密码验证通过: %v", want, err)
+		}
+	}
+}
+
+func TestLegacyCrypto_Rehash(t *testing.T) {
+	current := testArgon2()
+	for name, encrypted := range legacyHashes {
+		if !NeedsRehash(current, encrypted) {
+			t.Fatalf("%s 应需要重新哈希", name)
+		}
+		ok, newHash, err := VerifyAndRehash(current, "secret", encrypted)
+		if err != nil || !ok || newHash == "" {
+			t.Fatalf("%s 应验证通过并升级: %v", name, err)
+		}
+	}
+
+	if _, err := NewLegacyCrypto().Encrypt("secret"); !errors.Is(err, ErrLegacyEncrypt) {
+		t.Fatalf("旧格式不应支持生成, 实际 %v", err)
+	}
+}
+
+func TestCryptVectors(t *testing.T) {
+	// glibc crypt(3) 输出
+	if got := md5Crypt([]byte("password"), []byte("saltsalt")); got != "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/" {
+		t.Fatalf("MD5-crypt 结果错误: %s", got)
+	}
+	// 盐值超过 16 个字符时截断
+	got, err := shaCrypt([]byte("Hello world!"), "$5$rounds=10000$saltstringsaltstring")
+	if err != nil || got != "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA" {
+		t.Fatalf("SHA-256-crypt 结果错误: %s, %v", got, err)
+	}
+	got, err = shaCrypt([]byte("Hello world!"), "$6$saltstring")
+	if err != nil || got != "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1" {
+		t.Fatalf("SHA-512-crypt 结果错误: %s, %v", got, err)
+	}
+}
+
+func TestLegacyCrypto_Invalid(t *testing.T) {
+	for _, encrypted := range []string{"$1$nosalt", "$5$", "pbkdf2_sha256$x$salt$hash", "pbkdf2_md5$1000$salt$aGFzaA=="} {
+		if ok, err := Verify("secret", encrypted); ok || err == nil {
+			t.Fatalf("%q 应返回错误", encrypted)
+		}
+	}
+}
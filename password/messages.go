@@ -0,0 +1,66 @@
+package password
+
+import "fmt"
+
+// Language 提示信息的语言
+type Language string
+
+const (
+	LanguageZh Language = "zh"
+	LanguageEn Language = "en"
+)
+
+// DefaultLanguage 未指定或不支持的语言时使用的语言
+const DefaultLanguage = LanguageZh
+
+var messages = map[string]map[Language]string{
+	// 策略校验
+	string(ViolationTooShort):     {LanguageZh: "密码长度不能少于 %d 个字符", LanguageEn: "password must be at least %d characters"},
+	string(ViolationTooLong):      {LanguageZh: "密码长度不能超过 %d 个字符", LanguageEn: "password must be at most %d characters"},
+	string(ViolationNoLower):      {LanguageZh: "密码必须包含小写字母", LanguageEn: "password must contain a lowercase letter"},
+	string(ViolationNoUpper):      {LanguageZh: "密码必须包含大写字母", LanguageEn: "password must contain an uppercase letter"},
+	string(ViolationNoDigit):      {LanguageZh: "密码必须包含数字", LanguageEn: "password must contain a digit"},
+	string(ViolationNoSymbol):     {LanguageZh: "密码必须包含特殊字符", LanguageEn: "password must contain a symbol"},
+	string(ViolationCharClasses):  {LanguageZh: "密码至少需要包含 %d 类字符（小写字母、大写字母、数字、特殊字符）", LanguageEn: "password must contain at least %d of: lowercase, uppercase, digits, symbols"},
+	string(ViolationRepeated):     {LanguageZh: "同一字符不能连续出现超过 %d 次", LanguageEn: "password must not repeat a character more than %d times in a row"},
+	string(ViolationSequential):   {LanguageZh: "密码不能包含超过 %d 个字符的连续序列（如 abcd、1234）", LanguageEn: "password must not contain sequences longer than %d characters (e.g. abcd, 1234)"},
+	string(ViolationContainsUser): {LanguageZh: "密码不能包含用户名或邮箱", LanguageEn: "password must not contain your username or email"},
+	string(ViolationCommon):       {LanguageZh: "该密码过于常见，请更换", LanguageEn: "this password is too common"},
+	string(ViolationBreached):     {LanguageZh: "该密码已出现在泄露数据中 %d 次，请更换", LanguageEn: "this password has appeared in a data breach %d times"},
+	string(ViolationTooWeak):      {LanguageZh: "密码强度不足", LanguageEn: "password is too weak"},
+
+	// 强度评估提示
+	warningCommon:         {LanguageZh: "这是一个常见密码", LanguageEn: "This is a commonly used password"},
+	warningSimilarCommon:  {LanguageZh: "这与常见密码非常相似", LanguageEn: "This is similar to a commonly used password"},
+	warningUserInputs:     {LanguageZh: "密码中包含了个人信息", LanguageEn: "Passwords containing personal information are easy to guess"},
+	warningRepeat:         {LanguageZh: "重复的字符（如 aaa）很容易被猜到", LanguageEn: "Repeats like \"aaa\" are easy to guess"},
+	warningSequence:       {LanguageZh: "连续的字符（如 abc、6543）很容易被猜到", LanguageEn: "Sequences like \"abc\" or \"6543\" are easy to guess"},
+	warningKeyboard:       {LanguageZh: "键盘上相邻的按键（如 qwerty）很容易被猜到", LanguageEn: "Keyboard patterns like \"qwerty\" are easy to guess"},
+	warningYear:           {LanguageZh: "年份很容易被猜到", LanguageEn: "Years are easy to guess"},
+	warningShort:          {LanguageZh: "密码太短", LanguageEn: "This password is too short"},
+	suggestionLonger:      {LanguageZh: "使用更长的密码，例如由几个不相关的单词组成", LanguageEn: "Use a longer password, e.g. a few unrelated words"},
+	suggestionAvoidRepeat: {LanguageZh: "避免重复的单词和字符", LanguageEn: "Avoid repeated words and characters"},
+	suggestionAvoidSeq:    {LanguageZh: "避免使用连续的字符", LanguageEn: "Avoid sequences"},
+	suggestionAvoidKeys:   {LanguageZh: "避免使用键盘上相邻的按键", LanguageEn: "Avoid keyboard patterns"},
+	suggestionAvoidYear:   {LanguageZh: "避免使用近期的年份以及与自己相关的日期", LanguageEn: "Avoid recent years and dates associated with you"},
+	suggestionAvoidUser:   {LanguageZh: "避免使用用户名、邮箱等个人信息", LanguageEn: "Avoid your username, email and other personal information"},
+	suggestionUncommon:    {LanguageZh: "使用不常见的单词组合", LanguageEn: "Use uncommon words together"},
+	suggestionCaps:        {LanguageZh: "大写字母替换帮助不大", LanguageEn: "Capitalization doesn't help very much"},
+	suggestionLeet:        {LanguageZh: "用 @ 代替 a 这类替换帮助不大", LanguageEn: "Predictable substitutions like '@' instead of 'a' don't help very much"},
+}
+
+// message 按语言格式化提示信息，缺少该语言时回退到 DefaultLanguage
+func message(lang Language, id string, args ...any) string {
+	texts, ok := messages[id]
+	if !ok {
+		return id
+	}
+	text, ok := texts[lang]
+	if !ok {
+		text = texts[DefaultLanguage]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
@@ -0,0 +1,282 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrOTPReplayed      = errors.New("一次性密码已被使用")
+	ErrInvalidOTPSecret = errors.New("无效的一次性密码密钥")
+)
+
+// OTPAlgorithm HOTP/TOTP 使用的 HMAC 哈希算法
+type OTPAlgorithm string
+
+const (
+	OTPAlgorithmSHA1   OTPAlgorithm = "SHA1"
+	OTPAlgorithmSHA256 OTPAlgorithm = "SHA256"
+	OTPAlgorithmSHA512 OTPAlgorithm = "SHA512"
+)
+
+func (a OTPAlgorithm) hash() func() hash.Hash {
+	switch a {
+	case OTPAlgorithmSHA256:
+		return sha256.New
+	case OTPAlgorithmSHA512:
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
+
+func (a OTPAlgorithm) String() string {
+	if a == "" {
+		return string(OTPAlgorithmSHA1)
+	}
+	return string(a)
+}
+
+var otpSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateOTPSecret 生成随机密钥，size 为 0 时使用 RFC 4226 推荐的 20 字节
+func GenerateOTPSecret(size int) ([]byte, error) {
+	if size <= 0 {
+		size = 20
+	}
+	secret := make([]byte, size)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// EncodeOTPSecret 将密钥编码为认证器应用使用的 base32（无填充）
+func EncodeOTPSecret(secret []byte) string {
+	return otpSecretEncoding.EncodeToString(secret)
+}
+
+// DecodeOTPSecret 解码 base32 密钥，忽略大小写、空格、短横线与填充
+func DecodeOTPSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(s))
+	secret, err := otpSecretEncoding.DecodeString(s)
+	if err != nil || len(secret) == 0 {
+		return nil, ErrInvalidOTPSecret
+	}
+	return secret, nil
+}
+
+// hotpCode 实现 RFC 4226 的 HOTP 计算与动态截断
+func hotpCode(secret []byte, counter uint64, digits int, algorithm OTPAlgorithm) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(algorithm.hash(), secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff)
+
+	digits = otpDigits(digits)
+	mod := uint64(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
+
+// otpDigits 规范化验证码位数：未设置时为 6，最多 10 位
+func otpDigits(digits int) int {
+	if digits <= 0 {
+		return 6
+	}
+	return min(digits, 10)
+}
+
+// otpEqual 常量时间比较验证码
+func otpEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// otpURI 生成 otpauth:// URI，可转换为二维码供认证器应用扫描
+func otpURI(kind, issuer, account string, secret []byte, algorithm OTPAlgorithm, digits int, extra url.Values) string {
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+
+	query := url.Values{}
+	query.Set("secret", EncodeOTPSecret(secret))
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", algorithm.String())
+	query.Set("digits", strconv.Itoa(otpDigits(digits)))
+	for k, v := range extra {
+		query[k] = v
+	}
+
+	u := url.URL{Scheme: "otpauth", Host: kind, Path: "/" + label, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// HOTP 实现 RFC 4226 基于计数器的一次性密码
+type HOTP struct {
+	Secret    []byte
+	Digits    int
+	Algorithm OTPAlgorithm
+	LookAhead int // 验证时向后查找的计数器个数，用于容忍客户端多生成的验证码
+}
+
+// NewHOTP 创建 HOTP，默认 6 位、SHA1、向后查找 3 个计数器
+func NewHOTP(secret []byte) *HOTP {
+	return &HOTP{
+		Secret:    secret,
+		Digits:    6,
+		Algorithm: OTPAlgorithmSHA1,
+		LookAhead: 3,
+	}
+}
+
+// Generate 生成计数器对应的验证码
+func (h *HOTP) Generate(counter uint64) string {
+	return hotpCode(h.Secret, counter, h.Digits, h.Algorithm)
+}
+
+// Validate 从 counter 开始在查找窗口内验证，通过时返回下一次应使用的计数器，调用方需要保存它
+func (h *HOTP) Validate(code string, counter uint64) (next uint64, ok bool) {
+	for i := 0; i <= max(h.LookAhead, 0); i++ {
+		if otpEqual(h.Generate(counter+uint64(i)), code) {
+			return counter + uint64(i) + 1, true
+		}
+	}
+	return counter, false
+}
+
+// URI 生成 HOTP 的 otpauth:// URI，counter 为初始计数器
+func (h *HOTP) URI(issuer, account string, counter uint64) string {
+	return otpURI("hotp", issuer, account, h.Secret, h.Algorithm, h.Digits,
+		url.Values{"counter": {strconv.FormatUint(counter, 10)}})
+}
+
+// ReplayGuard 防止同一个 TOTP 验证码在有效期内被重复使用，可基于 Redis、数据库等实现
+type ReplayGuard interface {
+	// Use 记录 key 使用了时间步 step；step 不大于该 key 已使用的时间步时返回 false
+	Use(key string, step uint64) (bool, error)
+}
+
+// MemoryReplayGuard 基于内存的 ReplayGuard，只适用于单实例部署
+type MemoryReplayGuard struct {
+	mu   sync.Mutex
+	last map[string]uint64
+}
+
+// NewMemoryReplayGuard 创建基于内存的 ReplayGuard
+func NewMemoryReplayGuard() *MemoryReplayGuard {
+	return &MemoryReplayGuard{last: make(map[string]uint64)}
+}
+
+// Use 实现 ReplayGuard
+func (g *MemoryReplayGuard) Use(key string, step uint64) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if last, ok := g.last[key]; ok && step <= last {
+		return false, nil
+	}
+	g.last[key] = step
+	return true, nil
+}
+
+// TOTP 实现 RFC 6238 基于时间的一次性密码
+type TOTP struct {
+	Secret      []byte
+	Digits      int
+	Algorithm   OTPAlgorithm
+	Period      time.Duration
+	Skew        int         // 允许前后偏差的时间步数，用于容忍时钟误差
+	ReplayGuard ReplayGuard // 为 nil 时不做重放检查
+}
+
+// NewTOTP 创建 TOTP，默认 6 位、SHA1、30 秒、允许前后各 1 个时间步
+func NewTOTP(secret []byte) *TOTP {
+	return &TOTP{
+		Secret:    secret,
+		Digits:    6,
+		Algorithm: OTPAlgorithmSHA1,
+		Period:    30 * time.Second,
+		Skew:      1,
+	}
+}
+
+// period 返回时间步长（秒），未设置时为 30
+func (t *TOTP) period() int64 {
+	if period := int64(t.Period / time.Second); period > 0 {
+		return period
+	}
+	return 30
+}
+
+func (t *TOTP) step(at time.Time) uint64 {
+	return uint64(at.Unix() / t.period())
+}
+
+// Generate 生成指定时间的验证码
+func (t *TOTP) Generate(at time.Time) string {
+	return hotpCode(t.Secret, t.step(at), t.Digits, t.Algorithm)
+}
+
+// ValidateAt 验证指定时间的验证码，通过时返回匹配的时间步，不做重放检查
+func (t *TOTP) ValidateAt(code string, at time.Time) (step uint64, ok bool) {
+	current := t.step(at)
+	skew := max(t.Skew, 0)
+	for i := -skew; i <= skew; i++ {
+		if i < 0 && current < uint64(-i) {
+			continue
+		}
+		s := current + uint64(i)
+		if otpEqual(hotpCode(t.Secret, s, t.Digits, t.Algorithm), code) {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// Validate 验证当前时间的验证码；设置了 ReplayGuard 时，key（通常为用户 ID）已使用过的验证码返回 ErrOTPReplayed
+func (t *TOTP) Validate(key, code string) (bool, error) {
+	step, ok := t.ValidateAt(code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	if t.ReplayGuard == nil {
+		return true, nil
+	}
+	fresh, err := t.ReplayGuard.Use(key, step)
+	if err != nil {
+		return false, err
+	}
+	if !fresh {
+		return false, ErrOTPReplayed
+	}
+	return true, nil
+}
+
+// URI 生成 TOTP 的 otpauth:// URI
+func (t *TOTP) URI(issuer, account string) string {
+	return otpURI("totp", issuer, account, t.Secret, t.Algorithm, t.Digits,
+		url.Values{"period": {strconv.FormatInt(t.period(), 10)}})
+}
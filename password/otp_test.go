@@ -0,0 +1,145 @@
+package password
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHOTP_RFC4226(t *testing.T) {
+	hotp := NewHOTP([]byte("12345678901234567890"))
+	expected := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, want := range expected {
+		if got := hotp.Generate(uint64(counter)); got != want {
+			t.Fatalf("计数器 %d: 期望 %s, 实际 %s", counter, want, got)
+		}
+	}
+}
+
+func TestHOTP_Validate(t *testing.T) {
+	hotp := NewHOTP([]byte("12345678901234567890"))
+
+	next, ok := hotp.Validate("969429", 1)
+	if !ok || next != 4 {
+		t.Fatalf("查找窗口内应验证通过: next=%d ok=%v", next, ok)
+	}
+	if _, ok := hotp.Validate("755224", next); ok {
+		t.Fatal("已使用过的计数器不应验证通过")
+	}
+	if _, ok := hotp.Validate("520489", 0); ok {
+		t.Fatal("超出查找窗口不应验证通过")
+	}
+}
+
+func TestTOTP_RFC6238(t *testing.T) {
+	secrets := map[OTPAlgorithm]string{
+		OTPAlgorithmSHA1:   "12345678901234567890",
+		OTPAlgorithmSHA256: "12345678901234567890123456789012",
+		OTPAlgorithmSHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	vectors := []struct {
+		unix int64
+		want map[OTPAlgorithm]string
+	}{
+		{59, map[OTPAlgorithm]string{OTPAlgorithmSHA1: "94287082", OTPAlgorithmSHA256: "46119246", OTPAlgorithmSHA512: "90693936"}},
+		{1111111109, map[OTPAlgorithm]string{OTPAlgorithmSHA1: "07081804", OTPAlgorithmSHA256: "68084774", OTPAlgorithmSHA512: "25091201"}},
+		{1234567890, map[OTPAlgorithm]string{OTPAlgorithmSHA1: "89005924", OTPAlgorithmSHA256: "91819424", OTPAlgorithmSHA512: "93441116"}},
+		{20000000000, map[OTPAlgorithm]string{OTPAlgorithmSHA1: "65353130", OTPAlgorithmSHA256: "77737706", OTPAlgorithmSHA512: "47863826"}},
+	}
+
+	for _, v := range vectors {
+		for alg, want := range v.want {
+			totp := NewTOTP([]byte(secrets[alg]))
+			totp.Digits = 8
+			totp.Algorithm = alg
+			if got := totp.Generate(time.Unix(v.unix, 0)); got != want {
+				t.Fatalf("%s@%d: 期望 %s, 实际 %s", alg, v.unix, want, got)
+			}
+		}
+	}
+}
+
+func TestTOTP_Skew(t *testing.T) {
+	totp := NewTOTP([]byte("12345678901234567890"))
+	now := time.Unix(1700000000, 0)
+
+	previous := totp.Generate(now.Add(-30 * time.Second))
+	if _, ok := totp.ValidateAt(previous, now); !ok {
+		t.Fatal("上一个时间步的验证码应验证通过")
+	}
+	tooOld := totp.Generate(now.Add(-90 * time.Second))
+	if _, ok := totp.ValidateAt(tooOld, now); ok {
+		t.Fatal("超出偏差窗口不应验证通过")
+	}
+
+	totp.Skew = 0
+	if _, ok := totp.ValidateAt(previous, now); ok {
+		t.Fatal("Skew 为 0 时只接受当前时间步")
+	}
+
+	// 时间步为 0 时不能向前回绕
+	if _, ok := NewTOTP([]byte("12345678901234567890")).ValidateAt("000000", time.Unix(0, 0)); ok {
+		t.Fatal("不应验证通过")
+	}
+}
+
+func TestTOTP_Replay(t *testing.T) {
+	totp := NewTOTP([]byte("12345678901234567890"))
+	totp.ReplayGuard = NewMemoryReplayGuard()
+	code := totp.Generate(time.Now())
+
+	ok, err := totp.Validate("user-1", code)
+	if err != nil || !ok {
+		t.Fatalf("首次验证失败: %v", err)
+	}
+	if _, err := totp.Validate("user-1", code); !errors.Is(err, ErrOTPReplayed) {
+		t.Fatalf("重复使用应返回 ErrOTPReplayed, 实际 %v", err)
+	}
+	if ok, err := totp.Validate("user-2", code); err != nil || !ok {
+		t.Fatalf("不同用户互不影响: %v", err)
+	}
+	if ok, err := totp.Validate("user-1", "000000x"); err != nil || ok {
+		t.Fatalf("错误的验证码不应通过: %v", err)
+	}
+}
+
+func TestTOTP_URI(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	uri := NewTOTP(secret).URI("Example Co", "alice@example.com")
+	if !strings.HasPrefix(uri, "otpauth://totp/Example%20Co:alice@example.com?") {
+		t.Fatalf("URI 错误: %s", uri)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("解析 URI 失败: %v", err)
+	}
+	q := u.Query()
+	if q.Get("secret") != "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" || q.Get("issuer") != "Example Co" ||
+		q.Get("algorithm") != "SHA1" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Fatalf("URI 参数错误: %v", q)
+	}
+
+	hotpURI := NewHOTP(secret).URI("", "bob", 7)
+	if !strings.HasPrefix(hotpURI, "otpauth://hotp/bob?") || !strings.Contains(hotpURI, "counter=7") {
+		t.Fatalf("HOTP URI 错误: %s", hotpURI)
+	}
+}
+
+func TestOTPSecret(t *testing.T) {
+	secret, err := GenerateOTPSecret(0)
+	if err != nil || len(secret) != 20 {
+		t.Fatalf("生成密钥失败: %d, %v", len(secret), err)
+	}
+
+	encoded := EncodeOTPSecret(secret)
+	decoded, err := DecodeOTPSecret(strings.ToLower(encoded[:8]) + " " + encoded[8:])
+	if err != nil || string(decoded) != string(secret) {
+		t.Fatalf("解码失败: %v", err)
+	}
+	if _, err := DecodeOTPSecret("not base32!"); !errors.Is(err, ErrInvalidOTPSecret) {
+		t.Fatalf("应返回 ErrInvalidOTPSecret, 实际 %v", err)
+	}
+}
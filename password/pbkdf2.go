@@ -54,53 +54,108 @@ func (p *PBKDF2Crypto) Encrypt(password string) (string, error) {
 	// 生成密钥
 	key := pbkdf2Key([]byte(password), salt, p.Iterations, p.KeyLength, p.Hash)
 
-	// 格式: pbkdf2:<hash>:<iterations>:<base64-salt>:<base64-key>
-	return fmt.Sprintf(
-		"pbkdf2:%s:%d:%s:%s",
-		p.HashName,
-		p.Iterations,
-		base64.RawStdEncoding.EncodeToString(salt),
-		base64.RawStdEncoding.EncodeToString(key),
-	), nil
+	// 格式: $pbkdf2-<hash>$i=<iterations>$<base64-salt>$<base64-key>
+	return (&PHC{
+		ID:     "pbkdf2-" + p.HashName,
+		Params: []PHCParam{{Name: "i", Value: strconv.Itoa(p.Iterations)}},
+		Salt:   salt,
+		Hash:   key,
+	}).String(), nil
 }
 
-// Verify 验证密码
+// Verify 验证密码，同时兼容旧格式 pbkdf2:<hash>:<iterations>:<base64-salt>:<base64-key>
 func (p *PBKDF2Crypto) Verify(password, encrypted string) (bool, error) {
-	// 解析哈希字符串
+	h, err := parsePBKDF2(encrypted)
+	if err != nil {
+		return false, err
+	}
+
+	// 根据哈希名称选择哈希函数
+	hashFunc, ok := getHashFunction(h.hashName)
+	if !ok {
+		return false, fmt.Errorf("不支持的哈希算法: %s", h.hashName)
+	}
+
+	// 生成新密钥
+	newKey := pbkdf2Key([]byte(password), h.salt, h.iterations, len(h.key), hashFunc)
+
+	// 安全比较
+	return hmac.Equal(newKey, h.key), nil
+}
+
+// NeedsRehash 判断哈希是否为旧格式、使用了其他哈希函数或低于当前参数
+func (p *PBKDF2Crypto) NeedsRehash(encrypted string) bool {
+	h, err := parsePBKDF2(encrypted)
+	if err != nil {
+		return true
+	}
+	return h.legacy ||
+		h.hashName != p.HashName ||
+		h.iterations < p.Iterations ||
+		len(h.key) < p.KeyLength
+}
+
+type pbkdf2Hash struct {
+	hashName   string
+	iterations int
+	salt       []byte
+	key        []byte
+	legacy     bool
+}
+
+// parsePBKDF2 解析 PHC 格式或旧的冒号分隔格式
+func parsePBKDF2(encrypted string) (*pbkdf2Hash, error) {
+	if !strings.HasPrefix(encrypted, "$") {
+		return parseLegacyPBKDF2(encrypted)
+	}
+
+	phc, err := ParsePHC(encrypted)
+	if err != nil || !strings.HasPrefix(phc.ID, "pbkdf2-") || len(phc.Hash) == 0 {
+		return nil, errors.New("无效的 PBKDF2 哈希格式")
+	}
+	iterations, err := phc.IntParam("i")
+	if err != nil || iterations == 0 {
+		return nil, errors.New("无效的迭代次数")
+	}
+	return &pbkdf2Hash{
+		hashName:   strings.TrimPrefix(phc.ID, "pbkdf2-"),
+		iterations: iterations,
+		salt:       phc.Salt,
+		key:        phc.Hash,
+	}, nil
+}
+
+func parseLegacyPBKDF2(encrypted string) (*pbkdf2Hash, error) {
 	parts := strings.Split(encrypted, ":")
 	if len(parts) != 5 || parts[0] != "pbkdf2" {
-		return false, errors.New("无效的 PBKDF2 哈希格式")
+		return nil, errors.New("无效的 PBKDF2 哈希格式")
 	}
 
-	// 解析参数
-	hashName := parts[1]
 	iterations, err := strconv.Atoi(parts[2])
-	if err != nil {
-		return false, errors.New("无效的迭代次数")
+	if err != nil || iterations <= 0 {
+		return nil, errors.New("无效的迭代次数")
 	}
 
 	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	expectedKey, err := base64.RawStdEncoding.DecodeString(parts[4])
+	key, err := base64.RawStdEncoding.DecodeString(parts[4])
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-
-	// 根据哈希名称选择哈希函数
-	hashFunc, ok := getHashFunction(hashName)
-	if !ok {
-		return false, fmt.Errorf("不支持的哈希算法: %s", hashName)
+	if len(key) == 0 {
+		return nil, errors.New("无效的 PBKDF2 哈希格式")
 	}
 
-	// 生成新密钥
-	keyLength := len(expectedKey)
-	newKey := pbkdf2Key([]byte(password), salt, iterations, keyLength, hashFunc)
-
-	// 安全比较
-	return hmac.Equal(newKey, expectedKey), nil
+	return &pbkdf2Hash{
+		hashName:   parts[1],
+		iterations: iterations,
+		salt:       salt,
+		key:        key,
+		legacy:     true,
+	}, nil
 }
 
 // pbkdf2Key 实现 PBKDF2 核心算法
@@ -0,0 +1,174 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrPepperNotFound       = errors.New("pepper 版本不存在")
+	ErrPepperRequired       = errors.New("哈希使用了 pepper，需要通过 PepperedCrypto 验证")
+	ErrPepperUnsupported    = errors.New("pepper 只支持输出 PHC 格式的算法")
+	errPepperEmpty          = errors.New("pepper 不能为空")
+	errPepperInvalidVersion = errors.New("pepper 版本必须为正整数")
+)
+
+// pepperParam 哈希中记录 pepper 版本的 PHC 参数名
+const pepperParam = "pv"
+
+// Pepper 保存在服务端（配置或 KMS）而不是数据库中的密钥，按版本管理以支持轮换
+type Pepper struct {
+	mu      sync.RWMutex
+	keys    map[int][]byte
+	current int
+}
+
+// NewPepper 创建 Pepper，version 为当前版本
+func NewPepper(version int, key []byte) (*Pepper, error) {
+	p := &Pepper{keys: make(map[int][]byte)}
+	if err := p.Add(version, key); err != nil {
+		return nil, err
+	}
+	p.current = version
+	return p, nil
+}
+
+// Add 添加一个版本，用于验证旧版本 pepper 生成的哈希
+func (p *Pepper) Add(version int, key []byte) error {
+	if version <= 0 {
+		return errPepperInvalidVersion
+	}
+	if len(key) == 0 {
+		return errPepperEmpty
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[version] = append([]byte(nil), key...)
+	return nil
+}
+
+// SetCurrent 切换当前版本，之后生成的哈希使用该版本，旧哈希在验证后会被标记为需要重新哈希
+func (p *Pepper) SetCurrent(version int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.keys[version]; !ok {
+		return fmt.Errorf("%w: %d", ErrPepperNotFound, version)
+	}
+	p.current = version
+	return nil
+}
+
+// Current 返回当前版本
+func (p *Pepper) Current() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current
+}
+
+// apply 使用指定版本对密码做 HMAC-SHA256，输出 base64 以便交给任意密码哈希算法
+func (p *Pepper) apply(version int, password string) (string, error) {
+	p.mu.RLock()
+	key, ok := p.keys[version]
+	p.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrPepperNotFound, version)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(password))
+	return base64.RawStdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// PepperedCrypto 在哈希前先用 pepper 对密码做 HMAC，pepper 版本以 pv 参数记录在 PHC 字符串中，如
+//
+//	$argon2id$v=19$m=65536,t=3,p=2,pv=1$<salt>$<hash>
+//
+// 只支持输出 PHC 格式的算法（Argon2、PBKDF2、scrypt、SHA），bcrypt 不支持
+type PepperedCrypto struct {
+	inner  Crypto
+	pepper *Pepper
+}
+
+// NewPepperedCrypto 创建带 pepper 的加密器，inner 为实际使用的密码哈希算法
+func NewPepperedCrypto(inner Crypto, pepper *Pepper) *PepperedCrypto {
+	return &PepperedCrypto{inner: inner, pepper: pepper}
+}
+
+// Encrypt 使用当前版本的 pepper 加密密码
+func (c *PepperedCrypto) Encrypt(password string) (string, error) {
+	version := c.pepper.Current()
+	peppered, err := c.pepper.apply(version, password)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := c.inner.Encrypt(peppered)
+	if err != nil {
+		return "", err
+	}
+	p, err := ParsePHC(encrypted)
+	if err != nil {
+		return "", ErrPepperUnsupported
+	}
+	p.SetParam(pepperParam, strconv.Itoa(version))
+	return p.String(), nil
+}
+
+// Verify 根据哈希中的 pepper 版本验证密码，算法从哈希中自动识别；
+// 没有 pepper 版本的哈希按原始密码验证，便于在启用 pepper 前后平滑过渡
+func (c *PepperedCrypto) Verify(password, encrypted string) (bool, error) {
+	version, stripped, err := splitPepper(encrypted)
+	if err != nil {
+		return false, err
+	}
+	if version > 0 {
+		if password, err = c.pepper.apply(version, password); err != nil {
+			return false, err
+		}
+	}
+	return verifyUnpeppered(password, stripped)
+}
+
+// NeedsRehash 未使用 pepper、pepper 版本不是当前版本，或 inner 判断需要升级时返回 true
+func (c *PepperedCrypto) NeedsRehash(encrypted string) bool {
+	version, stripped, err := splitPepper(encrypted)
+	if err != nil || version != c.pepper.Current() {
+		return true
+	}
+	return NeedsRehash(c.inner, stripped)
+}
+
+// acceptsAnyFormat 表示 Verify 可以自行识别所有格式，VerifyAndRehash 应调用它而不是包级 Verify
+func (c *PepperedCrypto) acceptsAnyFormat() {}
+
+// splitPepper 取出 pepper 版本并返回去掉 pv 参数后的哈希；没有 pepper 时版本为 0
+func splitPepper(encrypted string) (int, string, error) {
+	p, err := ParsePHC(encrypted)
+	if err != nil {
+		return 0, encrypted, nil
+	}
+	value, ok := p.Param(pepperParam)
+	if !ok {
+		return 0, encrypted, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version <= 0 {
+		return 0, "", fmt.Errorf("%w: 无效的 pepper 版本 %q", ErrInvalidPHC, value)
+	}
+
+	params := p.Params[:0:0]
+	for _, param := range p.Params {
+		if param.Name != pepperParam {
+			params = append(params, param)
+		}
+	}
+	p.Params = params
+	return version, p.String(), nil
+}
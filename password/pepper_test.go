@@ -0,0 +1,102 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPepperedCrypto_EncryptAndVerify(t *testing.T) {
+	pepper, err := NewPepper(1, []byte("server-side-secret"))
+	if err != nil {
+		t.Fatalf("创建 pepper 失败: %v", err)
+	}
+
+	for _, inner := range []Crypto{testArgon2(), testPBKDF2(1000), NewSHA256Crypto()} {
+		crypto := NewPepperedCrypto(inner, pepper)
+
+		encrypted, err := crypto.Encrypt("securepassword")
+		if err != nil {
+			t.Fatalf("加密失败: %v", err)
+		}
+		if !strings.Contains(encrypted, "pv=1") {
+			t.Fatalf("哈希中缺少 pepper 版本: %s", encrypted)
+		}
+		t.Log(encrypted)
+
+		ok, err := crypto.Verify("securepassword", encrypted)
+		if err != nil || !ok {
+			t.Fatalf("验证失败: %v", err)
+		}
+		ok, err = crypto.Verify("wrongpassword", encrypted)
+		if err != nil || ok {
+			t.Fatalf("错误密码验证通过: %v", err)
+		}
+
+		// 没有 pepper 无法验证
+		if _, err := Verify("securepassword", encrypted); !errors.Is(err, ErrPepperRequired) {
+			t.Fatalf("应返回 ErrPepperRequired, 实际 %v", err)
+		}
+	}
+}
+
+func TestPepperedCrypto_WrongPepper(t *testing.T) {
+	pepper1, _ := NewPepper(1, []byte("secret-1"))
+	other, _ := NewPepper(1, []byte("secret-2"))
+
+	encrypted, err := NewPepperedCrypto(testArgon2(), pepper1).Encrypt("securepassword")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	ok, err := NewPepperedCrypto(testArgon2(), other).Verify("securepassword", encrypted)
+	if err != nil || ok {
+		t.Fatalf("不同的 pepper 不应验证通过: %v", err)
+	}
+}
+
+func TestPepperedCrypto_Rotation(t *testing.T) {
+	pepper, _ := NewPepper(1, []byte("secret-1"))
+	crypto := NewPepperedCrypto(testArgon2(), pepper)
+
+	unpeppered, _ := testArgon2().Encrypt("securepassword")
+	v1, _ := crypto.Encrypt("securepassword")
+
+	if err := pepper.Add(2, []byte("secret-2")); err != nil {
+		t.Fatalf("添加版本失败: %v", err)
+	}
+	if err := pepper.SetCurrent(2); err != nil {
+		t.Fatalf("切换版本失败: %v", err)
+	}
+	if err := pepper.SetCurrent(3); !errors.Is(err, ErrPepperNotFound) {
+		t.Fatalf("应返回 ErrPepperNotFound, 实际 %v", err)
+	}
+
+	for _, encrypted := range []string{unpeppered, v1} {
+		if !crypto.NeedsRehash(encrypted) {
+			t.Fatalf("%s 应需要重新哈希", encrypted)
+		}
+		ok, newHash, err := VerifyAndRehash(crypto, "securepassword", encrypted)
+		if err != nil || !ok {
+			t.Fatalf("验证失败: %v", err)
+		}
+		if !strings.Contains(newHash, "pv=2") {
+			t.Fatalf("应升级到当前 pepper 版本: %s", newHash)
+		}
+		if crypto.NeedsRehash(newHash) {
+			t.Fatal("升级后的哈希不应再需要重新哈希")
+		}
+	}
+}
+
+func TestPepperedCrypto_Unsupported(t *testing.T) {
+	pepper, _ := NewPepper(1, []byte("secret"))
+	if _, err := NewPepperedCrypto(NewBCryptCrypto(4), pepper).Encrypt("securepassword"); !errors.Is(err, ErrPepperUnsupported) {
+		t.Fatalf("bcrypt 应返回 ErrPepperUnsupported, 实际 %v", err)
+	}
+	if _, err := NewPepper(0, []byte("secret")); err == nil {
+		t.Fatal("版本为 0 时应返回错误")
+	}
+	if _, err := NewPepper(1, nil); err == nil {
+		t.Fatal("密钥为空时应返回错误")
+	}
+}
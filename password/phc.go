@@ -0,0 +1,147 @@
+package password
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPHC        = errors.New("无效的 PHC 字符串")
+	ErrUnknownHashFormat = errors.New("无法识别的密码哈希格式")
+)
+
+// PHCParam PHC 字符串中的一个参数，保持原有顺序
+type PHCParam struct {
+	Name  string
+	Value string
+}
+
+// PHC 表示 PHC 字符串格式的密码哈希：
+//
+//	$<id>[$v=<version>][$<param>=<value>(,<param>=<value>)*][$<salt>[$<hash>]]
+//
+// 盐值与哈希使用不带填充的标准 base64 编码
+type PHC struct {
+	ID      string
+	Version int // 0 表示不带版本字段
+	Params  []PHCParam
+	Salt    []byte
+	Hash    []byte
+}
+
+// ParsePHC 解析 PHC 字符串
+func ParsePHC(s string) (*PHC, error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, ErrInvalidPHC
+	}
+	fields := strings.Split(s[1:], "$")
+	if fields[0] == "" {
+		return nil, fmt.Errorf("%w: 缺少算法标识", ErrInvalidPHC)
+	}
+
+	p := &PHC{ID: fields[0]}
+	fields = fields[1:]
+
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "v=") {
+		v, err := strconv.Atoi(fields[0][2:])
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("%w: 无效的版本 %q", ErrInvalidPHC, fields[0])
+		}
+		p.Version = v
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 && strings.Contains(fields[0], "=") {
+		for _, kv := range strings.Split(fields[0], ",") {
+			name, value, ok := strings.Cut(kv, "=")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("%w: 无效的参数 %q", ErrInvalidPHC, kv)
+			}
+			p.Params = append(p.Params, PHCParam{Name: name, Value: value})
+		}
+		fields = fields[1:]
+	}
+
+	if len(fields) > 2 {
+		return nil, fmt.Errorf("%w: 字段过多", ErrInvalidPHC)
+	}
+	var err error
+	if len(fields) > 0 {
+		if p.Salt, err = base64.RawStdEncoding.DecodeString(fields[0]); err != nil {
+			return nil, fmt.Errorf("%w: 盐值解码失败", ErrInvalidPHC)
+		}
+	}
+	if len(fields) > 1 {
+		if p.Hash, err = base64.RawStdEncoding.DecodeString(fields[1]); err != nil {
+			return nil, fmt.Errorf("%w: 哈希解码失败", ErrInvalidPHC)
+		}
+	}
+	return p, nil
+}
+
+// String 编码为 PHC 字符串
+func (p *PHC) String() string {
+	var b strings.Builder
+	b.WriteString("$")
+	b.WriteString(p.ID)
+	if p.Version > 0 {
+		fmt.Fprintf(&b, "$v=%d", p.Version)
+	}
+	if len(p.Params) > 0 {
+		b.WriteString("$")
+		for i, param := range p.Params {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(param.Name)
+			b.WriteString("=")
+			b.WriteString(param.Value)
+		}
+	}
+	if p.Salt != nil {
+		b.WriteString("$")
+		b.WriteString(base64.RawStdEncoding.EncodeToString(p.Salt))
+		if p.Hash != nil {
+			b.WriteString("$")
+			b.WriteString(base64.RawStdEncoding.EncodeToString(p.Hash))
+		}
+	}
+	return b.String()
+}
+
+// Param 返回参数值
+func (p *PHC) Param(name string) (string, bool) {
+	for _, param := range p.Params {
+		if param.Name == name {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+// IntParam 返回整数参数值，参数缺失或不是非负整数时返回错误
+func (p *PHC) IntParam(name string) (int, error) {
+	value, ok := p.Param(name)
+	if !ok {
+		return 0, fmt.Errorf("%w: 缺少参数 %s", ErrInvalidPHC, name)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: 无效的参数 %s=%s", ErrInvalidPHC, name, value)
+	}
+	return n, nil
+}
+
+// SetParam 设置参数，已存在时覆盖原值
+func (p *PHC) SetParam(name, value string) {
+	for i := range p.Params {
+		if p.Params[i].Name == name {
+			p.Params[i].Value = value
+			return
+		}
+	}
+	p.Params = append(p.Params, PHCParam{Name: name, Value: value})
+}
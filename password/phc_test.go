@@ -0,0 +1,74 @@
+package password
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParsePHC(t *testing.T) {
+	s := "$argon2id$v=19$m=65536,t=3,p=2$c29tZXNhbHQ$aGFzaA"
+	p, err := ParsePHC(s)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if p.ID != "argon2id" || p.Version != 19 {
+		t.Fatalf("算法或版本错误: %s v=%d", p.ID, p.Version)
+	}
+	if m, err := p.IntParam("m"); err != nil || m != 65536 {
+		t.Fatalf("参数 m 错误: %d, %v", m, err)
+	}
+	if !bytes.Equal(p.Salt, []byte("somesalt")) || !bytes.Equal(p.Hash, []byte("hash")) {
+		t.Fatal("盐值或哈希解码错误")
+	}
+	if p.String() != s {
+		t.Fatalf("重新编码不一致: %s", p.String())
+	}
+}
+
+func TestParsePHC_Optional(t *testing.T) {
+	cases := []string{
+		"$sha256",
+		"$sha256$c2FsdA",
+		"$sha256$c2FsdA$aGFzaA",
+		"$pbkdf2-sha256$i=1000$c2FsdA$aGFzaA",
+	}
+	for _, s := range cases {
+		p, err := ParsePHC(s)
+		if err != nil {
+			t.Fatalf("解析 %s 失败: %v", s, err)
+		}
+		if p.String() != s {
+			t.Fatalf("重新编码不一致: %s != %s", p.String(), s)
+		}
+	}
+}
+
+func TestParsePHC_Invalid(t *testing.T) {
+	cases := []string{
+		"",
+		"argon2id$v=19",
+		"$",
+		"$argon2id$v=x$m=1$c2FsdA$aGFzaA",
+		"$argon2id$m=1,t$c2FsdA$aGFzaA",
+		"$argon2id$m=1$c2FsdA$aGFzaA$extra",
+		"$argon2id$m=1$!!!$aGFzaA",
+	}
+	for _, s := range cases {
+		if _, err := ParsePHC(s); !errors.Is(err, ErrInvalidPHC) {
+			t.Fatalf("%q 应返回 ErrInvalidPHC, 实际 %v", s, err)
+		}
+	}
+}
+
+func TestPHC_SetParam(t *testing.T) {
+	p := &PHC{ID: "pbkdf2-sha256", Params: []PHCParam{{Name: "i", Value: "1000"}}}
+	p.SetParam("i", "2000")
+	p.SetParam("l", "32")
+	if p.String() != "$pbkdf2-sha256$i=2000,l=32" {
+		t.Fatalf("编码错误: %s", p.String())
+	}
+	if _, err := p.IntParam("x"); err == nil {
+		t.Fatal("缺少参数时应返回错误")
+	}
+}
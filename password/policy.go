@@ -0,0 +1,268 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ViolationCode 密码策略校验未通过的原因
+type ViolationCode string
+
+const (
+	ViolationTooShort     ViolationCode = "too_short"
+	ViolationTooLong      ViolationCode = "too_long"
+	ViolationNoLower      ViolationCode = "no_lower"
+	ViolationNoUpper      ViolationCode = "no_upper"
+	ViolationNoDigit      ViolationCode = "no_digit"
+	ViolationNoSymbol     ViolationCode = "no_symbol"
+	ViolationCharClasses  ViolationCode = "char_classes"
+	ViolationRepeated     ViolationCode = "repeated"
+	ViolationSequential   ViolationCode = "sequential"
+	ViolationContainsUser ViolationCode = "contains_user"
+	ViolationCommon       ViolationCode = "common"
+	ViolationBreached     ViolationCode = "breached"
+	ViolationTooWeak      ViolationCode = "too_weak"
+)
+
+// Violation 一条未通过的策略规则
+type Violation struct {
+	Code ViolationCode
+	Args []any // 格式化提示信息所需的参数，如最小长度
+}
+
+// Message 返回指定语言的提示信息
+func (v Violation) Message(lang Language) string {
+	return message(lang, string(v.Code), v.Args...)
+}
+
+// PolicyError 密码未满足策略时返回的错误，包含所有未通过的规则
+type PolicyError struct {
+	Violations []Violation
+	Language   Language
+	Strength   *StrengthResult // 设置了 MinScore 时的强度评估结果
+}
+
+func (e *PolicyError) Error() string {
+	return strings.Join(e.Messages(e.Language), "; ")
+}
+
+// Messages 返回指定语言的全部提示信息
+func (e *PolicyError) Messages(lang Language) []string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Message(lang))
+	}
+	return msgs
+}
+
+// Has 判断是否包含指定原因
+func (e *PolicyError) Has(code ViolationCode) bool {
+	for _, v := range e.Violations {
+		if v.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// UserInfo 用于检查密码是否包含个人信息
+type UserInfo struct {
+	Username string
+	Email    string
+	Extra    []string // 其他个人信息，如姓名、手机号，只参与强度评估
+}
+
+func (u UserInfo) inputs() []string {
+	inputs := make([]string, 0, 2+len(u.Extra))
+	for _, s := range append([]string{u.Username, u.Email}, u.Extra...) {
+		if s != "" {
+			inputs = append(inputs, s)
+		}
+	}
+	return inputs
+}
+
+// Policy 密码策略，零值字段表示不启用对应规则
+type Policy struct {
+	MinLength int // 最小长度（按字符计）
+	MaxLength int // 最大长度（按字符计）
+
+	RequireLower   bool
+	RequireUpper   bool
+	RequireDigit   bool
+	RequireSymbol  bool
+	MinCharClasses int // 至少包含的字符类别数（小写、大写、数字、特殊字符）
+
+	MaxRepeat     int // 同一字符最多连续出现的次数
+	MaxSequential int // 连续递增或递减序列（如 abcd、4321）的最大长度
+
+	DisallowUserInfo bool          // 不允许包含用户名与邮箱
+	CheckCommon      bool          // 检查内置的常见密码列表
+	BreachChecker    BreachChecker // 泄露检查，nil 表示不检查
+	MinScore         int           // 最低强度评分 0-4
+
+	Language Language // 提示信息语言，默认 DefaultLanguage
+}
+
+// DefaultPolicy 返回推荐的默认策略
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MinLength:        8,
+		MaxLength:        128,
+		MinCharClasses:   2,
+		MaxRepeat:        3,
+		MaxSequential:    4,
+		DisallowUserInfo: true,
+		CheckCommon:      true,
+		MinScore:         2,
+		Language:         DefaultLanguage,
+	}
+}
+
+// Validate 按策略校验密码，未通过时返回 *PolicyError；泄露检查出错时直接返回该错误
+func (p *Policy) Validate(password string, user UserInfo) error {
+	var violations []Violation
+	add := func(code ViolationCode, args ...any) {
+		violations = append(violations, Violation{Code: code, Args: args})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		add(ViolationTooShort, p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(ViolationTooLong, p.MaxLength)
+	}
+
+	lower, upper, digit, symbol := charClasses(password)
+	if p.RequireLower && !lower {
+		add(ViolationNoLower)
+	}
+	if p.RequireUpper && !upper {
+		add(ViolationNoUpper)
+	}
+	if p.RequireDigit && !digit {
+		add(ViolationNoDigit)
+	}
+	if p.RequireSymbol && !symbol {
+		add(ViolationNoSymbol)
+	}
+	if p.MinCharClasses > 0 && countTrue(lower, upper, digit, symbol) < p.MinCharClasses {
+		add(ViolationCharClasses, p.MinCharClasses)
+	}
+
+	if p.MaxRepeat > 0 && longestRepeat(password) > p.MaxRepeat {
+		add(ViolationRepeated, p.MaxRepeat)
+	}
+	if p.MaxSequential > 0 && longestSequence(password) > p.MaxSequential {
+		add(ViolationSequential, p.MaxSequential)
+	}
+
+	if p.DisallowUserInfo && containsUserInfo(password, user) {
+		add(ViolationContainsUser)
+	}
+	if p.CheckCommon && IsCommonPassword(password) {
+		add(ViolationCommon)
+	}
+	if p.BreachChecker != nil {
+		count, err := p.BreachChecker.BreachCount(password)
+		if err != nil {
+			return fmt.Errorf("泄露检查失败: %w", err)
+		}
+		if count > 0 {
+			add(ViolationBreached, count)
+		}
+	}
+
+	var strength *StrengthResult
+	if p.MinScore > 0 {
+		strength = EstimateStrength(password, p.language(), user.inputs()...)
+		if strength.Score < p.MinScore {
+			add(ViolationTooWeak)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &PolicyError{Violations: violations, Language: p.language(), Strength: strength}
+}
+
+func (p *Policy) language() Language {
+	if p.Language == "" {
+		return DefaultLanguage
+	}
+	return p.Language
+}
+
+func charClasses(password string) (lower, upper, digit, symbol bool) {
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	return
+}
+
+func countTrue(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+// longestRepeat 返回同一字符连续出现的最大次数
+func longestRepeat(password string) int {
+	longest, run := 0, 0
+	var prev rune = -1
+	for _, r := range password {
+		if r == prev {
+			run++
+		} else {
+			run = 1
+			prev = r
+		}
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// longestSequence 返回字母或数字连续递增、递减序列的最大长度（不区分大小写）
+func longestSequence(password string) int {
+	runes := []rune(strings.ToLower(password))
+	longest := min(len(runes), 1)
+	for _, m := range sequenceMatches(runes) {
+		longest = max(longest, m.j-m.i+1)
+	}
+	if longest < 2 {
+		for i := 1; i < len(runes); i++ {
+			if d := runes[i] - runes[i-1]; (d == 1 || d == -1) && isAlnum(runes[i]) && isAlnum(runes[i-1]) {
+				return 2
+			}
+		}
+	}
+	return longest
+}
+
+// containsUserInfo 判断密码是否包含用户名、邮箱或邮箱 @ 之前的部分（不区分大小写，少于 3 个字符的忽略）
+func containsUserInfo(password string, user UserInfo) bool {
+	lower := strings.ToLower(password)
+	for input := range userInputRanks([]string{user.Username, user.Email}) {
+		if strings.Contains(lower, input) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package password
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	policy := DefaultPolicy()
+	user := UserInfo{Username: "johnsmith", Email: "john.smith@example.com"}
+
+	cases := []struct {
+		password string
+		want     ViolationCode
+	}{
+		{"aB3$", ViolationTooShort},
+		{"abcdefghijkl", ViolationCharClasses},
+		{"xk9aaaa#mq2vl7", ViolationRepeated},
+		{"xk9abcde#mq2vl7", ViolationSequential},
+		{"Zq#johnsmith8", ViolationContainsUser},
+		{"Zq#John.Smith8", ViolationContainsUser},
+		{"password123", ViolationCommon},
+		{"Monkey2019", ViolationTooWeak},
+	}
+	for _, c := range cases {
+		err := policy.Validate(c.password, user)
+		var policyErr *PolicyError
+		if !errors.As(err, &policyErr) {
+			t.Fatalf("%s 应返回 *PolicyError, 实际 %v", c.password, err)
+		}
+		if !policyErr.Has(c.want) {
+			t.Fatalf("%s 应违反 %s, 实际 %v", c.password, c.want, policyErr.Violations)
+		}
+	}
+
+	if err := policy.Validate("Tr0ub4dour&3", user); err != nil {
+		t.Fatalf("强密码不应被拒绝: %v", err)
+	}
+}
+
+func TestPolicy_RequireClasses(t *testing.T) {
+	policy := &Policy{RequireLower: true, RequireUpper: true, RequireDigit: true, RequireSymbol: true}
+
+	err := policy.Validate("abc", UserInfo{})
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("应返回 *PolicyError, 实际 %v", err)
+	}
+	for _, code := range []ViolationCode{ViolationNoUpper, ViolationNoDigit, ViolationNoSymbol} {
+		if !policyErr.Has(code) {
+			t.Fatalf("应违反 %s", code)
+		}
+	}
+	if policyErr.Has(ViolationNoLower) {
+		t.Fatal("已包含小写字母")
+	}
+
+	if err := policy.Validate("aB3$", UserInfo{}); err != nil {
+		t.Fatalf("不应被拒绝: %v", err)
+	}
+}
+
+func TestPolicy_MaxLength(t *testing.T) {
+	policy := &Policy{MaxLength: 4}
+	if err := policy.Validate("密码密码", UserInfo{}); err != nil {
+		t.Fatalf("长度应按字符计算: %v", err)
+	}
+	if err := policy.Validate("密码密码密", UserInfo{}); err == nil {
+		t.Fatal("超过最大长度应被拒绝")
+	}
+}
+
+func TestPolicy_Messages(t *testing.T) {
+	policy := &Policy{MinLength: 10, Language: LanguageEn}
+	err := policy.Validate("short", UserInfo{})
+	if err == nil || err.Error() != "password must be at least 10 characters" {
+		t.Fatalf("英文提示错误: %v", err)
+	}
+
+	var policyErr *PolicyError
+	errors.As(err, &policyErr)
+	if msgs := policyErr.Messages(LanguageZh); msgs[0] != "密码长度不能少于 10 个字符" {
+		t.Fatalf("中文提示错误: %v", msgs)
+	}
+	if msgs := policyErr.Messages("fr"); msgs[0] != "密码长度不能少于 10 个字符" {
+		t.Fatalf("不支持的语言应回退到默认语言: %v", msgs)
+	}
+}
+
+type stubBreachChecker struct {
+	count int
+	err   error
+}
+
+func (s stubBreachChecker) BreachCount(string) (int, error) {
+	return s.count, s.err
+}
+
+func TestPolicy_Breach(t *testing.T) {
+	policy := &Policy{BreachChecker: stubBreachChecker{count: 42}}
+	err := policy.Validate("whatever", UserInfo{})
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) || !policyErr.Has(ViolationBreached) {
+		t.Fatalf("应违反 %s, 实际 %v", ViolationBreached, err)
+	}
+
+	checkErr := errors.New("io error")
+	policy.BreachChecker = stubBreachChecker{err: checkErr}
+	if err := policy.Validate("whatever", UserInfo{}); !errors.Is(err, checkErr) {
+		t.Fatalf("应返回泄露检查的错误, 实际 %v", err)
+	}
+}
+
+func TestLongestSequence(t *testing.T) {
+	cases := map[string]int{
+		"":         0,
+		"a":        1,
+		"ab":       2,
+		"xabcdy":   4,
+		"9876":     4,
+		"ABCd":     4,
+		"a1b2c3":   1,
+		"xyz{|}":   3,
+		"aceg1357": 1,
+	}
+	for s, want := range cases {
+		if got := longestSequence(s); got != want {
+			t.Fatalf("%q: 期望 %d, 实际 %d", s, want, got)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package password
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+)
+
+// 恢复码字母表，去掉了容易混淆的 0/O、1/I/L
+const recoveryCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+const (
+	DefaultRecoveryCodeCount  = 10
+	DefaultRecoveryCodeLength = 10
+)
+
+// GenerateRecoveryCodes 生成 count 个一次性恢复码，每个 length 个字符，每 5 个字符以短横线分隔，如 7KQ2M-XH9RT；
+// count、length 为 0 时使用默认值
+func GenerateRecoveryCodes(count, length int) ([]string, error) {
+	if count <= 0 {
+		count = DefaultRecoveryCodeCount
+	}
+	if length <= 0 {
+		length = DefaultRecoveryCodeLength
+	}
+
+	codes := make([]string, 0, count)
+	buf := make([]byte, length)
+	for len(codes) < count {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		for i, c := range buf {
+			if i > 0 && i%5 == 0 {
+				b.WriteByte('-')
+			}
+			// 256 不是 31 的整数倍，拒绝采样避免偏差
+			for int(c) >= 256/len(recoveryCodeAlphabet)*len(recoveryCodeAlphabet) {
+				var one [1]byte
+				if _, err := rand.Read(one[:]); err != nil {
+					return nil, err
+				}
+				c = one[0]
+			}
+			b.WriteByte(recoveryCodeAlphabet[int(c)%len(recoveryCodeAlphabet)])
+		}
+		codes = append(codes, b.String())
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode 去掉空格与短横线并转为大写，用户输入时可以忽略格式
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// HashRecoveryCodes 使用 c 对恢复码做哈希，只保存哈希结果；
+// 恢复码本身熵较高，可以使用比登录密码更快的算法，例如 NewSHA256Crypto
+func HashRecoveryCodes(c Crypto, codes []string) ([]string, error) {
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hash, err := c.Encrypt(NormalizeRecoveryCode(code))
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// UseRecoveryCode 在 hashes 中查找与 code 匹配的恢复码，匹配时返回去掉该项后的剩余哈希，调用方需要保存以保证每个恢复码只能使用一次
+func UseRecoveryCode(c Crypto, code string, hashes []string) (remaining []string, ok bool, err error) {
+	normalized := NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return hashes, false, errors.New("恢复码为空")
+	}
+
+	for i, hash := range hashes {
+		matched, err := c.Verify(normalized, hash)
+		if err != nil {
+			return hashes, false, err
+		}
+		if matched {
+			remaining = make([]string, 0, len(hashes)-1)
+			remaining = append(remaining, hashes[:i]...)
+			return append(remaining, hashes[i+1:]...), true, nil
+		}
+	}
+	return hashes, false, nil
+}
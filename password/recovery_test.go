@@ -0,0 +1,55 @@
+package password
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(0, 0)
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if len(codes) != DefaultRecoveryCodeCount {
+		t.Fatalf("数量错误: %d", len(codes))
+	}
+
+	pattern := regexp.MustCompile(`^[23456789ABCDEFGHJKMNPQRSTUVWXYZ]{5}-[23456789ABCDEFGHJKMNPQRSTUVWXYZ]{5}$`)
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if !pattern.MatchString(code) {
+			t.Fatalf("格式错误: %s", code)
+		}
+		if seen[code] {
+			t.Fatalf("恢复码重复: %s", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	crypto := NewSHA256Crypto()
+	codes, _ := GenerateRecoveryCodes(3, 10)
+	hashes, err := HashRecoveryCodes(crypto, codes)
+	if err != nil {
+		t.Fatalf("哈希失败: %v", err)
+	}
+
+	// 忽略大小写与分隔符
+	input := NormalizeRecoveryCode(codes[1])
+	remaining, ok, err := UseRecoveryCode(crypto, " "+input[:3]+" "+input[3:], hashes)
+	if err != nil || !ok {
+		t.Fatalf("恢复码验证失败: %v", err)
+	}
+	if len(remaining) != 2 || remaining[0] != hashes[0] || remaining[1] != hashes[2] {
+		t.Fatal("应移除已使用的恢复码")
+	}
+
+	// 同一个恢复码只能使用一次
+	if _, ok, err := UseRecoveryCode(crypto, codes[1], remaining); err != nil || ok {
+		t.Fatalf("已使用的恢复码不应再次通过: %v", err)
+	}
+	if _, _, err := UseRecoveryCode(crypto, " - ", remaining); err == nil {
+		t.Fatal("空恢复码应返回错误")
+	}
+}
@@ -0,0 +1,109 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"math/bits"
+	"strconv"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ScryptCrypto 实现 scrypt 密码哈希算法
+type ScryptCrypto struct {
+	// 参数可配置，默认使用推荐值
+	N          int // CPU/内存成本，必须是大于 1 的 2 的幂
+	R          int // 块大小
+	P          int // 并行度
+	SaltLength int
+	KeyLength  int
+}
+
+// NewScryptCrypto 创建带默认参数的 scrypt 加密器
+func NewScryptCrypto() *ScryptCrypto {
+	return &ScryptCrypto{
+		N:          1 << 15, // 32MB
+		R:          8,
+		P:          1,
+		SaltLength: 16,
+		KeyLength:  32,
+	}
+}
+
+// Encrypt 实现密码加密
+func (s *ScryptCrypto) Encrypt(password string) (string, error) {
+	if s.N <= 1 || s.N&(s.N-1) != 0 {
+		return "", errors.New("scrypt N 必须是大于 1 的 2 的幂")
+	}
+
+	// 生成随机盐值
+	salt := make([]byte, s.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := scrypt.Key([]byte(password), salt, s.N, s.R, s.P, s.KeyLength)
+	if err != nil {
+		return "", err
+	}
+
+	// 格式: $scrypt$ln=<log2(N)>,r=<r>,p=<p>$<base64-salt>$<base64-key>
+	return (&PHC{
+		ID: "scrypt",
+		Params: []PHCParam{
+			{Name: "ln", Value: strconv.Itoa(bits.TrailingZeros(uint(s.N)))},
+			{Name: "r", Value: strconv.Itoa(s.R)},
+			{Name: "p", Value: strconv.Itoa(s.P)},
+		},
+		Salt: salt,
+		Hash: key,
+	}).String(), nil
+}
+
+// Verify 验证密码
+func (s *ScryptCrypto) Verify(password, encrypted string) (bool, error) {
+	h, err := parseScrypt(encrypted)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := scrypt.Key([]byte(password), h.salt, h.n, h.r, h.p, len(h.key))
+	if err != nil {
+		return false, err
+	}
+
+	// 安全比较
+	return subtle.ConstantTimeCompare(key, h.key) == 1, nil
+}
+
+// NeedsRehash 判断哈希是否低于当前参数
+func (s *ScryptCrypto) NeedsRehash(encrypted string) bool {
+	h, err := parseScrypt(encrypted)
+	if err != nil {
+		return true
+	}
+	return h.n < s.N || h.r < s.R || h.p < s.P || len(h.salt) < s.SaltLength || len(h.key) < s.KeyLength
+}
+
+type scryptHash struct {
+	n, r, p int
+	salt    []byte
+	key     []byte
+}
+
+// parseScrypt 解析 $scrypt$ln=..,r=..,p=..$salt$hash
+func parseScrypt(encrypted string) (*scryptHash, error) {
+	p, err := ParsePHC(encrypted)
+	if err != nil || p.ID != "scrypt" || len(p.Hash) == 0 {
+		return nil, errors.New("无效的 scrypt 哈希格式")
+	}
+
+	ln, errN := p.IntParam("ln")
+	r, errR := p.IntParam("r")
+	par, errP := p.IntParam("p")
+	if errN != nil || errR != nil || errP != nil || ln < 1 || ln > 30 || r == 0 || par == 0 {
+		return nil, errors.New("无效的 scrypt 参数")
+	}
+	return &scryptHash{n: 1 << ln, r: r, p: par, salt: p.Salt, key: p.Hash}, nil
+}
@@ -0,0 +1,64 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScryptCrypto_EncryptAndVerify(t *testing.T) {
+	crypto := NewScryptCrypto()
+
+	password := "securepassword"
+	encrypted, err := crypto.Encrypt(password)
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if !strings.HasPrefix(encrypted, "$scrypt$ln=15,r=8,p=1$") {
+		t.Fatalf("格式错误: %s", encrypted)
+	}
+	t.Log(encrypted)
+
+	isValid, err := crypto.Verify(password, encrypted)
+	if err != nil {
+		t.Fatalf("验证失败: %v", err)
+	}
+	if !isValid {
+		t.Fatal("验证未通过，密码应匹配")
+	}
+
+	isValid, err = Verify("wrongpassword", encrypted)
+	if err != nil {
+		t.Fatalf("验证失败: %v", err)
+	}
+	if isValid {
+		t.Fatal("验证通过，但密码不应匹配")
+	}
+}
+
+func TestScryptCrypto_Vector(t *testing.T) {
+	// RFC 7914 测试向量：P="password", S="NaCl", N=1024, r=8, p=16, dkLen=64
+	encrypted := "$scrypt$ln=10,r=8,p=16$TmFDbA$/bq+HJ00cgB4VucZDQHp/nxq18vII3gw53N2Y0s3MWIurzDZLiKjiG/xCSedmDDaxyevuUqD7m2DYMvfoswGQA"
+	isValid, err := Verify("password", encrypted)
+	if err != nil || !isValid {
+		t.Fatalf("RFC 7914 向量验证失败: %v", err)
+	}
+}
+
+func TestScryptCrypto_NeedsRehash(t *testing.T) {
+	weak := &ScryptCrypto{N: 1 << 10, R: 8, P: 1, SaltLength: 16, KeyLength: 32}
+	encrypted, err := weak.Encrypt("securepassword")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if weak.NeedsRehash(encrypted) {
+		t.Fatal("参数相同不应需要重新哈希")
+	}
+	if !NewScryptCrypto().NeedsRehash(encrypted) {
+		t.Fatal("N 提高后应需要重新哈希")
+	}
+
+	weak.N = 1000
+	if _, err := weak.Encrypt("securepassword"); err == nil {
+		t.Fatal("N 不是 2 的幂时应返回错误")
+	}
+}
@@ -53,48 +53,73 @@ func (s *SHACrypto) Encrypt(password string) (string, error) {
 	hashValue.Write([]byte(password))
 	hashBytes := hashValue.Sum(nil)
 
-	// 格式: sha256:$salt$hash 或 sha512:$salt$hash
-	return fmt.Sprintf(
-		"%s$%s$%s",
-		s.HashName,
-		base64.RawStdEncoding.EncodeToString(salt),
-		hex.EncodeToString(hashBytes),
-	), nil
+	// 格式: $sha256$<base64-salt>$<base64-hash> 或 $sha512$<base64-salt>$<base64-hash>
+	return (&PHC{ID: s.HashName, Salt: salt, Hash: hashBytes}).String(), nil
 }
 
-// Verify 验证密码
+// Verify 验证密码，同时兼容旧格式 sha256$<base64-salt>$<hex-hash>
 func (s *SHACrypto) Verify(password, encrypted string) (bool, error) {
-	// 解析哈希字符串
-	parts := strings.Split(encrypted, "$")
-	if len(parts) != 3 {
-		return false, errors.New("无效的 SHA 哈希格式")
+	h, err := parseSHA(encrypted)
+	if err != nil {
+		return false, err
+	}
+	if h.hashName != s.HashName {
+		return false, fmt.Errorf("哈希算法不匹配: 期望 %s, 实际 %s", s.HashName, h.hashName)
 	}
 
-	hashName := parts[0]
-	if hashName != s.HashName {
-		return false, fmt.Errorf("哈希算法不匹配: 期望 %s, 实际 %s", s.HashName, hashName)
+	// 计算新哈希
+	hashValue := s.Hash()
+	hashValue.Write(h.salt)
+	hashValue.Write([]byte(password))
+	newHash := hashValue.Sum(nil)
+
+	// 安全比较
+	return compareHash(newHash, h.hash), nil
+}
+
+// NeedsRehash 判断哈希是否为旧格式、使用了其他哈希函数或盐值短于当前设置
+func (s *SHACrypto) NeedsRehash(encrypted string) bool {
+	h, err := parseSHA(encrypted)
+	if err != nil {
+		return true
+	}
+	return h.legacy || h.hashName != s.HashName || len(h.salt) < s.SaltLength
+}
+
+type shaHash struct {
+	hashName string
+	salt     []byte
+	hash     []byte
+	legacy   bool
+}
+
+// parseSHA 解析 PHC 格式或旧的 <hash>$<base64-salt>$<hex-hash> 格式
+func parseSHA(encrypted string) (*shaHash, error) {
+	if strings.HasPrefix(encrypted, "$") {
+		p, err := ParsePHC(encrypted)
+		if err != nil || p.Version != 0 || len(p.Params) != 0 || len(p.Hash) == 0 {
+			return nil, errors.New("无效的 SHA 哈希格式")
+		}
+		return &shaHash{hashName: p.ID, salt: p.Salt, hash: p.Hash}, nil
+	}
+
+	parts := strings.Split(encrypted, "$")
+	if len(parts) != 3 {
+		return nil, errors.New("无效的 SHA 哈希格式")
 	}
 
 	// 解码盐值
 	salt, err := base64.RawStdEncoding.DecodeString(parts[1])
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	// 解码原始哈希值
 	originalHash, err := hex.DecodeString(parts[2])
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-
-	// 计算新哈希
-	hashValue := s.Hash()
-	hashValue.Write(salt)
-	hashValue.Write([]byte(password))
-	newHash := hashValue.Sum(nil)
-
-	// 安全比较
-	return compareHash(newHash, originalHash), nil
+	return &shaHash{hashName: parts[0], salt: salt, hash: originalHash, legacy: true}, nil
 }
 
 // compareHash 安全比较两个哈希值
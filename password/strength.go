@@ -0,0 +1,509 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 强度评估提示信息 ID
+const (
+	warningCommon         = "warning.common"
+	warningSimilarCommon  = "warning.similar_common"
+	warningUserInputs     = "warning.user_inputs"
+	warningRepeat         = "warning.repeat"
+	warningSequence       = "warning.sequence"
+	warningKeyboard       = "warning.keyboard"
+	warningYear           = "warning.year"
+	warningShort          = "warning.short"
+	suggestionLonger      = "suggestion.longer"
+	suggestionAvoidRepeat = "suggestion.avoid_repeat"
+	suggestionAvoidSeq    = "suggestion.avoid_sequence"
+	suggestionAvoidKeys   = "suggestion.avoid_keyboard"
+	suggestionAvoidYear   = "suggestion.avoid_year"
+	suggestionAvoidUser   = "suggestion.avoid_user_inputs"
+	suggestionUncommon    = "suggestion.uncommon"
+	suggestionCaps        = "suggestion.caps"
+	suggestionLeet        = "suggestion.leet"
+)
+
+// 匹配模式
+const (
+	patternDictionary = "dictionary"
+	patternUserInputs = "user_inputs"
+	patternRepeat     = "repeat"
+	patternSequence   = "sequence"
+	patternKeyboard   = "keyboard"
+	patternYear       = "year"
+)
+
+// 未覆盖整个密码的匹配的最小猜测次数，避免多个弱模式拼接后被低估
+const minSubmatchGuesses = 50
+
+// StrengthResult 密码强度评估结果
+type StrengthResult struct {
+	Score       int      // 评分 0-4，0 极弱，4 很强
+	Guesses     float64  // 估算的破解所需猜测次数
+	Entropy     float64  // 熵（比特），即 log2(Guesses)
+	Warning     string   // 主要问题，没有时为空
+	Suggestions []string // 改进建议
+}
+
+type strengthMatch struct {
+	pattern string
+	i, j    int // 匹配的字符区间 [i, j]
+	guesses float64
+	token   string
+	rank    int
+	upper   bool
+	l33t    bool
+}
+
+// l33t 常见字符替换
+var l33tTable = map[rune]rune{
+	'@': 'a', '4': 'a', '3': 'e', '0': 'o', '$': 's', '5': 's', '7': 't', '!': 'i', '+': 't',
+}
+
+// EstimateStrength 参考 zxcvbn 的思路估算密码强度：识别常见密码、个人信息、重复、连续、键盘序列与年份等模式，
+// 取猜测次数最少的组合作为估算值；userInputs 为用户名、邮箱等个人信息，lang 为提示信息语言
+func EstimateStrength(password string, lang Language, userInputs ...string) *StrengthResult {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return &StrengthResult{
+			Warning:     message(lang, warningShort),
+			Suggestions: []string{message(lang, suggestionLonger)},
+		}
+	}
+
+	var matches []*strengthMatch
+	matches = append(matches, dictionaryMatches(runes, userInputs)...)
+	matches = append(matches, repeatMatches(runes)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, yearMatches(runes)...)
+
+	bits, path := minimumGuesses(runes, matches)
+	result := &StrengthResult{
+		Guesses: math.Pow(2, bits),
+		Entropy: bits,
+		Score:   scoreFromGuesses(bits),
+	}
+	fillFeedback(result, runes, path, lang)
+	return result
+}
+
+// minimumGuesses 用动态规划求覆盖整个密码的最小猜测次数（以 log2 计），并返回使用的匹配
+func minimumGuesses(runes []rune, matches []*strengthMatch) (float64, []*strengthMatch) {
+	n := len(runes)
+	bruteforce := math.Log2(float64(bruteforceCardinality(runes)))
+
+	best := make([]float64, n+1)
+	back := make([]*strengthMatch, n+1)
+	for k := 1; k <= n; k++ {
+		best[k] = best[k-1] + bruteforce
+		back[k] = nil
+		for _, m := range matches {
+			if m.j != k-1 {
+				continue
+			}
+			guesses := m.guesses
+			if m.i != 0 || m.j != n-1 {
+				guesses = math.Max(guesses, minSubmatchGuesses)
+			}
+			if cost := best[m.i] + math.Log2(math.Max(guesses, 1)); cost < best[k] {
+				best[k] = cost
+				back[k] = m
+			}
+		}
+	}
+
+	var path []*strengthMatch
+	for k := n; k > 0; {
+		if m := back[k]; m != nil {
+			path = append(path, m)
+			k = m.i
+		} else {
+			k--
+		}
+	}
+	return best[n], path
+}
+
+// scoreFromGuesses 按 zxcvbn 的阈值将猜测次数转换为 0-4 分
+func scoreFromGuesses(bits float64) int {
+	log10 := bits * math.Log10(2)
+	switch {
+	case log10 < 3:
+		return 0
+	case log10 < 6:
+		return 1
+	case log10 < 8:
+		return 2
+	case log10 < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func bruteforceCardinality(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r <= unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	cardinality := 0
+	for _, c := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if c.present {
+			cardinality += c.size
+		}
+	}
+	return cardinality
+}
+
+// dictionaryMatches 在常见密码与个人信息中查找所有长度不小于 3 的子串，包括 l33t 替换还原后的子串
+func dictionaryMatches(runes []rune, userInputs []string) []*strengthMatch {
+	common := loadCommonPasswords()
+	inputs := userInputRanks(userInputs)
+
+	lower := make([]rune, len(runes))
+	unl33t := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+		unl33t[i] = lower[i]
+		if sub, ok := l33tTable[lower[i]]; ok {
+			unl33t[i] = sub
+		}
+	}
+
+	var matches []*strengthMatch
+	for i := range runes {
+		for j := i + 2; j < len(runes); j++ {
+			token := string(runes[i : j+1])
+			for _, candidate := range []struct {
+				word string
+				l33t bool
+			}{{string(lower[i : j+1]), false}, {string(unl33t[i : j+1]), true}} {
+				if candidate.l33t && candidate.word == string(lower[i:j+1]) {
+					continue
+				}
+				pattern, rank := patternUserInputs, inputs[candidate.word]
+				if rank == 0 {
+					pattern, rank = patternDictionary, common[candidate.word]
+				}
+				if rank == 0 {
+					continue
+				}
+				m := &strengthMatch{
+					pattern: pattern,
+					i:       i,
+					j:       j,
+					token:   token,
+					rank:    rank,
+					l33t:    candidate.l33t,
+				}
+				m.guesses = float64(rank) * uppercaseVariations(runes[i:j+1], m)
+				if m.l33t {
+					m.guesses *= 2
+				}
+				matches = append(matches, m)
+			}
+		}
+	}
+	return matches
+}
+
+// userInputRanks 将个人信息转换为排名表，邮箱额外拆出 @ 之前的部分
+func userInputRanks(userInputs []string) map[string]int {
+	ranks := make(map[string]int)
+	add := func(s string) {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if len([]rune(s)) >= 3 {
+			if _, ok := ranks[s]; !ok {
+				ranks[s] = len(ranks) + 1
+			}
+		}
+	}
+	for _, input := range userInputs {
+		add(input)
+		if local, _, ok := strings.Cut(input, "@"); ok {
+			add(local)
+		}
+	}
+	return ranks
+}
+
+// uppercaseVariations 估算大小写变化带来的额外猜测次数
+func uppercaseVariations(runes []rune, m *strengthMatch) float64 {
+	upper, lower := 0, 0
+	for _, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	m.upper = true
+	if lower == 0 || (upper == 1 && unicode.IsUpper(runes[0])) || (upper == 1 && unicode.IsUpper(runes[len(runes)-1])) {
+		return 2
+	}
+	return math.Pow(2, float64(min(upper, lower)))
+}
+
+// repeatMatches 查找连续 3 个以上相同的字符
+func repeatMatches(runes []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	for i := 0; i < len(runes); {
+		j := i
+		for j+1 < len(runes) && runes[j+1] == runes[i] {
+			j++
+		}
+		if j-i+1 >= 3 {
+			matches = append(matches, &strengthMatch{
+				pattern: patternRepeat,
+				i:       i,
+				j:       j,
+				token:   string(runes[i : j+1]),
+				guesses: float64(bruteforceCardinality(runes[i:i+1]) * (j - i + 1)),
+			})
+		}
+		i = j + 1
+	}
+	return matches
+}
+
+// sequenceMatches 查找 3 个以上递增或递减的连续字符，如 abc、9876
+func sequenceMatches(runes []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	for i := 0; i < len(runes)-1; {
+		delta := unicode.ToLower(runes[i+1]) - unicode.ToLower(runes[i])
+		j := i + 1
+		if (delta == 1 || delta == -1) && isAlnum(runes[i]) && isAlnum(runes[i+1]) {
+			for j+1 < len(runes) && isAlnum(runes[j+1]) && unicode.ToLower(runes[j+1])-unicode.ToLower(runes[j]) == delta {
+				j++
+			}
+		}
+		if j-i+1 >= 3 {
+			first := unicode.ToLower(runes[i])
+			base := 26.0
+			switch {
+			case strings.ContainsRune("az019", first):
+				base = 4
+			case unicode.IsDigit(first):
+				base = 10
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, &strengthMatch{
+				pattern: patternSequence,
+				i:       i,
+				j:       j,
+				token:   string(runes[i : j+1]),
+				guesses: base * float64(j-i+1),
+			})
+			i = j
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+func isAlnum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// 美式 QWERTY 键盘布局，每行相对上一行向右错开半个键位
+var keyboardRows = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}
+
+const keyboardShifted = "~!@#$%^&*()_+{}|:\"<>?"
+const keyboardUnshifted = "`1234567890-=[]\\;',./"
+
+type keyPosition struct{ row, col int }
+
+var keyboardPositions = func() map[rune]keyPosition {
+	positions := make(map[rune]keyPosition)
+	for row, keys := range keyboardRows {
+		for col, r := range keys {
+			positions[r] = keyPosition{row, col}
+		}
+	}
+	return positions
+}()
+
+// keyboardKey 将大写字母与上档符号还原为对应的键
+func keyboardKey(r rune) (keyPosition, bool) {
+	r = unicode.ToLower(r)
+	if idx := strings.IndexRune(keyboardShifted, r); idx >= 0 {
+		r = rune(keyboardUnshifted[idx])
+	}
+	pos, ok := keyboardPositions[r]
+	return pos, ok
+}
+
+// keyboardDirection 返回两个相邻键之间的方向，不相邻时返回 0
+func keyboardDirection(a, b keyPosition) int {
+	switch {
+	case a.row == b.row && b.col == a.col+1:
+		return 1
+	case a.row == b.row && b.col == a.col-1:
+		return 2
+	case b.row == a.row+1 && b.col == a.col-1:
+		return 3 // 左下
+	case b.row == a.row+1 && b.col == a.col:
+		return 4 // 右下
+	case b.row == a.row-1 && b.col == a.col:
+		return 5 // 左上
+	case b.row == a.row-1 && b.col == a.col+1:
+		return 6 // 右上
+	}
+	return 0
+}
+
+// keyboardMatches 查找 4 个以上在键盘上依次相邻的按键，如 qwer、1qaz
+func keyboardMatches(runes []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	for i := 0; i < len(runes); {
+		prev, ok := keyboardKey(runes[i])
+		if !ok {
+			i++
+			continue
+		}
+		j, turns, lastDir := i, 0, 0
+		for j+1 < len(runes) {
+			next, ok := keyboardKey(runes[j+1])
+			if !ok {
+				break
+			}
+			dir := keyboardDirection(prev, next)
+			if dir == 0 {
+				break
+			}
+			if dir != lastDir {
+				turns++
+				lastDir = dir
+			}
+			prev = next
+			j++
+		}
+		if j-i+1 >= 4 {
+			matches = append(matches, &strengthMatch{
+				pattern: patternKeyboard,
+				i:       i,
+				j:       j,
+				token:   string(runes[i : j+1]),
+				guesses: 47 * float64(j-i+1) * math.Pow(4, float64(turns)),
+			})
+			i = j + 1
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// yearMatches 查找 1900-2099 之间的年份
+func yearMatches(runes []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	now := time.Now().Year()
+	for i := 0; i+4 <= len(runes); i++ {
+		year := 0
+		for _, r := range runes[i : i+4] {
+			if r < '0' || r > '9' {
+				year = -1
+				break
+			}
+			year = year*10 + int(r-'0')
+		}
+		if year < 1900 || year > 2099 {
+			continue
+		}
+		matches = append(matches, &strengthMatch{
+			pattern: patternYear,
+			i:       i,
+			j:       i + 3,
+			token:   string(runes[i : i+4]),
+			guesses: math.Max(math.Abs(float64(year-now)), 20),
+		})
+	}
+	return matches
+}
+
+// fillFeedback 根据最长的弱模式给出提示
+func fillFeedback(result *StrengthResult, runes []rune, path []*strengthMatch, lang Language) {
+	if result.Score >= 3 {
+		return
+	}
+
+	var longest *strengthMatch
+	for _, m := range path {
+		if longest == nil || m.j-m.i > longest.j-longest.i {
+			longest = m
+		}
+	}
+
+	suggestions := []string{suggestionLonger}
+	warning := ""
+	if longest == nil {
+		if len(runes) < 8 {
+			warning = warningShort
+		}
+	} else {
+		switch longest.pattern {
+		case patternDictionary:
+			if len(path) == 1 && longest.i == 0 && longest.j == len(runes)-1 && !longest.l33t && !longest.upper {
+				warning = warningCommon
+			} else {
+				warning = warningSimilarCommon
+			}
+			suggestions = append(suggestions, suggestionUncommon)
+			if longest.upper {
+				suggestions = append(suggestions, suggestionCaps)
+			}
+			if longest.l33t {
+				suggestions = append(suggestions, suggestionLeet)
+			}
+		case patternUserInputs:
+			warning = warningUserInputs
+			suggestions = append(suggestions, suggestionAvoidUser)
+		case patternRepeat:
+			warning = warningRepeat
+			suggestions = append(suggestions, suggestionAvoidRepeat)
+		case patternSequence:
+			warning = warningSequence
+			suggestions = append(suggestions, suggestionAvoidSeq)
+		case patternKeyboard:
+			warning = warningKeyboard
+			suggestions = append(suggestions, suggestionAvoidKeys)
+		case patternYear:
+			warning = warningYear
+			suggestions = append(suggestions, suggestionAvoidYear)
+		}
+	}
+
+	if warning != "" {
+		result.Warning = message(lang, warning)
+	}
+	for _, s := range suggestions {
+		result.Suggestions = append(result.Suggestions, message(lang, s))
+	}
+}
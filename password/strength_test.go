@@ -0,0 +1,71 @@
+package password
+
+import (
+	"testing"
+)
+
+func TestEstimateStrength_Weak(t *testing.T) {
+	cases := map[string]string{
+		"password":     warningCommon,
+		"P@ssw0rd":     warningSimilarCommon,
+		"aaaaaaaaaaaa": warningRepeat,
+		"abcdefghijk":  warningSequence,
+		"zxcvfdsa":     warningKeyboard,
+		"johnsmith":    warningUserInputs,
+	}
+	for password, warning := range cases {
+		result := EstimateStrength(password, LanguageEn, "johnsmith")
+		if result.Score > 1 {
+			t.Fatalf("%s 评分过高: %d", password, result.Score)
+		}
+		if result.Warning != message(LanguageEn, warning) {
+			t.Fatalf("%s 提示错误: %q", password, result.Warning)
+		}
+		if len(result.Suggestions) == 0 {
+			t.Fatalf("%s 应给出建议", password)
+		}
+	}
+}
+
+func TestEstimateStrength_Strong(t *testing.T) {
+	for _, password := range []string{"correcthorsebatterystaple", "xK9#mQ2$vL7!", "Tr0ub4dour&3"} {
+		result := EstimateStrength(password, LanguageZh)
+		if result.Score < 3 {
+			t.Fatalf("%s 评分过低: %d", password, result.Score)
+		}
+		if result.Warning != "" || len(result.Suggestions) != 0 {
+			t.Fatalf("%s 不应给出提示", password)
+		}
+	}
+}
+
+func TestEstimateStrength_Monotonic(t *testing.T) {
+	weak := EstimateStrength("monkey", LanguageZh)
+	stronger := EstimateStrength("monkey-violin-27", LanguageZh)
+	if stronger.Entropy <= weak.Entropy {
+		t.Fatalf("更长的密码熵应更高: %.1f <= %.1f", stronger.Entropy, weak.Entropy)
+	}
+}
+
+func TestEstimateStrength_Empty(t *testing.T) {
+	result := EstimateStrength("", LanguageZh)
+	if result.Score != 0 || result.Warning != message(LanguageZh, warningShort) {
+		t.Fatalf("空密码结果错误: %+v", result)
+	}
+}
+
+func TestEstimateStrength_Year(t *testing.T) {
+	result := EstimateStrength("1998", LanguageEn)
+	if result.Score != 0 || result.Warning != message(LanguageEn, warningYear) {
+		t.Fatalf("年份结果错误: %+v", result)
+	}
+}
+
+func TestIsCommonPassword(t *testing.T) {
+	if !IsCommonPassword("123456") || !IsCommonPassword("PASSWORD") {
+		t.Fatal("应识别常见密码")
+	}
+	if IsCommonPassword("xK9#mQ2$vL7!") {
+		t.Fatal("不应误判")
+	}
+}
@@ -0,0 +1,146 @@
+package password
+
+import (
+	"strings"
+)
+
+// Identify 从哈希字符串识别算法，返回 PHC 算法标识，如 argon2id、bcrypt、pbkdf2-sha256、sha512；
+// 旧系统导入的格式返回 md5、sha1、md5-crypt、sha256-crypt、sha512-crypt、django-pbkdf2-sha256 等
+func Identify(encrypted string) (string, error) {
+	switch {
+	case strings.HasPrefix(encrypted, "$2a$"), strings.HasPrefix(encrypted, "$2b$"), strings.HasPrefix(encrypted, "$2y$"):
+		return "bcrypt", nil
+	case strings.HasPrefix(encrypted, bcryptSHA256Prefix):
+		return "bcrypt-sha256", nil
+	case strings.HasPrefix(encrypted, "$1$"):
+		return legacyMD5Crypt, nil
+	case strings.HasPrefix(encrypted, "$5$"):
+		return legacySHA256Crypt, nil
+	case strings.HasPrefix(encrypted, "$6$"):
+		return legacySHA512Crypt, nil
+	case strings.HasPrefix(encrypted, "$"):
+		p, err := ParsePHC(encrypted)
+		if err != nil {
+			return "", err
+		}
+		return p.ID, nil
+	case strings.HasPrefix(encrypted, "pbkdf2:"):
+		// 旧格式 pbkdf2:<hash>:...
+		parts := strings.SplitN(encrypted, ":", 3)
+		if len(parts) == 3 {
+			return "pbkdf2-" + parts[1], nil
+		}
+	case strings.HasPrefix(encrypted, "sha256$"):
+		return "sha256", nil
+	case strings.HasPrefix(encrypted, "sha512$"):
+		return "sha512", nil
+	case strings.HasPrefix(encrypted, "pbkdf2_"):
+		// Django pbkdf2_<hash>$...
+		if name, _, ok := strings.Cut(encrypted, "$"); ok {
+			return legacyDjangoPBKDF2 + strings.TrimPrefix(name, "pbkdf2_"), nil
+		}
+	case isHex(encrypted) && len(encrypted) == 32:
+		return legacyMD5, nil
+	case isHex(encrypted) && len(encrypted) == 40:
+		return legacySHA1, nil
+	}
+	return "", ErrUnknownHashFormat
+}
+
+// cryptoFor 返回能够验证该算法哈希的 Crypto，验证所需参数均取自哈希字符串本身
+func cryptoFor(id string) (Crypto, error) {
+	switch id {
+	case "argon2id":
+		return NewArgon2Crypto(), nil
+	case "bcrypt":
+		return NewBCryptCrypto(), nil
+	case "bcrypt-sha256":
+		return NewBCryptSHA256Crypto(), nil
+	case "scrypt":
+		return NewScryptCrypto(), nil
+	case "pbkdf2-sha256":
+		return NewPBKDF2Crypto(), nil
+	case "pbkdf2-sha512":
+		return NewPBKDF2WithSHA512(), nil
+	case "sha256":
+		return NewSHA256Crypto(), nil
+	case "sha512":
+		return NewSHA512Crypto(), nil
+	default:
+		if isLegacyID(id) {
+			return NewLegacyCrypto(), nil
+		}
+		return nil, ErrUnknownHashFormat
+	}
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Verify 根据哈希字符串自动识别算法并验证密码，无需事先调用 CreateCrypto；
+// 使用了 pepper 的哈希返回 ErrPepperRequired，需要通过 PepperedCrypto 验证
+func Verify(plainPassword, encrypted string) (bool, error) {
+	version, _, err := splitPepper(encrypted)
+	if err != nil {
+		return false, err
+	}
+	if version > 0 {
+		return false, ErrPepperRequired
+	}
+	return verifyUnpeppered(plainPassword, encrypted)
+}
+
+func verifyUnpeppered(plainPassword, encrypted string) (bool, error) {
+	id, err := Identify(encrypted)
+	if err != nil {
+		return false, err
+	}
+	c, err := cryptoFor(id)
+	if err != nil {
+		return false, err
+	}
+	return c.Verify(plainPassword, encrypted)
+}
+
+// anyFormatVerifier 由可以自行识别所有哈希格式的 Crypto 实现，如 PepperedCrypto
+type anyFormatVerifier interface {
+	Crypto
+	acceptsAnyFormat()
+}
+
+// NeedsRehash 判断哈希是否需要按 current 重新生成：旧系统导入的格式始终需要；
+// 其他格式由 current 实现的 Rehasher 判断，未实现时返回 false
+func NeedsRehash(current Crypto, encrypted string) bool {
+	if id, err := Identify(encrypted); err == nil && isLegacyID(id) {
+		return true
+	}
+	r, ok := current.(Rehasher)
+	return ok && r.NeedsRehash(encrypted)
+}
+
+// VerifyAndRehash 自动识别算法验证密码，验证成功且哈希低于 current 的策略时，
+// 返回用 current 重新生成的哈希，调用方应将其保存以在登录时静默升级；无需升级时 newHash 为空
+func VerifyAndRehash(current Crypto, plainPassword, encrypted string) (ok bool, newHash string, err error) {
+	if c, isAny := current.(anyFormatVerifier); isAny {
+		ok, err = c.Verify(plainPassword, encrypted)
+	} else {
+		ok, err = Verify(plainPassword, encrypted)
+	}
+	if err != nil || !ok {
+		return ok, "", err
+	}
+	if !NeedsRehash(current, encrypted) {
+		return true, "", nil
+	}
+	newHash, err = current.Encrypt(plainPassword)
+	if err != nil {
+		return true, "", err
+	}
+	return true, newHash, nil
+}
@@ -0,0 +1,169 @@
+package password
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// 测试使用较低的参数以加快速度
+func testArgon2() *Argon2Crypto {
+	return &Argon2Crypto{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+}
+
+func testPBKDF2(iterations int) *PBKDF2Crypto {
+	p := NewPBKDF2Crypto()
+	p.Iterations = iterations
+	return p
+}
+
+func legacySHA256(password string) string {
+	salt := []byte("0123456789abcdef")
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(password))
+	return fmt.Sprintf("sha256$%s$%s", base64.RawStdEncoding.EncodeToString(salt), hex.EncodeToString(h.Sum(nil)))
+}
+
+func legacyPBKDF2(password string, iterations int) string {
+	salt := []byte("0123456789abcdef")
+	key := pbkdf2Key([]byte(password), salt, iterations, 32, sha256.New)
+	return fmt.Sprintf("pbkdf2:sha256:%d:%s:%s", iterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+func TestVerify_AutoDetect(t *testing.T) {
+	password := "securepassword"
+	cryptos := map[string]Crypto{
+		"argon2id":      testArgon2(),
+		"bcrypt":        NewBCryptCrypto(4),
+		"pbkdf2-sha256": testPBKDF2(1000),
+		"sha256":        NewSHA256Crypto(),
+		"sha512":        NewSHA512Crypto(),
+	}
+
+	for want, c := range cryptos {
+		encrypted, err := c.Encrypt(password)
+		if err != nil {
+			t.Fatalf("%s 加密失败: %v", want, err)
+		}
+
+		id, err := Identify(encrypted)
+		if err != nil || id != want {
+			t.Fatalf("识别算法错误: 期望 %s, 实际 %s, %v", want, id, err)
+		}
+
+		ok, err := Verify(password, encrypted)
+		if err != nil || !ok {
+			t.Fatalf("%s 验证失败: %v", want, err)
+		}
+
+		ok, err = Verify("wrongpassword", encrypted)
+		if err != nil || ok {
+			t.Fatalf("%s 错误密码验证通过: %v", want, err)
+		}
+	}
+}
+
+func TestVerify_Legacy(t *testing.T) {
+	password := "securepassword"
+	for _, encrypted := range []string{legacySHA256(password), legacyPBKDF2(password, 1000)} {
+		ok, err := Verify(password, encrypted)
+		if err != nil || !ok {
+			t.Fatalf("旧格式 %s 验证失败: %v", encrypted, err)
+		}
+	}
+}
+
+func TestVerify_Unknown(t *testing.T) {
+	for _, encrypted := range []string{"", "plaintext", "$unknown$c2FsdA$aGFzaA", "md5$abc$def"} {
+		if _, err := Verify("x", encrypted); err == nil {
+			t.Fatalf("%q 应返回错误", encrypted)
+		}
+	}
+	if _, err := Verify("x", "plaintext"); !errors.Is(err, ErrUnknownHashFormat) {
+		t.Fatalf("应返回 ErrUnknownHashFormat, 实际 %v", err)
+	}
+}
+
+func TestVerify_EmptyHashRejected(t *testing.T) {
+	for _, encrypted := range []string{
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$",
+		"$pbkdf2-sha256$i=1000$c2FsdHNhbHRzYWx0c2FsdA$",
+	} {
+		if ok, _ := Verify("anything", encrypted); ok {
+			t.Fatalf("空哈希 %s 不应验证通过", encrypted)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	password := "securepassword"
+	weakArgon2, _ := testArgon2().Encrypt(password)
+	weakPBKDF2, _ := testPBKDF2(1000).Encrypt(password)
+	bcrypt4, _ := NewBCryptCrypto(4).Encrypt(password)
+	sha, _ := NewSHA256Crypto().Encrypt(password)
+
+	stronger := testArgon2()
+	stronger.Iterations = 2
+	if !stronger.NeedsRehash(weakArgon2) {
+		t.Fatal("Argon2 迭代次数提高后应需要重新哈希")
+	}
+	if testArgon2().NeedsRehash(weakArgon2) {
+		t.Fatal("Argon2 参数相同不应需要重新哈希")
+	}
+	if !testArgon2().NeedsRehash(weakPBKDF2) {
+		t.Fatal("Argon2 对其他算法应需要重新哈希")
+	}
+
+	if !testPBKDF2(2000).NeedsRehash(weakPBKDF2) || testPBKDF2(1000).NeedsRehash(weakPBKDF2) {
+		t.Fatal("PBKDF2 迭代次数判断错误")
+	}
+	if !testPBKDF2(1000).NeedsRehash(legacyPBKDF2(password, 1000)) {
+		t.Fatal("PBKDF2 旧格式应需要重新哈希")
+	}
+	if !NewPBKDF2WithSHA512().NeedsRehash(weakPBKDF2) {
+		t.Fatal("PBKDF2 哈希函数不同应需要重新哈希")
+	}
+
+	if !NewBCryptCrypto(5).NeedsRehash(bcrypt4) || NewBCryptCrypto(4).NeedsRehash(bcrypt4) {
+		t.Fatal("bcrypt cost 判断错误")
+	}
+	if !NewBCryptCrypto(4).NeedsRehash(sha) {
+		t.Fatal("bcrypt 对其他算法应需要重新哈希")
+	}
+
+	if NewSHA256Crypto().NeedsRehash(sha) || !NewSHA256Crypto().NeedsRehash(legacySHA256(password)) {
+		t.Fatal("SHA 格式判断错误")
+	}
+}
+
+func TestVerifyAndRehash(t *testing.T) {
+	password := "securepassword"
+	current := testArgon2()
+
+	old := legacySHA256(password)
+	ok, newHash, err := VerifyAndRehash(current, password, old)
+	if err != nil || !ok {
+		t.Fatalf("验证失败: %v", err)
+	}
+	if newHash == "" {
+		t.Fatal("旧哈希应被升级")
+	}
+	if id, _ := Identify(newHash); id != "argon2id" {
+		t.Fatalf("升级后的算法错误: %s", id)
+	}
+
+	ok, again, err := VerifyAndRehash(current, password, newHash)
+	if err != nil || !ok || again != "" {
+		t.Fatalf("已是最新哈希不应再次升级: %s, %v", again, err)
+	}
+
+	ok, newHash, err = VerifyAndRehash(current, "wrongpassword", old)
+	if err != nil || ok || newHash != "" {
+		t.Fatalf("错误密码不应通过或升级: %v", err)
+	}
+}